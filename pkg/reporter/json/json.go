@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package json writes the notes generated by vetters as JSON, e.g. for
+// processing by CI pipelines.
+package json
+
+import (
+	"encoding/json"
+	"io"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+)
+
+// note is the JSON representation of a Note. The level is written as its
+// name rather than the enum value.
+type note struct {
+	ID      string            `json:"id"`
+	Type    string            `json:"type"`
+	Summary string            `json:"summary"`
+	Msg     string            `json:"msg"`
+	Level   string            `json:"level"`
+	Attr    map[string]string `json:"attr"`
+}
+
+// WriteJSON writes the notes to w as a JSON array, followed by a newline. The
+// summary and message are rendered from the attributes of the note, which
+// are kept as well.
+func WriteJSON(w io.Writer, notes []*apiv1.Note) error {
+	out := make([]note, len(notes))
+	for i, n := range notes {
+		summary, err := util.FormatNoteSummary(n)
+		if err != nil {
+			return err
+		}
+		msg, err := util.FormatNote(n)
+		if err != nil {
+			return err
+		}
+		out[i] = note{
+			ID:      n.GetId(),
+			Type:    n.GetType(),
+			Summary: summary,
+			Msg:     msg,
+			Level:   n.GetLevel().String(),
+			Attr:    n.GetAttr(),
+		}
+		if out[i].Attr == nil {
+			out[i].Attr = map[string]string{}
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
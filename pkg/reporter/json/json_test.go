@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteJSON", func() {
+	It("writes the notes as a JSON array", func() {
+		notes := []*apiv1.Note{
+			{
+				Id:      "8a5b",
+				Type:    "missing-service-port-prefix",
+				Summary: "Missing prefix - ${service_name}",
+				Msg:     "Service ${service_name} has no prefix.",
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    map[string]string{"service_name": "reviews"},
+			},
+			{Type: "no-attr", Level: apiv1.NoteLevel_INFO},
+		}
+		var buf bytes.Buffer
+		Expect(WriteJSON(&buf, notes)).To(Succeed())
+		var out []map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &out)).To(Succeed())
+		Expect(out).To(Equal([]map[string]interface{}{
+			{
+				"id":      "8a5b",
+				"type":    "missing-service-port-prefix",
+				"summary": "Missing prefix - reviews",
+				"msg":     "Service reviews has no prefix.",
+				"level":   "ERROR",
+				"attr":    map[string]interface{}{"service_name": "reviews"},
+			},
+			{
+				"id":      "",
+				"type":    "no-attr",
+				"summary": "",
+				"msg":     "",
+				"level":   "INFO",
+				"attr":    map[string]interface{}{},
+			},
+		}))
+	})
+
+	It("renders the placeholders of the summary and message", func() {
+		notes := []*apiv1.Note{
+			{
+				Type:    "missing-service-port-prefix",
+				Summary: "Missing prefix - {{.service_name}}",
+				Msg:     "Service ${service_name} in ${namespace} has no prefix.",
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    map[string]string{"service_name": "reviews"},
+			},
+		}
+		var buf bytes.Buffer
+		Expect(WriteJSON(&buf, notes)).To(Succeed())
+		var out []map[string]interface{}
+		Expect(json.Unmarshal(buf.Bytes(), &out)).To(Succeed())
+		Expect(out).To(HaveLen(1))
+		Expect(out[0]["summary"]).To(Equal("Missing prefix - reviews"))
+		Expect(out[0]["msg"]).To(Equal("Service reviews in <missing:namespace> has no prefix."))
+		Expect(out[0]["attr"]).To(Equal(map[string]interface{}{"service_name": "reviews"}))
+	})
+
+	It("writes an empty array without notes", func() {
+		var buf bytes.Buffer
+		Expect(WriteJSON(&buf, nil)).To(Succeed())
+		Expect(buf.String()).To(Equal("[]\n"))
+	})
+})
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package junit writes the notes generated by vetters as a JUnit XML report,
+// so that they are shown as test results by CI dashboards.
+package junit
+
+import (
+	"encoding/xml"
+	"io"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+)
+
+type testSuites struct {
+	XMLName xml.Name    `xml:"testsuites"`
+	Suites  []testSuite `xml:"testsuite"`
+}
+
+type testSuite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Cases    []testCase `xml:"testcase"`
+}
+
+type testCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Failure   *failure `xml:"failure,omitempty"`
+}
+
+type failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// failed returns true if the level of the note fails its test case.
+func failed(n *apiv1.Note) bool {
+	switch n.GetLevel() {
+	case apiv1.NoteLevel_WARNING, apiv1.NoteLevel_ERROR:
+		return true
+	}
+	return false
+}
+
+// WriteJUnit writes the notes to w as a JUnit XML report. Notes are grouped
+// into a test suite per note type, in order of their first occurrence, with a
+// test case per note. WARNING and ERROR notes fail their test case, with the
+// summary as the failure message and the message as its body.
+func WriteJUnit(w io.Writer, notes []*apiv1.Note) error {
+	report := testSuites{}
+	index := map[string]int{}
+	for _, n := range notes {
+		i, ok := index[n.GetType()]
+		if !ok {
+			i = len(report.Suites)
+			index[n.GetType()] = i
+			report.Suites = append(report.Suites, testSuite{Name: n.GetType()})
+		}
+		s := &report.Suites[i]
+		summary, err := util.FormatNoteSummary(n)
+		if err != nil {
+			return err
+		}
+		c := testCase{Name: summary, ClassName: n.GetType()}
+		if failed(n) {
+			msg, err := util.FormatNote(n)
+			if err != nil {
+				return err
+			}
+			c.Failure = &failure{
+				Message: summary,
+				Type:    n.GetLevel().String(),
+				Body:    msg,
+			}
+			s.Failures++
+		}
+		s.Tests++
+		s.Cases = append(s.Cases, c)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
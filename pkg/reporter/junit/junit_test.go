@@ -0,0 +1,65 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package junit
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WriteJUnit", func() {
+	It("writes a test suite per note type", func() {
+		notes := []*apiv1.Note{
+			{
+				Type:    "missing-service-port-prefix",
+				Summary: "Missing prefix in service - ${service_name}",
+				Msg:     "Missing prefix in port ${port} of service ${service_name}.",
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr:    map[string]string{"service_name": "reviews", "port": "9080"},
+			},
+			{
+				Type:    "mesh-and-named-gateways",
+				Summary: "Mesh and named gateways - ${vs_name}",
+				Msg:     "The VirtualService ${vs_name} binds <mesh> & gateways.",
+				Level:   apiv1.NoteLevel_INFO,
+				Attr:    map[string]string{"vs_name": "api"},
+			},
+			{
+				Type:    "missing-service-port-prefix",
+				Summary: "Missing prefix in service - ${service_name}",
+				Msg:     "Missing prefix in port ${port} of service ${service_name}.",
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    map[string]string{"service_name": "ratings", "port": "9081"},
+			},
+		}
+		var buf bytes.Buffer
+		Expect(WriteJUnit(&buf, notes)).To(Succeed())
+		golden, err := ioutil.ReadFile("testdata/notes.xml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(Equal(string(golden)))
+	})
+
+	It("writes an empty report without notes", func() {
+		var buf bytes.Buffer
+		Expect(WriteJUnit(&buf, nil)).To(Succeed())
+		Expect(buf.String()).To(Equal("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<testsuites></testsuites>\n"))
+	})
+})
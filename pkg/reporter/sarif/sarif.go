@@ -0,0 +1,172 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sarif writes the notes generated by vetters as a SARIF 2.1.0 log,
+// e.g. for upload to GitHub code scanning.
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+)
+
+const (
+	// Version is the SARIF version of the written log.
+	Version = "2.1.0"
+	// Schema is the URI of the JSON schema of the written log.
+	Schema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+
+	toolName           = "istio-vet"
+	toolInformationURI = "https://github.com/aspenmesh/istio-vet"
+	noteIDFingerprint  = "istioVetNoteId/v1"
+)
+
+// objectAttrs maps the Attr keys naming a Kubernetes object to the kind of
+// the object, in order of precedence. The first key present in a note becomes
+// its logical location, in the Namespace of the "namespace" attribute.
+var objectAttrs = []struct {
+	key  string
+	kind string
+}{
+	{"vs_name", "VirtualService"},
+	{"dr_name", "DestinationRule"},
+	{"se_name", "ServiceEntry"},
+	{"envoyfilter_name", "EnvoyFilter"},
+	{"role_name", "ServiceRole"},
+	{"gateway_name", "Gateway"},
+	{"service_name", "Service"},
+	{"pod_name", "Pod"},
+	{"secret_name", "Secret"},
+}
+
+type sarifLog struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []run  `json:"runs"`
+}
+
+type run struct {
+	Tool    tool     `json:"tool"`
+	Results []result `json:"results"`
+}
+
+type tool struct {
+	Driver driver `json:"driver"`
+}
+
+type driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []rule `json:"rules"`
+}
+
+type rule struct {
+	ID string `json:"id"`
+}
+
+type result struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             message           `json:"message"`
+	Locations           []location        `json:"locations,omitempty"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+type location struct {
+	LogicalLocations []logicalLocation `json:"logicalLocations"`
+}
+
+type logicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// level returns the SARIF level of the note.
+func level(n *apiv1.Note) string {
+	switch n.GetLevel() {
+	case apiv1.NoteLevel_ERROR:
+		return "error"
+	case apiv1.NoteLevel_WARNING:
+		return "warning"
+	}
+	return "note"
+}
+
+// locations returns the logical location of the Kubernetes object referenced
+// by the note, if any.
+func locations(n *apiv1.Note) []location {
+	for _, o := range objectAttrs {
+		name, ok := n.GetAttr()[o.key]
+		if !ok || name == "" {
+			continue
+		}
+		fqn := o.kind + "/" + name
+		if ns := n.GetAttr()["namespace"]; ns != "" {
+			fqn = o.kind + "/" + ns + "/" + name
+		}
+		return []location{{LogicalLocations: []logicalLocation{
+			{Name: name, FullyQualifiedName: fqn, Kind: "resource"},
+		}}}
+	}
+	return nil
+}
+
+// WriteSARIF writes the notes to w as a SARIF log with a single run. The note
+// type is used as the rule ID and the formatted summary as the message of a
+// result. Notes referencing a Kubernetes object in their attributes get a
+// logical location "<kind>/<namespace>/<name>".
+func WriteSARIF(w io.Writer, notes []*apiv1.Note) error {
+	r := run{
+		Tool: tool{Driver: driver{
+			Name:           toolName,
+			InformationURI: toolInformationURI,
+			Rules:          []rule{},
+		}},
+		Results: []result{},
+	}
+	seen := map[string]bool{}
+	for _, n := range notes {
+		if !seen[n.GetType()] {
+			seen[n.GetType()] = true
+			r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, rule{ID: n.GetType()})
+		}
+		summary, err := util.FormatNoteSummary(n)
+		if err != nil {
+			return err
+		}
+		res := result{
+			RuleID:    n.GetType(),
+			Level:     level(n),
+			Message:   message{Text: summary},
+			Locations: locations(n),
+		}
+		if n.GetId() != "" {
+			res.PartialFingerprints = map[string]string{noteIDFingerprint: n.GetId()}
+		}
+		r.Results = append(r.Results, res)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{Schema: Schema, Version: Version, Runs: []run{r}})
+}
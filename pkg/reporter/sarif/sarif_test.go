@@ -0,0 +1,167 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// validate checks the log against the constraints of the SARIF 2.1.0 schema
+// for the properties written by WriteSARIF.
+func validate(l map[string]interface{}) error {
+	if l["version"] != Version {
+		return fmt.Errorf("version %v is not %q", l["version"], Version)
+	}
+	if _, ok := l["$schema"].(string); !ok {
+		return fmt.Errorf("$schema is not a string")
+	}
+	runs, ok := l["runs"].([]interface{})
+	if !ok {
+		return fmt.Errorf("runs is not an array")
+	}
+	for _, r := range runs {
+		run := r.(map[string]interface{})
+		driver, ok := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("run has no tool.driver")
+		}
+		if name, ok := driver["name"].(string); !ok || name == "" {
+			return fmt.Errorf("driver has no name")
+		}
+		for _, ru := range driver["rules"].([]interface{}) {
+			if _, ok := ru.(map[string]interface{})["id"].(string); !ok {
+				return fmt.Errorf("rule has no id")
+			}
+		}
+		for _, re := range run["results"].([]interface{}) {
+			res := re.(map[string]interface{})
+			if _, ok := res["message"].(map[string]interface{})["text"].(string); !ok {
+				return fmt.Errorf("result has no message.text")
+			}
+			switch res["level"] {
+			case "none", "note", "warning", "error":
+			default:
+				return fmt.Errorf("result level %v is not valid", res["level"])
+			}
+			locs, _ := res["locations"].([]interface{})
+			for _, lo := range locs {
+				for _, ll := range lo.(map[string]interface{})["logicalLocations"].([]interface{}) {
+					for _, k := range []string{"name", "fullyQualifiedName", "kind"} {
+						if _, ok := ll.(map[string]interface{})[k].(string); !ok {
+							return fmt.Errorf("logical location has no %s", k)
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+var _ = Describe("WriteSARIF", func() {
+	notes := []*apiv1.Note{
+		{
+			Id:      "8a5b",
+			Type:    "mesh-and-named-gateways",
+			Summary: "Mesh and named gateways - ${vs_name}",
+			Level:   apiv1.NoteLevel_INFO,
+			Attr:    map[string]string{"vs_name": "api", "namespace": "default"},
+		},
+		{
+			Type:    "missing-service-port-prefix",
+			Summary: "Missing prefix in service - ${service_name}",
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr:    map[string]string{"service_name": "reviews", "namespace": "default"},
+		},
+		{
+			Type:    "missing-service-port-prefix",
+			Summary: "Missing prefix in service - ${service_name}",
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr:    map[string]string{"service_name": "ratings", "namespace": "bookinfo"},
+		},
+		{
+			Type:    "istio-component-mismatch",
+			Summary: "Mismatched versions",
+			Level:   apiv1.NoteLevel_WARNING,
+		},
+	}
+
+	write := func(notes []*apiv1.Note) map[string]interface{} {
+		var buf bytes.Buffer
+		Expect(WriteSARIF(&buf, notes)).To(Succeed())
+		l := map[string]interface{}{}
+		Expect(json.Unmarshal(buf.Bytes(), &l)).To(Succeed())
+		Expect(validate(l)).To(Succeed())
+		return l
+	}
+
+	It("maps notes to results of a rule per note type", func() {
+		run := write(notes)["runs"].([]interface{})[0].(map[string]interface{})
+		Expect(run["tool"]).To(Equal(map[string]interface{}{
+			"driver": map[string]interface{}{
+				"name":           "istio-vet",
+				"informationUri": "https://github.com/aspenmesh/istio-vet",
+				"rules": []interface{}{
+					map[string]interface{}{"id": "mesh-and-named-gateways"},
+					map[string]interface{}{"id": "missing-service-port-prefix"},
+					map[string]interface{}{"id": "istio-component-mismatch"},
+				},
+			},
+		}))
+		results := run["results"].([]interface{})
+		Expect(results).To(HaveLen(4))
+		Expect(results[0]).To(Equal(map[string]interface{}{
+			"ruleId":  "mesh-and-named-gateways",
+			"level":   "note",
+			"message": map[string]interface{}{"text": "Mesh and named gateways - api"},
+			"locations": []interface{}{
+				map[string]interface{}{"logicalLocations": []interface{}{
+					map[string]interface{}{
+						"name":               "api",
+						"fullyQualifiedName": "VirtualService/default/api",
+						"kind":               "resource",
+					},
+				}},
+			},
+			"partialFingerprints": map[string]interface{}{"istioVetNoteId/v1": "8a5b"},
+		}))
+		Expect(results[1].(map[string]interface{})["level"]).To(Equal("warning"))
+		Expect(results[2].(map[string]interface{})["level"]).To(Equal("error"))
+		Expect(results[2].(map[string]interface{})["locations"]).To(Equal([]interface{}{
+			map[string]interface{}{"logicalLocations": []interface{}{
+				map[string]interface{}{
+					"name":               "ratings",
+					"fullyQualifiedName": "Service/bookinfo/ratings",
+					"kind":               "resource",
+				},
+			}},
+		}))
+		Expect(results[3]).NotTo(HaveKey("locations"))
+	})
+
+	It("writes a valid log without notes", func() {
+		l := write(nil)
+		Expect(l["$schema"]).To(Equal(Schema))
+		Expect(l["runs"].([]interface{})[0].(map[string]interface{})["results"]).To(Equal([]interface{}{}))
+	})
+})
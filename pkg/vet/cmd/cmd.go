@@ -35,8 +35,26 @@ var cfgFile string
 const (
 	// DefaultConfigFile is the default config file for vet tool
 	DefaultConfigFile = "/etc/istio/vet.yaml"
+
+	enableVetterFlag    = "enable-vetter"
+	exemptNamespaceFlag = "exempt-namespace"
+	runVettersFlag      = "run-vetters"
+	skipVettersFlag     = "skip-vetters"
+	outputFlag          = "output"
+	failOnFlag          = "fail-on"
+	downgradeFlag       = "downgrade-suppressed"
+
+	outputText  = "text"
+	outputJSON  = "json"
+	outputJUnit = "junit"
+	outputSARIF = "sarif"
+
+	failOnNone = "none"
 )
 
+// exitCode is the exit code of the process once the vet command succeeds.
+var exitCode int
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
 	Use:   "vet",
@@ -58,6 +76,7 @@ func Execute() {
 		fmt.Println(err)
 		os.Exit(-1)
 	}
+	os.Exit(exitCode)
 }
 
 func init() {
@@ -70,6 +89,21 @@ func init() {
 	// Copy those flags into root command
 	meshclient.BindKubeConfigToFlags(RootCmd.PersistentFlags())
 	RootCmd.PersistentFlags().AddFlagSet(pflag.CommandLine)
+
+	RootCmd.Flags().StringSlice(enableVetterFlag, []string{},
+		"IDs of opt-in vetters to run in addition to the default vetters")
+	RootCmd.Flags().StringSlice(exemptNamespaceFlag, []string{},
+		"Namespaces exempted from sidecar injection in addition to kube-system, kube-public and istio-system")
+	RootCmd.Flags().StringSlice(runVettersFlag, []string{},
+		"IDs of the only vetters to run, all enabled vetters run if empty")
+	RootCmd.Flags().StringSlice(skipVettersFlag, []string{},
+		"IDs of vetters to skip")
+	RootCmd.Flags().StringP(outputFlag, "o", outputText,
+		"Output format of the notes, \"text\", \"json\", \"junit\" or \"sarif\"")
+	RootCmd.Flags().String(failOnFlag, failOnNone,
+		"Exit with 1, or 2 for errors, if a note is at or above the level, \"none\", \"info\", \"warning\" or \"error\"")
+	RootCmd.Flags().Bool(downgradeFlag, false,
+		"Report the notes suppressed by the vet.istio.io/suppress annotation as info notes instead of dropping them")
 }
 
 // WordSepNormalizeFunc changes all flags that contain "_" separators
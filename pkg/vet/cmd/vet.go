@@ -18,23 +18,91 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	istioinformer "github.com/aspenmesh/istio-client-go/pkg/client/informers/externalversions"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
 	"github.com/aspenmesh/istio-vet/pkg/istioclient"
 	"github.com/aspenmesh/istio-vet/pkg/meshclient"
+	jsonreporter "github.com/aspenmesh/istio-vet/pkg/reporter/json"
+	"github.com/aspenmesh/istio-vet/pkg/reporter/junit"
+	"github.com/aspenmesh/istio-vet/pkg/reporter/sarif"
 	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/ambiguousshortnamehost"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/ambiguoustargetport"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/apiservershadow"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/applabel"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/conflictingsubset"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/conflictingvirtualservicehost"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/controlplaneselfinjection"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/corsheaderconflict"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/danglingroutedestinationhost"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/deploymentproxyconcurrency"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/destinationruleexportto"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/destinationrulekeepalive"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/destinationrulesubjectaltname"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/destinationrulesubset"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/destinationruletlsfilepath"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/dnscaptureoverride"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/envoyfilterheavyfilter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewaycredentialsecret"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewayemptyhosts"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewayportprotocol"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewayrouteport"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewaytlsversion"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/gatewayweakcipher"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/grpcroutefeature"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/h2cserviceport"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/holdapplicationproxystart"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/hopbyhopheadermatch"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/hostcasemismatch"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/inconsistentappmtls"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/injectioncontrolplane"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/injectionlabelconflict"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/invalidserviceforjwtpolicy"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/kubernetesservicedestinationrule"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/latestimagetag"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/meshversion"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/mirrorstrictmtls"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/missingnamespacepolicy"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/mixedendpoints"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/mtlsprobes"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/podsinmesh"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/portlessmeshpod"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/proxyportconflict"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/proxystatsinclusion"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/publishnotreadyaddresses"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/rbacconstraintkey"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/registryonlydestinationrule"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/serviceassociation"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/serviceentryaddress"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/serviceentryduplicateport"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/serviceentryprotocol"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/servicemultiplecontrollers"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/servicenameapplabel"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/servicenodeport"
 	"github.com/aspenmesh/istio-vet/pkg/vetter/serviceportprefix"
-	"github.com/aspenmesh/istio-vet/pkg/vetter/invalidserviceforjwtpolicy"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/staleistioconfig"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/strictmtlsnonmeshsource"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/subsetlabelsuperset"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/subsetmixedversions"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/targetportprotocol"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/tcproutematchport"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/unsupportedvirtualserviceregex"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualserviceauthoritymatch"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualservicegatewaynamespace"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualservicehostnamespace"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualservicemeshgateway"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualservicemultidomain"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualserviceprefixrewrite"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualserviceredirectloop"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/virtualserviceweightedredirect"
 	"github.com/golang/glog"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"k8s.io/client-go/informers"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -58,6 +126,30 @@ func printNote(level, summary, msg string) {
 	}
 }
 
+// printSink prints each note as soon as it is generated, and every vetter
+// which generated no notes.
+type printSink struct{}
+
+func (p *printSink) Emit(n *apiv1.Note) {
+	summary, err := util.FormatNoteSummary(n)
+	if err != nil {
+		glog.Errorf("Failed to format summary of note %s: %s", n.GetType(), err)
+		summary = n.GetSummary()
+	}
+	msg, err := util.FormatNote(n)
+	if err != nil {
+		glog.Errorf("Failed to format message of note %s: %s", n.GetType(), err)
+		msg = n.GetMsg()
+	}
+	printNote(n.GetLevel().String(), summary, msg)
+}
+
+func (p *printSink) VetterDone(id string, emitted int) {
+	if emitted == 0 {
+		fmt.Printf("Vetter \"%s\" ran successfully and generated no notes\n\n", id)
+	}
+}
+
 type metaInformerFactory struct {
 	k8s   informers.SharedInformerFactory
 	istio istioinformer.SharedInformerFactory
@@ -71,6 +163,26 @@ func (m *metaInformerFactory) Istio() istioinformer.SharedInformerFactory {
 }
 
 func vet(cmd *cobra.Command, args []string) error {
+	output := viper.GetString(outputFlag)
+	switch output {
+	case outputText, outputJSON, outputJUnit, outputSARIF:
+	default:
+		return fmt.Errorf("unsupported output format %q, must be one of %q",
+			output, []string{outputText, outputJSON, outputJUnit, outputSARIF})
+	}
+	failOn := apiv1.NoteLevel_UNUSED
+	if f := viper.GetString(failOnFlag); !strings.EqualFold(f, failOnNone) {
+		l, ok := apiv1.NoteLevel_value[strings.ToUpper(f)]
+		if !ok || l == int32(apiv1.NoteLevel_UNUSED) {
+			return fmt.Errorf("unsupported fail-on level %q, must be one of %q",
+				f, []string{failOnNone, "info", "warning", "error"})
+		}
+		failOn = apiv1.NoteLevel(l)
+	}
+	for _, ns := range viper.GetStringSlice(exemptNamespaceFlag) {
+		util.AddExemptedNamespace(ns)
+	}
+
 	k8sClient, err := meshclient.New()
 	if err != nil {
 		return err
@@ -97,6 +209,85 @@ func vet(cmd *cobra.Command, args []string) error {
 		vetter.Vetter(danglingroutedestinationhost.NewVetter(informerFactory)),
 		vetter.Vetter(conflictingvirtualservicehost.NewVetter(informerFactory)),
 		vetter.Vetter(invalidserviceforjwtpolicy.NewVetter(informerFactory)),
+		vetter.Vetter(unsupportedvirtualserviceregex.NewVetter(informerFactory)),
+		vetter.Vetter(destinationruletlsfilepath.NewVetter(informerFactory)),
+		vetter.Vetter(ambiguousshortnamehost.NewVetter(informerFactory)),
+		vetter.Vetter(strictmtlsnonmeshsource.NewVetter(informerFactory)),
+		vetter.Vetter(servicenodeport.NewVetter(informerFactory)),
+		vetter.Vetter(virtualservicehostnamespace.NewVetter(informerFactory)),
+		vetter.Vetter(inconsistentappmtls.NewVetter(informerFactory)),
+		vetter.Vetter(gatewaycredentialsecret.NewVetter(informerFactory)),
+		vetter.Vetter(hostcasemismatch.NewVetter(informerFactory)),
+		vetter.Vetter(holdapplicationproxystart.NewVetter(informerFactory)),
+		vetter.Vetter(serviceentryprotocol.NewVetter(informerFactory)),
+		vetter.Vetter(missingnamespacepolicy.NewVetter(informerFactory)),
+		vetter.Vetter(gatewayportprotocol.NewVetter(informerFactory)),
+		vetter.Vetter(virtualserviceprefixrewrite.NewVetter(informerFactory)),
+		vetter.Vetter(subsetlabelsuperset.NewVetter(informerFactory)),
+		vetter.Vetter(targetportprotocol.NewVetter(informerFactory)),
+		vetter.Vetter(serviceentryaddress.NewVetter(informerFactory)),
+		vetter.Vetter(rbacconstraintkey.NewVetter(informerFactory)),
+		vetter.Vetter(gatewayemptyhosts.NewVetter(informerFactory)),
+		vetter.Vetter(virtualservicemeshgateway.NewVetter(informerFactory)),
+		vetter.Vetter(servicemultiplecontrollers.NewVetter(informerFactory)),
+		vetter.Vetter(dnscaptureoverride.NewVetter(informerFactory)),
+		vetter.Vetter(grpcroutefeature.NewVetter(informerFactory)),
+		vetter.Vetter(conflictingsubset.NewVetter(informerFactory)),
+		vetter.Vetter(injectioncontrolplane.NewVetter(informerFactory)),
+		vetter.Vetter(ambiguoustargetport.NewVetter(informerFactory)),
+		vetter.Vetter(latestimagetag.NewVetter(informerFactory)),
+		vetter.Vetter(tcproutematchport.NewVetter(informerFactory)),
+		vetter.Vetter(gatewayrouteport.NewVetter(informerFactory)),
+		vetter.Vetter(registryonlydestinationrule.NewVetter(informerFactory)),
+		vetter.Vetter(proxyportconflict.NewVetter(informerFactory)),
+		vetter.Vetter(corsheaderconflict.NewVetter(informerFactory)),
+		vetter.Vetter(injectionlabelconflict.NewVetter(informerFactory)),
+		vetter.Vetter(subsetmixedversions.NewVetter(informerFactory)),
+		vetter.Vetter(envoyfilterheavyfilter.NewVetter(informerFactory)),
+		vetter.Vetter(virtualserviceredirectloop.NewVetter(informerFactory)),
+		vetter.Vetter(apiservershadow.NewVetter(informerFactory)),
+		vetter.Vetter(destinationrulesubjectaltname.NewVetter(informerFactory)),
+		vetter.Vetter(portlessmeshpod.NewVetter(informerFactory)),
+		vetter.Vetter(gatewaytlsversion.NewVetter(informerFactory)),
+		vetter.Vetter(gatewayweakcipher.NewVetter(informerFactory)),
+		vetter.Vetter(virtualserviceauthoritymatch.NewVetter(informerFactory)),
+		vetter.Vetter(mirrorstrictmtls.NewVetter(informerFactory)),
+		vetter.Vetter(destinationrulekeepalive.NewVetter(informerFactory)),
+		vetter.Vetter(virtualserviceweightedredirect.NewVetter(informerFactory)),
+		vetter.Vetter(controlplaneselfinjection.NewVetter(informerFactory)),
+		vetter.Vetter(h2cserviceport.NewVetter(informerFactory)),
+		vetter.Vetter(serviceentryduplicateport.NewVetter(informerFactory)),
+		vetter.Vetter(destinationruleexportto.NewVetter(informerFactory)),
+		vetter.Vetter(proxystatsinclusion.NewVetter(informerFactory)),
+		vetter.Vetter(virtualservicegatewaynamespace.NewVetter(informerFactory)),
+		vetter.Vetter(mixedendpoints.NewVetter(informerFactory)),
+		vetter.Vetter(deploymentproxyconcurrency.NewVetter(informerFactory)),
+		vetter.Vetter(hopbyhopheadermatch.NewVetter(informerFactory)),
+		vetter.Vetter(publishnotreadyaddresses.NewVetter(informerFactory)),
+		vetter.Vetter(kubernetesservicedestinationrule.NewVetter(informerFactory)),
+		vetter.Vetter(destinationrulesubset.NewVetter(informerFactory)),
+	}
+
+	// Opt-in vetters only run if enabled by ID with the enable-vetter flag.
+	optInList := []vetter.Vetter{
+		vetter.Vetter(servicenameapplabel.NewVetter(informerFactory)),
+		vetter.Vetter(staleistioconfig.NewVetter(informerFactory)),
+		vetter.Vetter(virtualservicemultidomain.NewVetter(informerFactory)),
+	}
+	disabledOptIn := []string{}
+	for _, v := range optInList {
+		enabled := false
+		for _, id := range viper.GetStringSlice(enableVetterFlag) {
+			if strings.EqualFold(id, v.Info().GetId()) {
+				enabled = true
+				break
+			}
+		}
+		if enabled {
+			vList = append(vList, v)
+		} else {
+			disabledOptIn = append(disabledOptIn, v.Info().GetId())
+		}
 	}
 
 	stopCh := make(chan struct{})
@@ -119,27 +310,34 @@ func vet(cmd *cobra.Command, args []string) error {
 	// Just run through once
 	close(stopCh)
 
-	for _, v := range vList {
-		nList, err := v.Vet()
-		if err != nil {
-			fmt.Printf("Vetter: \"%s\" reported error: %s\n", v.Info().GetId(), err)
-			continue
-		}
-		if len(nList) > 0 {
-			for i := range nList {
-				var ts []string
-				for k, v := range nList[i].Attr {
-					ts = append(ts, "${"+k+"}", v)
-				}
-				r := strings.NewReplacer(ts...)
-				summary := r.Replace(nList[i].GetSummary())
-				msg := r.Replace(nList[i].GetMsg())
-				printNote(nList[i].GetLevel().String(), summary, msg)
-			}
-		} else {
-			fmt.Printf("Vetter \"%s\" ran successfully and generated no notes\n\n", v.Info().GetId())
+	registry := vetter.NewRegistry()
+	registry.Register(vList...)
+	registry.OptIn(disabledOptIn...)
+	registry.RunOnly(viper.GetStringSlice(runVettersFlag)...)
+	registry.Disable(viper.GetStringSlice(skipVettersFlag)...)
+	registry.FailOnLevel(failOn)
+	registry.DowngradeSuppressed(viper.GetBool(downgradeFlag))
+	// Errors go to stderr with machine-readable output so that stdout stays
+	// parsable.
+	var errOut io.Writer = os.Stderr
+	if output == outputText {
+		registry.AddSink(&printSink{})
+		errOut = os.Stdout
+	}
+	nList, err := registry.RunAll()
+	if errs, ok := err.(vetter.RunErrors); ok {
+		for _, e := range errs {
+			fmt.Fprintf(errOut, "Vetter: \"%s\" reported error: %s\n", e.ID, e.Err)
 		}
 	}
-
+	exitCode = registry.ExitCode(nList)
+	switch output {
+	case outputJSON:
+		return jsonreporter.WriteJSON(os.Stdout, nList)
+	case outputJUnit:
+		return junit.WriteJUnit(os.Stdout, nList)
+	case outputSARIF:
+		return sarif.WriteSARIF(os.Stdout, nList)
+	}
 	return nil
 }
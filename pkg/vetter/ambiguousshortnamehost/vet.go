@@ -0,0 +1,148 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ambiguousshortnamehost vets the hosts of VirtualService resources
+// and generates notes for short names matching Services in more than one
+// namespace.
+package ambiguousshortnamehost
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "AmbiguousShortNameHost"
+	ambiguousHostNoteType    = "ambiguous-short-name-host"
+	ambiguousHostNoteSummary = "Ambiguous short name host ${host} - ${vs_name}"
+	ambiguousHostNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" uses the short name host ${host} which matches services in namespaces" +
+		" ${namespaces}. Short names are resolved in the namespace of the VirtualService" +
+		" so it refers to ${fqdn}. Consider using the fully qualified domain name" +
+		" to make the intended service explicit."
+)
+
+// AmbiguousShortNameHost implements Vetter interface
+type AmbiguousShortNameHost struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	vsLister  netv1alpha3.VirtualServiceLister
+}
+
+// virtualServiceHosts returns the unique hosts and route destination hosts of
+// the VirtualService in the order they appear.
+func virtualServiceHosts(vs *v1alpha3.VirtualService) []string {
+	seen := map[string]bool{}
+	hosts := []string{}
+	add := func(h string) {
+		if h != "" && !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	for _, h := range vs.Spec.GetHosts() {
+		add(h)
+	}
+	for _, r := range vs.Spec.GetHttp() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination().GetHost())
+		}
+		add(r.GetMirror().GetHost())
+	}
+	for _, r := range vs.Spec.GetTcp() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination().GetHost())
+		}
+	}
+	for _, r := range vs.Spec.GetTls() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination().GetHost())
+		}
+	}
+	return hosts
+}
+
+// createAmbiguousHostNotes generates notes for the short name hosts of the
+// VirtualServices which match Services in more than one namespace.
+func createAmbiguousHostNotes(svcs []*corev1.Service,
+	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, vs := range vsList {
+		for _, host := range virtualServiceHosts(vs) {
+			namespaces := resolver.AmbiguousNamespaces(host)
+			if namespaces == nil {
+				continue
+			}
+			fqdn, err := util.ConvertHostnameToFQDN(host, vs.Namespace)
+			if err != nil {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    ambiguousHostNoteType,
+				Summary: ambiguousHostNoteSummary,
+				Msg:     ambiguousHostNoteMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"vs_name":    vs.Name,
+					"namespace":  vs.Namespace,
+					"host":       host,
+					"namespaces": strings.Join(namespaces, ", "),
+					"fqdn":       fqdn,
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (a *AmbiguousShortNameHost) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(a.nsLister, a.svcLister)
+	if err != nil {
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(a.nsLister, a.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createAmbiguousHostNotes(svcs, vsList), nil
+}
+
+// Info returns information about the vetter
+func (a *AmbiguousShortNameHost) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "AmbiguousShortNameHost" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *AmbiguousShortNameHost {
+	return &AmbiguousShortNameHost{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
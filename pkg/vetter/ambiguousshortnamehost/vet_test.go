@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ambiguousshortnamehost
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func service(name, namespace string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+func virtualService(name string, hosts ...string) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: hosts,
+			},
+		},
+	}
+}
+
+var _ = Describe("Ambiguous short name hosts", func() {
+	svcs := []*corev1.Service{
+		service("reviews", "default"),
+		service("ratings", "default"),
+		service("ratings", "staging"),
+	}
+
+	It("creates zero notes on empty lists", func() {
+		Expect(createAmbiguousHostNotes(nil, nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for an unambiguous short name", func() {
+		vsList := []*v1alpha3.VirtualService{virtualService("reviews", "reviews")}
+		Expect(createAmbiguousHostNotes(svcs, vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for an FQDN", func() {
+		vsList := []*v1alpha3.VirtualService{
+			virtualService("ratings", "ratings.staging.svc.cluster.local"),
+		}
+		Expect(createAmbiguousHostNotes(svcs, vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for an ambiguous short name", func() {
+		vs := virtualService("ratings", "ratings")
+		vs.Spec.Http = []*istiov1alpha3.HTTPRoute{
+			{
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					{Destination: &istiov1alpha3.Destination{Host: "ratings"}},
+				},
+			},
+		}
+		expNote := &apiv1.Note{
+			Type:    ambiguousHostNoteType,
+			Summary: ambiguousHostNoteSummary,
+			Msg:     ambiguousHostNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":    "ratings",
+				"namespace":  "default",
+				"host":       "ratings",
+				"namespaces": "default, staging",
+				"fqdn":       "ratings.default.svc.cluster.local",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes := createAmbiguousHostNotes(svcs, []*v1alpha3.VirtualService{vs})
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
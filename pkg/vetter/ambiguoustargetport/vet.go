@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ambiguoustargetport vets the named target ports of Services in the
+// mesh and generates notes if containers of a selected pod declare the port
+// name with different numbers.
+package ambiguoustargetport
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                   = "AmbiguousTargetPort"
+	ambiguousTargetPortType    = "ambiguous-target-port"
+	ambiguousTargetPortSummary = "Ambiguous target port ${target_port} - ${service_name}"
+	ambiguousTargetPortMsg     = "The service ${service_name} in namespace ${namespace}" +
+		" targets the port ${target_port}, which the containers of pod ${pod_name}" +
+		" declare with different numbers: ${container_ports}. The service sends" +
+		" traffic to only one of them. Consider giving the container ports unique" +
+		" names."
+)
+
+// AmbiguousTargetPort implements Vetter interface
+type AmbiguousTargetPort struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+}
+
+// namedContainerPorts returns the "<container>:<number>" of the container
+// ports of the pod with the given name, and whether their numbers differ.
+func namedContainerPorts(name string, p *corev1.Pod) ([]string, bool) {
+	ports := []string{}
+	numbers := map[int32]bool{}
+	for _, c := range p.Spec.Containers {
+		for _, cp := range c.Ports {
+			if cp.Name != name {
+				continue
+			}
+			numbers[cp.ContainerPort] = true
+			ports = append(ports, c.Name+":"+strconv.Itoa(int(cp.ContainerPort)))
+		}
+	}
+	sort.Strings(ports)
+	return ports, len(numbers) > 1
+}
+
+// createAmbiguousTargetPortNotes generates notes for the named target ports
+// of the services which the containers of a selected pod declare with
+// different numbers. A note is generated for the first such pod only.
+func createAmbiguousTargetPortNotes(services []*corev1.Service,
+	pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		if len(s.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(s.Spec.Selector)
+		for _, sp := range s.Spec.Ports {
+			if sp.TargetPort.Type != intstr.String {
+				continue
+			}
+			for _, p := range pods {
+				if p.Namespace != s.Namespace || !selector.Matches(labels.Set(p.Labels)) {
+					continue
+				}
+				ports, ambiguous := namedContainerPorts(sp.TargetPort.StrVal, p)
+				if !ambiguous {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    ambiguousTargetPortType,
+					Summary: ambiguousTargetPortSummary,
+					Msg:     ambiguousTargetPortMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"service_name":    s.Name,
+						"namespace":       s.Namespace,
+						"target_port":     sp.TargetPort.StrVal,
+						"pod_name":        p.Name,
+						"container_ports": strings.Join(ports, ", "),
+					},
+				})
+				break
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (a *AmbiguousTargetPort) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(a.nsLister, a.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			ambiguousTargetPortType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(a.nsLister, a.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			ambiguousTargetPortType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createAmbiguousTargetPortNotes(services, pods), nil
+}
+
+// Info returns information about the vetter
+func (a *AmbiguousTargetPort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "AmbiguousTargetPort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *AmbiguousTargetPort {
+	return &AmbiguousTargetPort{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ambiguoustargetport
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func pod(appPort, sidecarPort int32) []*corev1.Pod {
+	containers := []corev1.Container{
+		{
+			Name:  "reviews",
+			Ports: []corev1.ContainerPort{{Name: "http-web", ContainerPort: appPort}},
+		},
+	}
+	if sidecarPort != 0 {
+		containers = append(containers, corev1.Container{
+			Name:  "metrics-exporter",
+			Ports: []corev1.ContainerPort{{Name: "http-web", ContainerPort: sidecarPort}},
+		})
+	}
+	return []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "reviews-1",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "reviews"},
+			},
+			Spec: corev1.PodSpec{Containers: containers},
+		},
+	}
+}
+
+var _ = Describe("Ambiguous named target ports", func() {
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "reviews"},
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 80, TargetPort: intstr.FromString("http-web")},
+				},
+			},
+		},
+	}
+
+	It("creates zero notes for a unique named port", func() {
+		Expect(createAmbiguousTargetPortNotes(services, pod(9080, 0))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for duplicates with the same number", func() {
+		Expect(createAmbiguousTargetPortNotes(services, pod(9080, 9080))).To(HaveLen(0))
+	})
+
+	It("creates a note for duplicates with different numbers", func() {
+		expNote := &apiv1.Note{
+			Type:    ambiguousTargetPortType,
+			Summary: ambiguousTargetPortSummary,
+			Msg:     ambiguousTargetPortMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name":    "reviews",
+				"namespace":       "default",
+				"target_port":     "http-web",
+				"pod_name":        "reviews-1",
+				"container_ports": "metrics-exporter:9090, reviews:9080",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createAmbiguousTargetPortNotes(services, pod(9080, 9090))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
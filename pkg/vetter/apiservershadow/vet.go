@@ -0,0 +1,165 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiservershadow vets the Services in the mesh and generates notes
+// if a Service shadows the kubernetes Service fronting the API server.
+package apiservershadow
+
+import (
+	"sort"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID          = "APIServerShadow"
+	shadowNoteType    = "api-server-shadow"
+	shadowNoteSummary = "Service shadows the API server - ${service_name}"
+	shadowNoteMsg     = "The service ${service_name} in namespace ${namespace}" +
+		" looks like the kubernetes API server service (${reason_list}). It can" +
+		" interfere with the handling of the kubernetes service by Istio." +
+		" Consider renaming or relabeling the service, or removing it."
+	apiServerLabels = "component=apiserver,provider=kubernetes"
+)
+
+// apiServerPorts are the ports the API server is commonly served on.
+var apiServerPorts = map[int32]bool{443: true, 6443: true}
+
+// APIServerShadow implements Vetter interface
+type APIServerShadow struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	epLister  v1.EndpointsLister
+}
+
+// servesAPIServerPort returns true if the Service exposes or targets one of
+// the API server ports.
+func servesAPIServerPort(s *corev1.Service) bool {
+	for _, p := range s.Spec.Ports {
+		if apiServerPorts[p.Port] || apiServerPorts[p.TargetPort.IntVal] {
+			return true
+		}
+	}
+	return false
+}
+
+// addressIPs returns the IPs of the addresses of the Endpoints.
+func addressIPs(ep *corev1.Endpoints) map[string]bool {
+	ips := map[string]bool{}
+	if ep == nil {
+		return ips
+	}
+	for _, ss := range ep.Subsets {
+		for _, a := range ss.Addresses {
+			ips[a.IP] = true
+		}
+	}
+	return ips
+}
+
+// createShadowNotes generates a note for every Service named like the API
+// server, labeled like the API server on an API server port, or with an
+// endpoint IP of the API server. The kubernetes Service itself is ignored.
+func createShadowNotes(svcs []*corev1.Service, eps []*corev1.Endpoints,
+	apiServer *corev1.Endpoints) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	apiServerIPs := addressIPs(apiServer)
+	svcIPs := map[string]map[string]bool{}
+	for _, ep := range eps {
+		svcIPs[ep.Namespace+"/"+ep.Name] = addressIPs(ep)
+	}
+	selector, _ := labels.Parse(apiServerLabels)
+	for _, s := range svcs {
+		if util.IsKubernetesService(s) {
+			continue
+		}
+		reasons := []string{}
+		if s.Name == util.KubernetesServiceName {
+			reasons = append(reasons, "name")
+		}
+		if selector.Matches(labels.Set(s.Labels)) && servesAPIServerPort(s) {
+			reasons = append(reasons, "labels and port")
+		}
+		for ip := range svcIPs[s.Namespace+"/"+s.Name] {
+			if apiServerIPs[ip] {
+				reasons = append(reasons, "endpoint "+ip)
+			}
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+		sort.Strings(reasons)
+		notes = append(notes, &apiv1.Note{
+			Type:    shadowNoteType,
+			Summary: shadowNoteSummary,
+			Msg:     shadowNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name": s.Name,
+				"namespace":    s.Namespace,
+				"reason_list":  strings.Join(reasons, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (a *APIServerShadow) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(a.nsLister, a.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			shadowNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	eps, err := util.ListEndpointsInMesh(a.nsLister, a.epLister)
+	if err != nil {
+		return nil, err
+	}
+	apiServer, err := util.KubernetesServiceEndpoints(a.epLister)
+	if err != nil {
+		glog.Errorf("Failed to retrieve kubernetes service endpoints: %s", err)
+		return nil, err
+	}
+	return createShadowNotes(svcs, eps, apiServer), nil
+}
+
+// Info returns information about the vetter
+func (a *APIServerShadow) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "APIServerShadow" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *APIServerShadow {
+	return &APIServerShadow{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		epLister:  factory.K8s().Core().V1().Endpoints().Lister(),
+	}
+}
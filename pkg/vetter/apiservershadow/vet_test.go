@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiservershadow
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func endpoints(name, namespace, ip string) *corev1.Endpoints {
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Subsets: []corev1.EndpointSubset{
+			{Addresses: []corev1.EndpointAddress{{IP: ip}}},
+		},
+	}
+}
+
+func service(name, namespace string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "https", Port: 443}}},
+	}
+}
+
+var _ = Describe("Services shadowing the API server", func() {
+	apiServer := endpoints("kubernetes", "default", "10.0.0.1")
+
+	It("creates zero notes for a normal service", func() {
+		svcs := []*corev1.Service{service("web", "default")}
+		eps := []*corev1.Endpoints{endpoints("web", "default", "10.1.0.5")}
+		Expect(createShadowNotes(svcs, eps, apiServer)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for the kubernetes service", func() {
+		kubernetes := service("kubernetes", "default")
+		kubernetes.Labels = map[string]string{"component": "apiserver", "provider": "kubernetes"}
+		svcs := []*corev1.Service{kubernetes}
+		Expect(createShadowNotes(svcs, []*corev1.Endpoints{apiServer}, apiServer)).To(HaveLen(0))
+	})
+
+	It("creates a note for a service shadowing the API server", func() {
+		expNote := &apiv1.Note{
+			Type:    shadowNoteType,
+			Summary: shadowNoteSummary,
+			Msg:     shadowNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name": "kubernetes",
+				"namespace":    "foo",
+				"reason_list":  "endpoint 10.0.0.1, name",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		svcs := []*corev1.Service{service("kubernetes", "foo")}
+		eps := []*corev1.Endpoints{endpoints("kubernetes", "foo", "10.0.0.1")}
+		Expect(createShadowNotes(svcs, eps, apiServer)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
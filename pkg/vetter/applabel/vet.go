@@ -67,7 +67,7 @@ func (m *AppLabel) Vet() ([]*apiv1.Note, error) {
 	}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 
 	return notes, nil
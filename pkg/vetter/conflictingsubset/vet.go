@@ -0,0 +1,141 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conflictingsubset vets the subsets of DestinationRule resources and
+// generates notes if DestinationRules for the same host define a subset with
+// the same name but different labels.
+package conflictingsubset
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                  = "ConflictingSubset"
+	conflictingSubsetNoteType = "conflicting-subset-labels"
+	conflictingSubsetSummary  = "Conflicting subset ${subset} - ${host}"
+	conflictingSubsetMsg      = "The DestinationRules ${dr_list} define the subset" +
+		" ${subset} of host ${host} with different labels: ${label_list}. Routes to" +
+		" the subset use the labels of only one of them. Consider defining the" +
+		" subset in a single DestinationRule."
+)
+
+// ConflictingSubset implements Vetter interface
+type ConflictingSubset struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	drLister  netv1alpha3.DestinationRuleLister
+}
+
+type subsetKey struct {
+	host   string
+	subset string
+}
+
+// createConflictingSubsetNotes generates a note for every subset name of a
+// host which DestinationRules define with different labels. Hosts are
+// normalized so that short names and FQDNs of the same Service match.
+func createConflictingSubsetNotes(svcs []*corev1.Service,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	// host and subset name -> labels -> DestinationRules defining the subset
+	subsets := map[subsetKey]map[string][]string{}
+	keys := []subsetKey{}
+	for _, dr := range drList {
+		host := resolver.Normalize(dr.Spec.GetHost(), dr.Namespace)
+		for _, s := range dr.Spec.GetSubsets() {
+			key := subsetKey{host: host, subset: s.GetName()}
+			if _, ok := subsets[key]; !ok {
+				subsets[key] = map[string][]string{}
+				keys = append(keys, key)
+			}
+			l := labels.Set(s.GetLabels()).String()
+			subsets[key][l] = append(subsets[key][l], dr.Namespace+"/"+dr.Name)
+		}
+	}
+	for _, key := range keys {
+		if len(subsets[key]) < 2 {
+			continue
+		}
+		labelList := []string{}
+		drNames := []string{}
+		for l, drs := range subsets[key] {
+			labelList = append(labelList, "{"+l+"}")
+			drNames = append(drNames, drs...)
+		}
+		sort.Strings(labelList)
+		sort.Strings(drNames)
+		notes = append(notes, &apiv1.Note{
+			Type:    conflictingSubsetNoteType,
+			Summary: conflictingSubsetSummary,
+			Msg:     conflictingSubsetMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"host":       key.host,
+				"subset":     key.subset,
+				"dr_list":    strings.Join(drNames, ", "),
+				"label_list": strings.Join(labelList, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (c *ConflictingSubset) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(c.nsLister, c.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			conflictingSubsetNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(c.nsLister, c.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createConflictingSubsetNotes(svcs, drList), nil
+}
+
+// Info returns information about the vetter
+func (c *ConflictingSubset) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ConflictingSubset" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ConflictingSubset {
+	return &ConflictingSubset{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conflictingsubset
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func destinationRule(name, host, version string) *v1alpha3.DestinationRule {
+	return &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha3.DestinationRuleSpec{
+			DestinationRule: istiov1alpha3.DestinationRule{
+				Host: host,
+				Subsets: []*istiov1alpha3.Subset{
+					{Name: "v1", Labels: map[string]string{"version": version}},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Conflicting subsets", func() {
+	svcs := []*corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"}},
+	}
+
+	It("creates zero notes for a single definition", func() {
+		drList := []*v1alpha3.DestinationRule{destinationRule("reviews", "reviews", "v1")}
+		Expect(createConflictingSubsetNotes(svcs, drList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for identical definitions", func() {
+		drList := []*v1alpha3.DestinationRule{
+			destinationRule("reviews", "reviews", "v1"),
+			destinationRule("reviews-tls", "reviews.default.svc.cluster.local", "v1"),
+		}
+		Expect(createConflictingSubsetNotes(svcs, drList)).To(HaveLen(0))
+	})
+
+	It("creates a note for conflicting definitions", func() {
+		expNote := &apiv1.Note{
+			Type:    conflictingSubsetNoteType,
+			Summary: conflictingSubsetSummary,
+			Msg:     conflictingSubsetMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"host":       "reviews.default.svc.cluster.local",
+				"subset":     "v1",
+				"dr_list":    "default/reviews, default/reviews-tls",
+				"label_list": "{version=v1}, {version=v2}",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		drList := []*v1alpha3.DestinationRule{
+			destinationRule("reviews-tls", "reviews.default.svc.cluster.local", "v2"),
+			destinationRule("reviews", "reviews", "v1"),
+		}
+		Expect(createConflictingSubsetNotes(svcs, drList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
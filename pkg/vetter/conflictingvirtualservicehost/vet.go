@@ -105,7 +105,7 @@ func CreateVirtualServiceNotes(virtualServices []*v1alpha3.VirtualService) ([]*a
 		return []*apiv1.Note{}, err
 	}
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 	return notes, nil
 }
@@ -268,7 +268,7 @@ var _ = Describe("Conflicting Virtual Service Host Vet Notes", func() {
 					"host":     "host2.bar.svc.cluster.local",
 					"routes":   "/foo exact /foo exact",
 				}}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			Expect(vsNotes[0]).To(Equal(expectedNote))
 		})
 
@@ -298,7 +298,7 @@ var _ = Describe("Conflicting Virtual Service Host Vet Notes", func() {
 					"host":     "host2.bar.svc.cluster.local",
 					"routes":   "/bar/foo prefix /bar/foo/baz exact",
 				}}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			Expect(vsNotes[0]).To(Equal(expectedNote))
 		})
 
@@ -318,7 +318,7 @@ var _ = Describe("Conflicting Virtual Service Host Vet Notes", func() {
 					"host":     "host2.bar.svc.cluster.local",
 					"routes":   "/f* regex /foo/bar prefix",
 					"vs_names": "Vs1.bar, Vs2.bar"}}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			Expect(vsNotes[0]).To(Equal(expectedNote))
 		})
 
@@ -420,13 +420,13 @@ var _ = Describe("Conflicting Virtual Service Host Vet Notes", func() {
 				},
 			}
 
-			expectedNote1.Id = util.ComputeID(expectedNote1)
-			expectedNote2.Id = util.ComputeID(expectedNote2)
-			expectedNote3.Id = util.ComputeID(expectedNote3)
-			expectedNote4.Id = util.ComputeID(expectedNote4)
-			expectedNote5.Id = util.ComputeID(expectedNote5)
-			expectedNote6.Id = util.ComputeID(expectedNote6)
-			expectedNote7.Id = util.ComputeID(expectedNote7)
+			expectedNote1.Id = util.ComputeIDStable(expectedNote1)
+			expectedNote2.Id = util.ComputeIDStable(expectedNote2)
+			expectedNote3.Id = util.ComputeIDStable(expectedNote3)
+			expectedNote4.Id = util.ComputeIDStable(expectedNote4)
+			expectedNote5.Id = util.ComputeIDStable(expectedNote5)
+			expectedNote6.Id = util.ComputeIDStable(expectedNote6)
+			expectedNote7.Id = util.ComputeIDStable(expectedNote7)
 
 			expecteds := []*apiv1.Note{expectedNote1, expectedNote2, expectedNote3, expectedNote4, expectedNote5,
 				expectedNote6, expectedNote7,
@@ -558,12 +558,12 @@ var _ = Describe("Conflicting Virtual Service Host Vet Notes", func() {
 			expecteds := []*apiv1.Note{expectedNote1, expectedNote2, expectedNote3, expectedNote4, expectedNote5,
 				expectedNote6,
 			}
-			expectedNote1.Id = util.ComputeID(expectedNote1)
-			expectedNote2.Id = util.ComputeID(expectedNote2)
-			expectedNote3.Id = util.ComputeID(expectedNote3)
-			expectedNote4.Id = util.ComputeID(expectedNote4)
-			expectedNote5.Id = util.ComputeID(expectedNote5)
-			expectedNote6.Id = util.ComputeID(expectedNote6)
+			expectedNote1.Id = util.ComputeIDStable(expectedNote1)
+			expectedNote2.Id = util.ComputeIDStable(expectedNote2)
+			expectedNote3.Id = util.ComputeIDStable(expectedNote3)
+			expectedNote4.Id = util.ComputeIDStable(expectedNote4)
+			expectedNote5.Id = util.ComputeIDStable(expectedNote5)
+			expectedNote6.Id = util.ComputeIDStable(expectedNote6)
 
 			vsNotes, err := CreateVirtualServiceNotes(vsList)
 
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplaneselfinjection vets the Istio control plane Namespace
+// and generates notes if it is labeled or configured for sidecar injection.
+package controlplaneselfinjection
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	admissionv1beta1 "k8s.io/client-go/listers/admissionregistration/v1beta1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID              = "ControlPlaneSelfInjection"
+	selfInjectionNoteType = "control-plane-self-injection"
+	selfInjectionSummary  = "Sidecar injection enabled for control plane - ${namespace}"
+	selfInjectionMsg      = "The control plane namespace ${namespace} is labeled or" +
+		" configured for sidecar injection. Control plane pods restarted in the" +
+		" namespace get a sidecar which can't bootstrap without the control plane" +
+		" itself. Consider removing the injection labels of the namespace or" +
+		" excluding it from the namespaceSelector of the sidecar injector webhook."
+)
+
+// ControlPlaneSelfInjection implements Vetter interface
+type ControlPlaneSelfInjection struct {
+	nsLister  v1.NamespaceLister
+	cmLister  v1.ConfigMapLister
+	mwcLister admissionv1beta1.MutatingWebhookConfigurationLister
+}
+
+// createSelfInjectionNotes generates a note if the control plane Namespace is
+// labeled for injection or selected by the sidecar injector webhook.
+func createSelfInjectionNotes(ns *corev1.Namespace,
+	cfg *util.SidecarInjectorConfig) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	if ns == nil {
+		return notes
+	}
+	if _, ok := util.InjectionRevision(ns); ok || cfg.InjectionEnabled(ns) {
+		notes = append(notes, &apiv1.Note{
+			Type:    selfInjectionNoteType,
+			Summary: selfInjectionSummary,
+			Msg:     selfInjectionMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"namespace": ns.Name,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (c *ControlPlaneSelfInjection) Vet() ([]*apiv1.Note, error) {
+	ns, err := c.nsLister.Get(util.IstioNamespace)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return []*apiv1.Note{}, nil
+		}
+		glog.Errorf("Failed to retrieve namespace: %s error: %s", util.IstioNamespace, err)
+		return nil, err
+	}
+	cfg, err := util.GetSidecarInjectorConfig(c.cmLister, c.mwcLister)
+	if err != nil {
+		return nil, err
+	}
+	return createSelfInjectionNotes(ns, cfg), nil
+}
+
+// Info returns information about the vetter
+func (c *ControlPlaneSelfInjection) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ControlPlaneSelfInjection" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ControlPlaneSelfInjection {
+	return &ControlPlaneSelfInjection{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister:  factory.K8s().Core().V1().ConfigMaps().Lister(),
+		mwcLister: factory.K8s().Admissionregistration().V1beta1().MutatingWebhookConfigurations().Lister(),
+	}
+}
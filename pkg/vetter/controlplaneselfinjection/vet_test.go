@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplaneselfinjection
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func istioNamespace(l map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: util.IstioNamespace, Labels: l}}
+}
+
+var _ = Describe("Sidecar injection for the control plane namespace", func() {
+	cfg := &util.SidecarInjectorConfig{
+		Policy:            util.InjectionPolicyEnabled,
+		NamespaceSelector: labels.SelectorFromSet(map[string]string{"istio-injection": "enabled"}),
+	}
+	expNote := &apiv1.Note{
+		Type:    selfInjectionNoteType,
+		Summary: selfInjectionSummary,
+		Msg:     selfInjectionMsg,
+		Level:   apiv1.NoteLevel_ERROR,
+		Attr:    map[string]string{"namespace": util.IstioNamespace},
+	}
+	expNote.Id = util.ComputeIDStable(expNote)
+
+	It("creates zero notes without the control plane namespace", func() {
+		Expect(createSelfInjectionNotes(nil, cfg)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for an exempt control plane namespace", func() {
+		Expect(createSelfInjectionNotes(istioNamespace(nil), cfg)).To(HaveLen(0))
+		ns := istioNamespace(map[string]string{"istio-injection": "disabled"})
+		Expect(createSelfInjectionNotes(ns, cfg)).To(HaveLen(0))
+	})
+
+	It("creates a note for a control plane namespace labeled for injection", func() {
+		ns := istioNamespace(map[string]string{"istio-injection": "enabled"})
+		Expect(createSelfInjectionNotes(ns, cfg)).To(Equal([]*apiv1.Note{expNote}))
+		ns = istioNamespace(map[string]string{"istio.io/rev": "canary"})
+		Expect(createSelfInjectionNotes(ns, cfg)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for a control plane namespace selected by the webhook", func() {
+		everything := &util.SidecarInjectorConfig{
+			Policy:            util.InjectionPolicyEnabled,
+			NamespaceSelector: labels.Everything(),
+		}
+		Expect(createSelfInjectionNotes(istioNamespace(nil), everything)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package corsheaderconflict vets the http routes of VirtualService resources
+// and generates notes if the response header manipulation of a route
+// overrides the CORS headers of its corsPolicy.
+package corsheaderconflict
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "CorsHeaderConflict"
+	corsConflictNoteType    = "cors-header-conflict"
+	corsConflictNoteSummary = "CORS headers overridden - ${vs_name}"
+	corsConflictNoteMsg     = "The http route ${route} of VirtualService ${vs_name} in" +
+		" namespace ${namespace} has a corsPolicy, but its response header" +
+		" manipulation also changes the header(s) ${header_list}. Clients receive" +
+		" contradictory CORS responses. Consider configuring these headers in the" +
+		" corsPolicy only."
+)
+
+// CorsHeaderConflict implements Vetter interface
+type CorsHeaderConflict struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// corsHeaders returns the response headers set by the CORS policy, keyed by
+// lower-cased name. The value of a header is empty if it depends on the
+// request, e.g. the allowed origin matching the Origin of the request.
+func corsHeaders(c *istiov1alpha3.CorsPolicy) map[string]string {
+	headers := map[string]string{}
+	if len(c.GetAllowOrigin()) == 1 {
+		headers["access-control-allow-origin"] = c.GetAllowOrigin()[0]
+	} else if len(c.GetAllowOrigin()) > 1 {
+		headers["access-control-allow-origin"] = ""
+	}
+	if len(c.GetAllowMethods()) > 0 {
+		headers["access-control-allow-methods"] = strings.Join(c.GetAllowMethods(), ",")
+	}
+	if len(c.GetAllowHeaders()) > 0 {
+		headers["access-control-allow-headers"] = strings.Join(c.GetAllowHeaders(), ",")
+	}
+	if len(c.GetExposeHeaders()) > 0 {
+		headers["access-control-expose-headers"] = strings.Join(c.GetExposeHeaders(), ",")
+	}
+	if c.GetMaxAge() != nil {
+		headers["access-control-max-age"] = strconv.FormatInt(c.GetMaxAge().GetSeconds(), 10)
+	}
+	if c.GetAllowCredentials() != nil {
+		headers["access-control-allow-credentials"] = strconv.FormatBool(c.GetAllowCredentials().GetValue())
+	}
+	return headers
+}
+
+// sameValue returns true if the comma separated header values are equal,
+// ignoring case and whitespace.
+func sameValue(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), ""))
+	}
+	return a != "" && normalize(a) == normalize(b)
+}
+
+// conflictingHeaders returns the sorted CORS headers of the route which its
+// response header manipulation sets to a different value, adds or removes.
+func conflictingHeaders(r *istiov1alpha3.HTTPRoute) []string {
+	if r.GetCorsPolicy() == nil || r.GetHeaders().GetResponse() == nil {
+		return nil
+	}
+	cors := corsHeaders(r.GetCorsPolicy())
+	ops := r.GetHeaders().GetResponse()
+	conflicts := map[string]bool{}
+	for h, v := range ops.GetSet() {
+		if corsValue, ok := cors[strings.ToLower(h)]; ok && !sameValue(corsValue, v) {
+			conflicts[h] = true
+		}
+	}
+	for h := range ops.GetAdd() {
+		if _, ok := cors[strings.ToLower(h)]; ok {
+			conflicts[h] = true
+		}
+	}
+	for _, h := range ops.GetRemove() {
+		if _, ok := cors[strings.ToLower(h)]; ok {
+			conflicts[h] = true
+		}
+	}
+	headers := []string{}
+	for h := range conflicts {
+		headers = append(headers, h)
+	}
+	sort.Strings(headers)
+	return headers
+}
+
+// createCorsConflictNotes generates a note for every http route whose
+// response header manipulation conflicts with its corsPolicy.
+func createCorsConflictNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for i, r := range vs.Spec.GetHttp() {
+			headers := conflictingHeaders(r)
+			if len(headers) == 0 {
+				continue
+			}
+			route := r.GetName()
+			if route == "" {
+				route = strconv.Itoa(i)
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    corsConflictNoteType,
+				Summary: corsConflictNoteSummary,
+				Msg:     corsConflictNoteMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"vs_name":     vs.Name,
+					"namespace":   vs.Namespace,
+					"route":       route,
+					"header_list": strings.Join(headers, ", "),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (c *CorsHeaderConflict) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(c.nsLister, c.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createCorsConflictNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (c *CorsHeaderConflict) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "CorsHeaderConflict" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *CorsHeaderConflict {
+	return &CorsHeaderConflict{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package corsheaderconflict
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(cors *istiov1alpha3.CorsPolicy, set map[string]string) []*v1alpha3.VirtualService {
+	var headers *istiov1alpha3.Headers
+	if set != nil {
+		headers = &istiov1alpha3.Headers{
+			Response: &istiov1alpha3.Headers_HeaderOperations{Set: set},
+		}
+	}
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: []string{"api"},
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Name: "api-v1",
+							Route: []*istiov1alpha3.HTTPRouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "api"}},
+							},
+							CorsPolicy: cors,
+							Headers:    headers,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("CORS header conflicts", func() {
+	cors := &istiov1alpha3.CorsPolicy{
+		AllowOrigin:  []string{"https://example.com"},
+		AllowMethods: []string{"GET", "POST"},
+	}
+
+	It("creates zero notes for a corsPolicy only", func() {
+		Expect(createCorsConflictNotes(virtualService(cors, nil))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for header manipulation only", func() {
+		set := map[string]string{"Access-Control-Allow-Origin": "*"}
+		Expect(createCorsConflictNotes(virtualService(nil, set))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for headers set to the corsPolicy values", func() {
+		set := map[string]string{
+			"Access-Control-Allow-Origin":  "https://example.com",
+			"Access-Control-Allow-Methods": "GET, POST",
+			"X-Frame-Options":              "DENY",
+		}
+		Expect(createCorsConflictNotes(virtualService(cors, set))).To(HaveLen(0))
+	})
+
+	It("creates a note for conflicting headers", func() {
+		expNote := &apiv1.Note{
+			Type:    corsConflictNoteType,
+			Summary: corsConflictNoteSummary,
+			Msg:     corsConflictNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":     "api",
+				"namespace":   "default",
+				"route":       "api-v1",
+				"header_list": "Access-Control-Allow-Origin",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		set := map[string]string{"Access-Control-Allow-Origin": "*"}
+		Expect(createCorsConflictNotes(virtualService(cors, set))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
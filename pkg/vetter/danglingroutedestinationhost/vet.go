@@ -16,7 +16,7 @@ limitations under the License.
 
 // Package danglingroutedestinationhost vets if HTTP route destination host in
 // any VirtualService resource points to services which don't exist in the
-// cluster.
+// cluster, neither as a Service nor as a host of a ServiceEntry.
 package danglingroutedestinationhost
 
 import (
@@ -46,25 +46,35 @@ type DanglingRouteDestinationHost struct {
 	nsLister  v1.NamespaceLister
 	svcLister v1.ServiceLister
 	vsLister  netv1alpha3.VirtualServiceLister
+	seLister  netv1alpha3.ServiceEntryLister
 }
 
-func createServiceMap(svcs []*corev1.Service) map[string]bool {
+// createServiceMap returns the FQDNs of the Services and of the hosts of the
+// ServiceEntries.
+func createServiceMap(svcs []*corev1.Service, seList []*v1alpha3.ServiceEntry) map[string]bool {
 	serviceMap := map[string]bool{}
 	for _, s := range svcs {
 		key := s.Name + "." + s.Namespace + util.KubernetesDomainSuffix
 		serviceMap[key] = true
 	}
+	for _, se := range seList {
+		for _, h := range se.Spec.GetHosts() {
+			if key, err := util.ConvertHostnameToFQDN(h, se.Namespace); err == nil {
+				serviceMap[key] = true
+			}
+		}
+	}
 	return serviceMap
 }
 
 // createDanglingRouteHostNotes creates notes for VirtualService(s) which have
 // dangling route hostname(s).
-func createDanglingRouteHostNotes(svcs []*corev1.Service,
+func createDanglingRouteHostNotes(svcs []*corev1.Service, seList []*v1alpha3.ServiceEntry,
 	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
 	var err error
 	var host string
 	notes := []*apiv1.Note{}
-	svcMap := createServiceMap(svcs)
+	svcMap := createServiceMap(svcs, seList)
 	for _, vs := range vsList {
 		danglingHostnames := []string{}
 		for _, routes := range vs.Spec.GetHttp() {
@@ -99,7 +109,7 @@ func createDanglingRouteHostNotes(svcs []*corev1.Service,
 	}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 
 	return notes
@@ -112,12 +122,17 @@ func (r *DanglingRouteDestinationHost) Vet() ([]*apiv1.Note, error) {
 		return nil, err
 	}
 
+	seList, err := util.ListServiceEntriesInMesh(r.nsLister, r.seLister)
+	if err != nil {
+		return nil, err
+	}
+
 	vsList, err := util.ListVirtualServicesInMesh(r.nsLister, r.vsLister)
 	if err != nil {
 		return nil, err
 	}
 
-	notes := createDanglingRouteHostNotes(svcs, vsList)
+	notes := createDanglingRouteHostNotes(svcs, seList, vsList)
 	return notes, nil
 }
 
@@ -132,5 +147,6 @@ func NewVetter(factory vetter.ResourceListGetter) *DanglingRouteDestinationHost
 		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
 		svcLister: factory.K8s().Core().V1().Services().Lister(),
 		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		seLister:  factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
 	}
 }
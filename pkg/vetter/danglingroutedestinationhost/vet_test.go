@@ -29,7 +29,7 @@ import (
 
 var _ = Describe("Vet", func() {
 	It("creates zero notes on empty lists", func() {
-		notes := createDanglingRouteHostNotes(nil, nil)
+		notes := createDanglingRouteHostNotes(nil, nil, nil)
 		Expect(notes).To(HaveLen(0))
 	})
 
@@ -116,7 +116,7 @@ var _ = Describe("Vet", func() {
 				},
 			},
 		}
-		notes := createDanglingRouteHostNotes(svcs, vsList)
+		notes := createDanglingRouteHostNotes(svcs, nil, vsList)
 		Expect(notes).To(HaveLen(0))
 	})
 
@@ -255,9 +255,66 @@ var _ = Describe("Vet", func() {
 			},
 		}
 		for i := range expNotes {
-			expNotes[i].Id = util.ComputeID(expNotes[i])
+			expNotes[i].Id = util.ComputeIDStable(expNotes[i])
 		}
-		notes := createDanglingRouteHostNotes(svcs, vsList)
+		notes := createDanglingRouteHostNotes(svcs, nil, vsList)
 		Expect(notes).To(Equal(expNotes))
 	})
+
+	It("matches hosts of service entries", func() {
+		seList := []*v1alpha3.ServiceEntry{
+			&v1alpha3.ServiceEntry{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "legacy",
+					Namespace: "team-foo",
+				},
+				Spec: v1alpha3.ServiceEntrySpec{
+					ServiceEntry: istiov1alpha3.ServiceEntry{
+						Hosts: []string{"legacy.team-foo.svc.cluster.local"},
+					},
+				},
+			},
+		}
+		route := func(host string) *istiov1alpha3.HTTPRoute {
+			return &istiov1alpha3.HTTPRoute{
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					&istiov1alpha3.HTTPRouteDestination{
+						Destination: &istiov1alpha3.Destination{Host: host},
+					},
+				},
+			}
+		}
+		vsList := []*v1alpha3.VirtualService{
+			&v1alpha3.VirtualService{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "team-foo",
+				},
+				Spec: v1alpha3.VirtualServiceSpec{
+					VirtualService: istiov1alpha3.VirtualService{
+						Http: []*istiov1alpha3.HTTPRoute{
+							route("legacy"),
+							route("legacy.team-foo.svc.cluster.local"),
+							route("legasy"),
+							route("legacy.team-bar.svc.cluster.local"),
+						},
+					},
+				},
+			},
+		}
+		expNote := &apiv1.Note{
+			Type:    danglingRouteDestinationHostNoteType,
+			Summary: danglingRouteDestinationHostNoteSummary,
+			Msg:     danglingRouteDestinationHostNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":       "foo",
+				"namespace":     "team-foo",
+				"hostname_list": "legasy,legacy.team-bar.svc.cluster.local",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes := createDanglingRouteHostNotes(nil, seList, vsList)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
 })
@@ -0,0 +1,154 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deploymentproxyconcurrency vets the sidecar proxies of the pods in
+// the mesh and generates notes if the pods of a Deployment run their proxies
+// with different concurrency settings.
+package deploymentproxyconcurrency
+
+import (
+	"sort"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                    = "DeploymentProxyConcurrency"
+	concurrencyMismatchNoteType = "deployment-proxy-concurrency-mismatch"
+	concurrencyMismatchSummary  = "Proxy concurrency differs across pods - ${workload}"
+	concurrencyMismatchMsg      = "The pods of ${workload} in namespace ${namespace}" +
+		" run their sidecar proxies with different concurrency settings:" +
+		" ${concurrency_list}. Replicas with fewer worker threads may show higher" +
+		" latency under load. Consider restarting the pods so that they share" +
+		" the same proxy configuration."
+	concurrencyArg  = "--concurrency"
+	concurrencyAuto = "auto"
+)
+
+// DeploymentProxyConcurrency implements Vetter interface
+type DeploymentProxyConcurrency struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+	rsLister  appsv1.ReplicaSetLister
+}
+
+// proxyConcurrency returns the value of the concurrency argument of the
+// istio-proxy container of the pod. A missing argument or a value of 0 lets
+// the proxy pick the number of worker threads and is returned as "auto".
+func proxyConcurrency(p *corev1.Pod) string {
+	for _, c := range p.Spec.Containers {
+		if c.Name != util.IstioProxyContainerName {
+			continue
+		}
+		for i, arg := range c.Args {
+			v := ""
+			if arg == concurrencyArg && i < len(c.Args)-1 {
+				v = c.Args[i+1]
+			} else if strings.HasPrefix(arg, concurrencyArg+"=") {
+				v = strings.TrimPrefix(arg, concurrencyArg+"=")
+			} else {
+				continue
+			}
+			if v == "0" {
+				return concurrencyAuto
+			}
+			return v
+		}
+	}
+	return concurrencyAuto
+}
+
+// createConcurrencyMismatchNotes generates a note for every Deployment whose
+// injected pods don't share the same proxy concurrency.
+func createConcurrencyMismatchNotes(pods []*corev1.Pod,
+	rsLister appsv1.ReplicaSetLister) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	workloads := []util.Workload{}
+	concurrency := map[util.Workload]map[string]bool{}
+	for _, p := range pods {
+		if !util.SidecarInjected(p) {
+			continue
+		}
+		w := util.ResolveWorkload(p, rsLister)
+		if w.Kind != "Deployment" {
+			continue
+		}
+		if concurrency[w] == nil {
+			concurrency[w] = map[string]bool{}
+			workloads = append(workloads, w)
+		}
+		concurrency[w][proxyConcurrency(p)] = true
+	}
+	for _, w := range workloads {
+		if len(concurrency[w]) < 2 {
+			continue
+		}
+		values := []string{}
+		for v := range concurrency[w] {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		notes = append(notes, &apiv1.Note{
+			Type:    concurrencyMismatchNoteType,
+			Summary: concurrencyMismatchSummary,
+			Msg:     concurrencyMismatchMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"workload":         w.String(),
+				"namespace":        w.Namespace,
+				"concurrency_list": strings.Join(values, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DeploymentProxyConcurrency) Vet() ([]*apiv1.Note, error) {
+	pods, err := util.ListPodsInMesh(d.nsLister, d.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			concurrencyMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createConcurrencyMismatchNotes(pods, d.rsLister), nil
+}
+
+// Info returns information about the vetter
+func (d *DeploymentProxyConcurrency) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DeploymentProxyConcurrency" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DeploymentProxyConcurrency {
+	return &DeploymentProxyConcurrency{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		rsLister:  factory.K8s().Apps().V1().ReplicaSets().Lister(),
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deploymentproxyconcurrency
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var controller = true
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func meshPod(name, replicaSet string, proxyArgs ...string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			OwnerReferences: controllerRef("ReplicaSet", replicaSet),
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "reviews"},
+				{Name: util.IstioProxyContainerName, Args: append([]string{"proxy", "sidecar"}, proxyArgs...)},
+			},
+		},
+	}
+}
+
+var _ = Describe("Deployment proxy concurrency", func() {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, rs := range []*appsv1.ReplicaSet{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-5d8f9",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "reviews")}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-7c4b2",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "reviews")}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "ratings-6f8d1",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "ratings")}},
+	} {
+		indexer.Add(rs)
+	}
+	rsLister := appsv1listers.NewReplicaSetLister(indexer)
+
+	It("creates zero notes if every pod uses the automatic concurrency", func() {
+		pods := []*corev1.Pod{
+			meshPod("reviews-5d8f9-a", "reviews-5d8f9"),
+			meshPod("reviews-7c4b2-a", "reviews-7c4b2", "--concurrency", "0"),
+		}
+		Expect(createConcurrencyMismatchNotes(pods, rsLister)).To(HaveLen(0))
+	})
+
+	It("creates zero notes if every pod pins the same concurrency", func() {
+		pods := []*corev1.Pod{
+			meshPod("reviews-5d8f9-a", "reviews-5d8f9", "--concurrency", "2"),
+			meshPod("reviews-7c4b2-a", "reviews-7c4b2", "--concurrency=2"),
+			meshPod("ratings-6f8d1-a", "ratings-6f8d1", "--concurrency", "4"),
+		}
+		Expect(createConcurrencyMismatchNotes(pods, rsLister)).To(HaveLen(0))
+	})
+
+	It("creates a note if the pods of a Deployment mix concurrency settings", func() {
+		pods := []*corev1.Pod{
+			meshPod("reviews-5d8f9-a", "reviews-5d8f9", "--concurrency", "2"),
+			meshPod("reviews-5d8f9-b", "reviews-5d8f9", "--concurrency", "2"),
+			meshPod("reviews-7c4b2-a", "reviews-7c4b2"),
+			meshPod("ratings-6f8d1-a", "ratings-6f8d1"),
+		}
+		expNote := &apiv1.Note{
+			Type:    concurrencyMismatchNoteType,
+			Summary: concurrencyMismatchSummary,
+			Msg:     concurrencyMismatchMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"workload":         "Deployment/reviews",
+				"namespace":        "default",
+				"concurrency_list": "2, auto",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createConcurrencyMismatchNotes(pods, rsLister)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
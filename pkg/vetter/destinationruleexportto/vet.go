@@ -0,0 +1,155 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package destinationruleexportto vets the exportTo visibility of
+// DestinationRule resources and generates notes if a DestinationRule is
+// exported to Namespaces its target Service isn't visible in.
+package destinationruleexportto
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID           = "DestinationRuleExportTo"
+	overExportNoteType = "dr-over-exported"
+	overExportSummary  = "DestinationRule exported beyond its Service - ${dr_name}"
+	overExportNoteMsg  = "The DestinationRule ${dr_name} in namespace ${namespace}" +
+		" for host ${host} is exported to ${dr_export_to}, but the service" +
+		" ${service_name} in namespace ${service_namespace} is only exported to" +
+		" ${service_export_to}. Namespaces which can't reach the service still" +
+		" get the traffic policy. Consider restricting the exportTo of the" +
+		" DestinationRule to the visibility of the service."
+)
+
+// DestinationRuleExportTo implements Vetter interface
+type DestinationRuleExportTo struct {
+	nsLister  v1.NamespaceLister
+	cmLister  v1.ConfigMapLister
+	svcLister v1.ServiceLister
+	drLister  netv1alpha3.DestinationRuleLister
+}
+
+// overExported returns true if the DestinationRule is visible in a Namespace
+// the Service isn't.
+func overExported(drExportTo []string, drNamespace string,
+	svcExportTo []string, svcNamespace string) bool {
+	for _, e := range drExportTo {
+		switch e {
+		case util.ExportToAll:
+			for _, s := range svcExportTo {
+				if s == util.ExportToAll {
+					return false
+				}
+			}
+			return true
+		case util.ExportToNamespace:
+			e = drNamespace
+		}
+		if !util.ExportedTo(svcExportTo, svcNamespace, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// createOverExportNotes generates a note for every DestinationRule exported
+// more widely than the Service of its host.
+func createOverExportNotes(mc *meshv1alpha1.MeshConfig, svcs []*corev1.Service,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, dr := range drList {
+		s := resolver.Resolve(dr.Spec.GetHost(), dr.Namespace)
+		if s == nil {
+			continue
+		}
+		drExportTo := util.DestinationRuleExportTo(dr, mc)
+		svcExportTo := util.ServiceExportTo(s, mc)
+		if !overExported(drExportTo, dr.Namespace, svcExportTo, s.Namespace) {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    overExportNoteType,
+			Summary: overExportSummary,
+			Msg:     overExportNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"dr_name":           dr.Name,
+				"namespace":         dr.Namespace,
+				"host":              dr.Spec.GetHost(),
+				"dr_export_to":      strings.Join(drExportTo, ", "),
+				"service_name":      s.Name,
+				"service_namespace": s.Namespace,
+				"service_export_to": strings.Join(svcExportTo, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DestinationRuleExportTo) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(d.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := util.GetMeshConfig(cm)
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := util.ListServicesInMesh(d.nsLister, d.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			overExportNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(d.nsLister, d.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createOverExportNotes(mc, svcs, drList), nil
+}
+
+// Info returns information about the vetter
+func (d *DestinationRuleExportTo) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DestinationRuleExportTo" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DestinationRuleExportTo {
+	return &DestinationRuleExportTo{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister:  factory.K8s().Core().V1().ConfigMaps().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
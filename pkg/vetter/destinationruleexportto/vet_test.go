@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destinationruleexportto
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func service(exportTo string) []*corev1.Service {
+	s := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+	}
+	if exportTo != "" {
+		s.Annotations = map[string]string{util.IstioExportToAnnotation: exportTo}
+	}
+	return []*corev1.Service{s}
+}
+
+func destinationRule(exportTo ...string) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host:     "reviews.default.svc.cluster.local",
+					ExportTo: exportTo,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("DestinationRule ExportTo Vet Notes", func() {
+	var mc *meshv1alpha1.MeshConfig
+
+	BeforeEach(func() {
+		mc = &meshv1alpha1.MeshConfig{}
+	})
+
+	It("does not generate notes if the visibility matches", func() {
+		notes := createOverExportNotes(mc, service("."), destinationRule("."))
+		Expect(notes).To(BeEmpty())
+		notes = createOverExportNotes(mc, service(""), destinationRule())
+		Expect(notes).To(BeEmpty())
+	})
+
+	It("generates a note if the DestinationRule is over-exported", func() {
+		notes := createOverExportNotes(mc, service("."), destinationRule("*"))
+		expNote := &apiv1.Note{
+			Type:    overExportNoteType,
+			Summary: overExportSummary,
+			Msg:     overExportNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"dr_name":           "reviews",
+				"namespace":         "default",
+				"host":              "reviews.default.svc.cluster.local",
+				"dr_export_to":      "*",
+				"service_name":      "reviews",
+				"service_namespace": "default",
+				"service_export_to": ".",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("uses the mesh defaults when exportTo isn't set", func() {
+		mc.DefaultServiceExportTo = []string{"."}
+		notes := createOverExportNotes(mc, service(""), destinationRule())
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["service_export_to"]).To(Equal("."))
+		Expect(notes[0].Attr["dr_export_to"]).To(Equal("*"))
+	})
+
+	It("does not generate notes if the DestinationRule is under-exported", func() {
+		notes := createOverExportNotes(mc, service("*"), destinationRule("."))
+		Expect(notes).To(BeEmpty())
+		notes = createOverExportNotes(mc, service(".,bookinfo"), destinationRule("bookinfo"))
+		Expect(notes).To(BeEmpty())
+	})
+})
@@ -0,0 +1,174 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package destinationrulekeepalive vets the connection pool settings of
+// DestinationRule resources and generates notes if TCP keepalive probes
+// start later than the idle timeout reaps the connections.
+package destinationrulekeepalive
+
+import (
+	"fmt"
+	"time"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/gogo/protobuf/types"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID              = "DestinationRuleKeepalive"
+	keepaliveIdleNoteType = "dr-keepalive-idle-timeout"
+	keepaliveIdleSummary  = "TCP keepalive exceeds idle timeout - ${dr_name}"
+	keepaliveIdleMsg      = "The ${scope} of DestinationRule ${dr_name} in namespace" +
+		" ${namespace} sends TCP keepalive probes after ${keepalive_time} of" +
+		" inactivity, but idle connections are closed after ${idle_timeout}." +
+		" Connections are reaped before keepalive fires and have to be" +
+		" re-established. Consider setting a keepalive time shorter than the" +
+		" idle timeout."
+	// defaultIdleTimeout is the idle timeout of upstream connections if the
+	// DestinationRule doesn't set one.
+	defaultIdleTimeout = time.Hour
+)
+
+// DestinationRuleKeepalive implements Vetter interface
+type DestinationRuleKeepalive struct {
+	nsLister v1.NamespaceLister
+	cmLister v1.ConfigMapLister
+	drLister netv1alpha3.DestinationRuleLister
+}
+
+// duration converts a protobuf duration, returning false if it isn't set or
+// invalid.
+func duration(d *types.Duration) (time.Duration, bool) {
+	if d == nil {
+		return 0, false
+	}
+	t, err := types.DurationFromProto(d)
+	if err != nil || t <= 0 {
+		return 0, false
+	}
+	return t, true
+}
+
+// keepaliveIdleMismatch returns the keepalive time and idle timeout of the
+// connection pool settings if keepalive probes start after the idle timeout.
+// Unset values default to the keepalive of the mesh and defaultIdleTimeout.
+// Connection pools setting neither value are never reported.
+func keepaliveIdleMismatch(cp *istiov1alpha3.ConnectionPoolSettings,
+	mc *meshv1alpha1.MeshConfig) (time.Duration, time.Duration, bool) {
+	keepalive, keepaliveSet := duration(cp.GetTcp().GetTcpKeepalive().GetTime())
+	idle, idleSet := duration(cp.GetHttp().GetIdleTimeout())
+	if !keepaliveSet && !idleSet {
+		return 0, 0, false
+	}
+	if !keepaliveSet {
+		if keepalive, keepaliveSet = duration(mc.GetTcpKeepalive().GetTime()); !keepaliveSet {
+			return 0, 0, false
+		}
+	}
+	if !idleSet {
+		idle = defaultIdleTimeout
+	}
+	return keepalive, idle, keepalive > idle
+}
+
+// createKeepaliveIdleNotes generates a note for every connection pool of the
+// DestinationRules whose TCP keepalive time is longer than its idle timeout.
+func createKeepaliveIdleNotes(mc *meshv1alpha1.MeshConfig,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, dr := range drList {
+		type pool struct {
+			scope string
+			cp    *istiov1alpha3.ConnectionPoolSettings
+		}
+		tp := dr.Spec.GetTrafficPolicy()
+		pools := []pool{{scope: "traffic policy", cp: tp.GetConnectionPool()}}
+		for _, pls := range tp.GetPortLevelSettings() {
+			pools = append(pools, pool{
+				scope: fmt.Sprintf("traffic policy of port %d", pls.GetPort().GetNumber()),
+				cp:    pls.GetConnectionPool(),
+			})
+		}
+		for _, subset := range dr.Spec.GetSubsets() {
+			pools = append(pools, pool{
+				scope: "traffic policy of subset " + subset.GetName(),
+				cp:    subset.GetTrafficPolicy().GetConnectionPool(),
+			})
+		}
+		for _, p := range pools {
+			keepalive, idle, mismatch := keepaliveIdleMismatch(p.cp, mc)
+			if !mismatch {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    keepaliveIdleNoteType,
+				Summary: keepaliveIdleSummary,
+				Msg:     keepaliveIdleMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"dr_name":        dr.Name,
+					"namespace":      dr.Namespace,
+					"scope":          p.scope,
+					"keepalive_time": keepalive.String(),
+					"idle_timeout":   idle.String(),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DestinationRuleKeepalive) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(d.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := util.GetMeshConfig(cm)
+	if err != nil {
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(d.nsLister, d.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createKeepaliveIdleNotes(mc, drList), nil
+}
+
+// Info returns information about the vetter
+func (d *DestinationRuleKeepalive) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DestinationRuleKeepalive" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DestinationRuleKeepalive {
+	return &DestinationRuleKeepalive{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister: factory.K8s().Core().V1().ConfigMaps().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destinationrulekeepalive
+
+import (
+	"time"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func connectionPool(keepalive, idle time.Duration) *istiov1alpha3.ConnectionPoolSettings {
+	cp := &istiov1alpha3.ConnectionPoolSettings{}
+	if keepalive != 0 {
+		cp.Tcp = &istiov1alpha3.ConnectionPoolSettings_TCPSettings{
+			TcpKeepalive: &istiov1alpha3.ConnectionPoolSettings_TCPSettings_TcpKeepalive{
+				Time: types.DurationProto(keepalive),
+			},
+		}
+	}
+	if idle != 0 {
+		cp.Http = &istiov1alpha3.ConnectionPoolSettings_HTTPSettings{
+			IdleTimeout: types.DurationProto(idle),
+		}
+	}
+	return cp
+}
+
+func destinationRule(cp *istiov1alpha3.ConnectionPoolSettings) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host:          "reviews.default.svc.cluster.local",
+					TrafficPolicy: &istiov1alpha3.TrafficPolicy{ConnectionPool: cp},
+				},
+			},
+		},
+	}
+}
+
+func keepaliveNote(keepalive, idle string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    keepaliveIdleNoteType,
+		Summary: keepaliveIdleSummary,
+		Msg:     keepaliveIdleMsg,
+		Level:   apiv1.NoteLevel_INFO,
+		Attr: map[string]string{
+			"dr_name":        "reviews",
+			"namespace":      "default",
+			"scope":          "traffic policy",
+			"keepalive_time": keepalive,
+			"idle_timeout":   idle,
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("DestinationRule TCP keepalive and idle timeouts", func() {
+	It("creates zero notes on empty lists", func() {
+		Expect(createKeepaliveIdleNotes(nil, nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a keepalive time shorter than the idle timeout", func() {
+		drList := destinationRule(connectionPool(5*time.Minute, 30*time.Minute))
+		Expect(createKeepaliveIdleNotes(nil, drList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a keepalive time longer than the idle timeout", func() {
+		drList := destinationRule(connectionPool(2*time.Hour, 30*time.Minute))
+		Expect(createKeepaliveIdleNotes(nil, drList)).To(Equal([]*apiv1.Note{
+			keepaliveNote("2h0m0s", "30m0s"),
+		}))
+	})
+
+	It("falls back to the mesh keepalive and the default idle timeout", func() {
+		mc := &meshv1alpha1.MeshConfig{
+			TcpKeepalive: &istiov1alpha3.ConnectionPoolSettings_TCPSettings_TcpKeepalive{
+				Time: types.DurationProto(30 * time.Minute),
+			},
+		}
+		Expect(createKeepaliveIdleNotes(nil, destinationRule(connectionPool(0, 10*time.Minute)))).To(HaveLen(0))
+		Expect(createKeepaliveIdleNotes(mc, destinationRule(connectionPool(0, 10*time.Minute)))).To(Equal([]*apiv1.Note{
+			keepaliveNote("30m0s", "10m0s"),
+		}))
+		Expect(createKeepaliveIdleNotes(mc, destinationRule(connectionPool(2*time.Hour, 0)))).To(Equal([]*apiv1.Note{
+			keepaliveNote("2h0m0s", "1h0m0s"),
+		}))
+		Expect(createKeepaliveIdleNotes(mc, destinationRule(nil))).To(HaveLen(0))
+	})
+})
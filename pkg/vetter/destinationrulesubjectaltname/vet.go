@@ -0,0 +1,220 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package destinationrulesubjectaltname vets the TLS settings of
+// DestinationRule resources and generates notes if the subjectAltNames don't
+// match the SPIFFE identity of the destination workloads.
+package destinationrulesubjectaltname
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID            = "DestinationRuleSubjectAltName"
+	sanMismatchNoteType = "dr-subject-alt-name-mismatch"
+	sanMismatchSummary  = "TLS subjectAltNames don't match the destination - ${dr_name}"
+	sanMismatchMsg      = "The TLS settings of DestinationRule ${dr_name} in namespace" +
+		" ${namespace} only accept the subjectAltNames ${san_list}, but the" +
+		" destination workloads have the identities ${identity_list}. The mTLS" +
+		" handshake with these workloads fails. Consider adding their identities" +
+		" to the subjectAltNames."
+	defaultTrustDomain    = "cluster.local"
+	defaultServiceAccount = "default"
+)
+
+// DestinationRuleSubjectAltName implements Vetter interface
+type DestinationRuleSubjectAltName struct {
+	nsLister  v1.NamespaceLister
+	cmLister  v1.ConfigMapLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+	drLister  netv1alpha3.DestinationRuleLister
+}
+
+// trustDomains returns the trust domain of the mesh and its aliases.
+func trustDomains(mc *meshv1alpha1.MeshConfig) []string {
+	td := mc.GetTrustDomain()
+	if td == "" {
+		td = defaultTrustDomain
+	}
+	return append([]string{td}, mc.GetTrustDomainAliases()...)
+}
+
+// spiffeID returns the SPIFFE identity of the service account in the trust
+// domain.
+func spiffeID(trustDomain, namespace, serviceAccount string) string {
+	return "spiffe://" + trustDomain + "/ns/" + namespace + "/sa/" + serviceAccount
+}
+
+// unmatchedIdentities returns the sorted identities of the pods which none of
+// the subjectAltNames match in any of the trust domains.
+func unmatchedIdentities(sans, trustDomains []string, pods []*corev1.Pod) []string {
+	accepted := map[string]bool{}
+	for _, san := range sans {
+		accepted[san] = true
+	}
+	unmatched := map[string]bool{}
+	for _, p := range pods {
+		sa := p.Spec.ServiceAccountName
+		if sa == "" {
+			sa = defaultServiceAccount
+		}
+		matched := false
+		for _, td := range trustDomains {
+			if accepted[spiffeID(td, p.Namespace, sa)] {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched[spiffeID(trustDomains[0], p.Namespace, sa)] = true
+		}
+	}
+	ids := []string{}
+	for id := range unmatched {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// mutualTLS returns true if the TLS settings use mutual TLS with explicit
+// subjectAltNames.
+func mutualTLS(tls *istiov1alpha3.TLSSettings) bool {
+	switch tls.GetMode() {
+	case istiov1alpha3.TLSSettings_ISTIO_MUTUAL, istiov1alpha3.TLSSettings_MUTUAL:
+		return len(tls.GetSubjectAltNames()) > 0
+	}
+	return false
+}
+
+// createSANMismatchNotes generates a note for every mutual TLS setting of the
+// DestinationRules whose subjectAltNames don't match the identity of all the
+// pods it applies to. Settings of a subset apply to the pods of the subset.
+func createSANMismatchNotes(mc *meshv1alpha1.MeshConfig, svcs []*corev1.Service,
+	pods []*corev1.Pod, drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	tds := trustDomains(mc)
+	resolver := util.NewHostResolver(svcs)
+	for _, dr := range drList {
+		s := resolver.Resolve(dr.Spec.GetHost(), dr.Namespace)
+		if s == nil {
+			continue
+		}
+		type setting struct {
+			tls  *istiov1alpha3.TLSSettings
+			pods []*corev1.Pod
+		}
+		svcPods := util.SubsetPods(s, nil, pods)
+		settings := []setting{{tls: dr.Spec.GetTrafficPolicy().GetTls(), pods: svcPods}}
+		for _, pls := range dr.Spec.GetTrafficPolicy().GetPortLevelSettings() {
+			settings = append(settings, setting{tls: pls.GetTls(), pods: svcPods})
+		}
+		for _, subset := range dr.Spec.GetSubsets() {
+			settings = append(settings, setting{
+				tls:  subset.GetTrafficPolicy().GetTls(),
+				pods: util.SubsetPods(s, subset.GetLabels(), pods),
+			})
+		}
+		for _, st := range settings {
+			if !mutualTLS(st.tls) {
+				continue
+			}
+			ids := unmatchedIdentities(st.tls.GetSubjectAltNames(), tds, st.pods)
+			if len(ids) == 0 {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    sanMismatchNoteType,
+				Summary: sanMismatchSummary,
+				Msg:     sanMismatchMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"dr_name":       dr.Name,
+					"namespace":     dr.Namespace,
+					"san_list":      strings.Join(st.tls.GetSubjectAltNames(), ", "),
+					"identity_list": strings.Join(ids, ", "),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DestinationRuleSubjectAltName) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(d.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := util.GetMeshConfig(cm)
+	if err != nil {
+		return nil, err
+	}
+	svcs, err := util.ListServicesInMesh(d.nsLister, d.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			sanMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(d.nsLister, d.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			sanMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(d.nsLister, d.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createSANMismatchNotes(mc, svcs, pods, drList), nil
+}
+
+// Info returns information about the vetter
+func (d *DestinationRuleSubjectAltName) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DestinationRuleSubjectAltName" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DestinationRuleSubjectAltName {
+	return &DestinationRuleSubjectAltName{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister:  factory.K8s().Core().V1().ConfigMaps().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
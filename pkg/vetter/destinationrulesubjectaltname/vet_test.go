@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destinationrulesubjectaltname
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func destinationRule(sans ...string) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host: "reviews",
+					TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+						Tls: &istiov1alpha3.TLSSettings{
+							Mode:            istiov1alpha3.TLSSettings_ISTIO_MUTUAL,
+							SubjectAltNames: sans,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("DestinationRule subjectAltNames", func() {
+	mc := &meshv1alpha1.MeshConfig{}
+	svcs := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+		},
+	}
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "reviews-v1",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "reviews"},
+			},
+			Spec: corev1.PodSpec{ServiceAccountName: "bookinfo-reviews"},
+		},
+	}
+
+	It("creates zero notes for matching subjectAltNames", func() {
+		drList := destinationRule("spiffe://cluster.local/ns/default/sa/bookinfo-reviews")
+		Expect(createSANMismatchNotes(mc, svcs, pods, drList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes without subjectAltNames", func() {
+		Expect(createSANMismatchNotes(mc, svcs, pods, destinationRule())).To(HaveLen(0))
+	})
+
+	It("creates a note for mismatched subjectAltNames", func() {
+		expNote := &apiv1.Note{
+			Type:    sanMismatchNoteType,
+			Summary: sanMismatchSummary,
+			Msg:     sanMismatchMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":       "reviews",
+				"namespace":     "default",
+				"san_list":      "spiffe://cluster.local/ns/default/sa/default",
+				"identity_list": "spiffe://cluster.local/ns/default/sa/bookinfo-reviews",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		drList := destinationRule("spiffe://cluster.local/ns/default/sa/default")
+		Expect(createSANMismatchNotes(mc, svcs, pods, drList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package destinationrulesubset vets the subsets of DestinationRule resources
+// and generates notes if the labels of a subset select none of the pods of
+// the target Service.
+package destinationrulesubset
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                  = "DestinationRuleSubset"
+	unreachableSubsetNoteType = "dr-subset-no-pods"
+	unreachableSubsetSummary  = "Unreachable subset - ${dr_name}"
+	unreachableSubsetMsg      = "The labels ${label_selector} of subset ${subset} of" +
+		" DestinationRule ${dr_name} in namespace ${namespace} select none of the" +
+		" pods of service ${service_name}. Requests routed to the subset fail" +
+		" with 503 errors. Consider correcting the labels of the subset."
+)
+
+// DestinationRuleSubset implements Vetter interface
+type DestinationRuleSubset struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+	drLister  netv1alpha3.DestinationRuleLister
+}
+
+// createUnreachableSubsetNotes generates a note for every subset of the
+// DestinationRules which selects none of the pods of its Service. Services
+// without pods are skipped since none of their subsets can select pods.
+func createUnreachableSubsetNotes(svcs []*corev1.Service, pods []*corev1.Pod,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, dr := range drList {
+		s := resolver.Resolve(dr.Spec.GetHost(), dr.Namespace)
+		if s == nil || len(util.SubsetPods(s, nil, pods)) == 0 {
+			continue
+		}
+		for _, subset := range dr.Spec.GetSubsets() {
+			if len(util.SubsetPods(s, subset.GetLabels(), pods)) > 0 {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    unreachableSubsetNoteType,
+				Summary: unreachableSubsetSummary,
+				Msg:     unreachableSubsetMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"dr_name":        dr.Name,
+					"namespace":      dr.Namespace,
+					"subset":         subset.GetName(),
+					"label_selector": labels.Set(subset.GetLabels()).String(),
+					"service_name":   s.Name,
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DestinationRuleSubset) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(d.nsLister, d.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			unreachableSubsetNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(d.nsLister, d.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			unreachableSubsetNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(d.nsLister, d.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createUnreachableSubsetNotes(svcs, pods, drList), nil
+}
+
+// Info returns information about the vetter
+func (d *DestinationRuleSubset) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DestinationRuleSubset" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DestinationRuleSubset {
+	return &DestinationRuleSubset{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
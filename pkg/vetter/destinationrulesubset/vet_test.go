@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destinationrulesubset
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(name string, l map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: l}}
+}
+
+func destinationRule(subsetLabels map[string]string) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host:    "reviews",
+					Subsets: []*istiov1alpha3.Subset{{Name: "v1", Labels: subsetLabels}},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Unreachable DestinationRule subsets", func() {
+	svcs := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+		},
+	}
+	pods := []*corev1.Pod{
+		pod("reviews-v1-a", map[string]string{"app": "reviews", "version": "v1"}),
+		pod("reviews-v1-b", map[string]string{"app": "reviews", "version": "v1"}),
+		pod("ratings-v2", map[string]string{"app": "ratings", "version": "v2"}),
+	}
+
+	It("creates zero notes for a subset matching multiple pods", func() {
+		drList := destinationRule(map[string]string{"version": "v1"})
+		Expect(createUnreachableSubsetNotes(svcs, pods, drList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a service without pods", func() {
+		drList := destinationRule(map[string]string{"version": "v1"})
+		Expect(createUnreachableSubsetNotes(svcs, pods[2:], drList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a subset with a label typo", func() {
+		expNote := &apiv1.Note{
+			Type:    unreachableSubsetNoteType,
+			Summary: unreachableSubsetSummary,
+			Msg:     unreachableSubsetMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":        "reviews",
+				"namespace":      "default",
+				"subset":         "v1",
+				"label_selector": "verison=v1",
+				"service_name":   "reviews",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		drList := destinationRule(map[string]string{"verison": "v1"})
+		Expect(createUnreachableSubsetNotes(svcs, pods, drList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for a subset matching pods of another service", func() {
+		drList := destinationRule(map[string]string{"version": "v2"})
+		Expect(createUnreachableSubsetNotes(svcs, pods, drList)).To(HaveLen(1))
+	})
+})
@@ -0,0 +1,191 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package destinationruletlsfilepath vets the certificate and key file paths
+// referenced by DestinationRule TLS settings and generates notes if they are
+// outside the directories mounted into the sidecar proxy.
+package destinationruletlsfilepath
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "DestinationRuleTLSFilePath"
+	tlsFilePathNoteType    = "tls-file-not-mounted"
+	tlsFilePathNoteSummary = "TLS file path not mounted in sidecar - ${dr_name}"
+	tlsFilePathNoteMsg     = "The DestinationRule ${dr_name} in namespace ${namespace}" +
+		" references the ${tls_field} file ${file_path} in its ${location} which is" +
+		" outside the directories mounted into the sidecar proxy (${mount_dirs})." +
+		" TLS origination will fail unless the file is mounted, e.g. with the" +
+		" \"sidecar.istio.io/userVolumeMount\" annotation. Consider moving the file" +
+		" into one of the mounted directories."
+)
+
+// defaultMountDirs are the directories which hold certificates in the sidecar
+// and gateway proxies when the injector configuration cannot be read.
+var defaultMountDirs = []string{"/etc/certs/", "/etc/istio/"}
+
+// TLSFilePath implements Vetter interface
+type TLSFilePath struct {
+	nsLister v1.NamespaceLister
+	cmLister v1.ConfigMapLister
+	drLister netv1alpha3.DestinationRuleLister
+}
+
+type locatedTLSSettings struct {
+	location string
+	tls      *istiov1alpha3.TLSSettings
+}
+
+// trafficPolicyTLS returns the TLS settings of the traffic policy and all its
+// port level settings, described by where they are defined.
+func trafficPolicyTLS(prefix string, tp *istiov1alpha3.TrafficPolicy) []locatedTLSSettings {
+	settings := []locatedTLSSettings{}
+	if tp == nil {
+		return settings
+	}
+	if tls := tp.GetTls(); tls != nil {
+		settings = append(settings, locatedTLSSettings{prefix + "traffic policy", tls})
+	}
+	for _, pl := range tp.GetPortLevelSettings() {
+		if tls := pl.GetTls(); tls != nil {
+			loc := fmt.Sprintf("%sport %d traffic policy", prefix, pl.GetPort().GetNumber())
+			settings = append(settings, locatedTLSSettings{loc, tls})
+		}
+	}
+	return settings
+}
+
+func isMounted(file string, mountDirs []string) bool {
+	clean := path.Clean(file)
+	for _, d := range mountDirs {
+		dir := strings.TrimSuffix(path.Clean(d), "/") + "/"
+		if strings.HasPrefix(clean, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// createTLSFilePathNotes generates notes for the TLS files referenced by
+// DestinationRules which are not under any of mountDirs. Only SIMPLE and
+// MUTUAL modes are inspected since ISTIO_MUTUAL ignores the file paths.
+func createTLSFilePathNotes(drList []*v1alpha3.DestinationRule, mountDirs []string) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, dr := range drList {
+		settings := trafficPolicyTLS("", dr.Spec.GetTrafficPolicy())
+		for _, s := range dr.Spec.GetSubsets() {
+			settings = append(settings,
+				trafficPolicyTLS("subset "+s.GetName()+" ", s.GetTrafficPolicy())...)
+		}
+		for _, ls := range settings {
+			mode := ls.tls.GetMode()
+			if mode != istiov1alpha3.TLSSettings_SIMPLE && mode != istiov1alpha3.TLSSettings_MUTUAL {
+				continue
+			}
+			files := []struct {
+				field string
+				path  string
+			}{
+				{"clientCertificate", ls.tls.GetClientCertificate()},
+				{"privateKey", ls.tls.GetPrivateKey()},
+				{"caCertificates", ls.tls.GetCaCertificates()},
+			}
+			for _, f := range files {
+				if f.path == "" || isMounted(f.path, mountDirs) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    tlsFilePathNoteType,
+					Summary: tlsFilePathNoteSummary,
+					Msg:     tlsFilePathNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"dr_name":    dr.Name,
+						"namespace":  dr.Namespace,
+						"tls_field":  f.field,
+						"file_path":  f.path,
+						"location":   ls.location,
+						"mount_dirs": strings.Join(mountDirs, ", "),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// sidecarMountDirs returns the directories mounted into the istio-proxy
+// container by the sidecar injector, in addition to the default certificate
+// directories.
+func sidecarMountDirs(cmLister v1.ConfigMapLister) []string {
+	dirs := append([]string{}, defaultMountDirs...)
+	spec, err := util.GetInitializerSidecarSpec(cmLister)
+	if err != nil {
+		glog.V(2).Infof("Using default sidecar mount directories: %s", err)
+		return dirs
+	}
+	for _, c := range spec.Containers {
+		if c.Name != util.IstioProxyContainerName {
+			continue
+		}
+		for _, vm := range c.VolumeMounts {
+			if !isMounted(vm.MountPath+"/", dirs) {
+				dirs = append(dirs, vm.MountPath)
+			}
+		}
+	}
+	return dirs
+}
+
+// Vet returns the list of generated notes
+func (t *TLSFilePath) Vet() ([]*apiv1.Note, error) {
+	drList, err := util.ListDestinationRulesInMesh(t.nsLister, t.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createTLSFilePathNotes(drList, sidecarMountDirs(t.cmLister)), nil
+}
+
+// Info returns information about the vetter
+func (t *TLSFilePath) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "TLSFilePath" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *TLSFilePath {
+	return &TLSFilePath{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister: factory.K8s().Core().V1().ConfigMaps().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
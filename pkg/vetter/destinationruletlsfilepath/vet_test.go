@@ -0,0 +1,119 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package destinationruletlsfilepath
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func tlsDestinationRule(name string, tls *istiov1alpha3.TLSSettings) *v1alpha3.DestinationRule {
+	return &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha3.DestinationRuleSpec{
+			DestinationRule: istiov1alpha3.DestinationRule{
+				Host: "external.example.com",
+				TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+					Tls: tls,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("DestinationRule TLS file paths", func() {
+	mountDirs := []string{"/etc/certs/", "/etc/istio/"}
+
+	It("creates zero notes on empty lists", func() {
+		Expect(createTLSFilePathNotes(nil, mountDirs)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for files in the standard mount directories", func() {
+		drList := []*v1alpha3.DestinationRule{
+			tlsDestinationRule("standard", &istiov1alpha3.TLSSettings{
+				Mode:              istiov1alpha3.TLSSettings_MUTUAL,
+				ClientCertificate: "/etc/certs/cert-chain.pem",
+				PrivateKey:        "/etc/certs/key.pem",
+				CaCertificates:    "/etc/istio/egress-ca-certs/ca-chain.pem",
+			}),
+			// ISTIO_MUTUAL ignores the file paths
+			tlsDestinationRule("istio-mutual", &istiov1alpha3.TLSSettings{
+				Mode:              istiov1alpha3.TLSSettings_ISTIO_MUTUAL,
+				ClientCertificate: "/tmp/cert.pem",
+			}),
+		}
+		Expect(createTLSFilePathNotes(drList, mountDirs)).To(HaveLen(0))
+	})
+
+	It("creates notes for files outside the mount directories", func() {
+		drList := []*v1alpha3.DestinationRule{
+			tlsDestinationRule("nonstandard", &istiov1alpha3.TLSSettings{
+				Mode:              istiov1alpha3.TLSSettings_MUTUAL,
+				ClientCertificate: "/etc/certs/cert-chain.pem",
+				PrivateKey:        "/etc/certsfoo/key.pem",
+			}),
+		}
+		expNote := &apiv1.Note{
+			Type:    tlsFilePathNoteType,
+			Summary: tlsFilePathNoteSummary,
+			Msg:     tlsFilePathNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":    "nonstandard",
+				"namespace":  "default",
+				"tls_field":  "privateKey",
+				"file_path":  "/etc/certsfoo/key.pem",
+				"location":   "traffic policy",
+				"mount_dirs": "/etc/certs/, /etc/istio/",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createTLSFilePathNotes(drList, mountDirs)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("inspects subset and port level TLS settings", func() {
+		dr := tlsDestinationRule("subsets", nil)
+		dr.Spec.Subsets = []*istiov1alpha3.Subset{
+			{
+				Name: "v1",
+				TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+					PortLevelSettings: []*istiov1alpha3.TrafficPolicy_PortTrafficPolicy{
+						{
+							Port: &istiov1alpha3.PortSelector{Number: 443},
+							Tls: &istiov1alpha3.TLSSettings{
+								Mode:           istiov1alpha3.TLSSettings_SIMPLE,
+								CaCertificates: "/opt/ca.pem",
+							},
+						},
+					},
+				},
+			},
+		}
+		notes := createTLSFilePathNotes([]*v1alpha3.DestinationRule{dr}, mountDirs)
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["location"]).To(Equal("subset v1 port 443 traffic policy"))
+		Expect(notes[0].Attr["tls_field"]).To(Equal("caCertificates"))
+	})
+})
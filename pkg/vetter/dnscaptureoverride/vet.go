@@ -0,0 +1,159 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dnscaptureoverride vets the proxy metadata of pods in the mesh and
+// generates notes if they override the DNS capture setting of the mesh.
+package dnscaptureoverride
+
+import (
+	"strconv"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID              = "DNSCaptureOverride"
+	dnsCaptureNoteType    = "dns-capture-override"
+	dnsCaptureNoteSummary = "DNS capture override - ${pod_name}"
+	dnsCaptureNoteMsg     = "The pod ${pod_name} in namespace ${namespace} sets" +
+		" ${metadata_key} to ${pod_value} in the ${annotation} annotation, while" +
+		" the mesh default is ${mesh_value}. Hosts of ServiceEntries resolve" +
+		" differently for this pod than for the rest of the mesh. Consider" +
+		" removing the override unless it is intended."
+	proxyConfigAnnotation = "proxy.istio.io/config"
+	dnsCaptureMetadataKey = "ISTIO_META_DNS_CAPTURE"
+)
+
+// proxyConfig holds the fields of the proxy config used by the vetter. The
+// ProxyConfig type of the mesh API in this tree doesn't have proxyMetadata.
+type proxyConfig struct {
+	ProxyMetadata map[string]string `json:"proxyMetadata"`
+}
+
+type meshConfig struct {
+	DefaultConfig proxyConfig `json:"defaultConfig"`
+}
+
+// DNSCaptureOverride implements Vetter interface
+type DNSCaptureOverride struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+	cmLister  v1.ConfigMapLister
+}
+
+// dnsCapture returns the DNS capture setting of the proxy config and whether
+// it is set.
+func dnsCapture(cfg proxyConfig) (bool, bool) {
+	v, ok := cfg.ProxyMetadata[dnsCaptureMetadataKey]
+	if !ok {
+		return false, false
+	}
+	enabled, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, false
+	}
+	return enabled, true
+}
+
+// meshDNSCapture returns the DNS capture setting of the default proxy config
+// of the mesh. DNS capture is disabled unless the mesh config enables it.
+func meshDNSCapture(cm *corev1.ConfigMap) bool {
+	cfg := meshConfig{}
+	if err := yaml.Unmarshal([]byte(cm.Data[util.IstioConfigMapKey]), &cfg); err != nil {
+		glog.Errorf("Failed to parse yaml mesh config: %s", err)
+		return false
+	}
+	enabled, _ := dnsCapture(cfg.DefaultConfig)
+	return enabled
+}
+
+// createDNSCaptureNotes generates notes for the injected pods whose proxy
+// config annotation sets DNS capture differently than the mesh default.
+func createDNSCaptureNotes(meshEnabled bool, pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, p := range pods {
+		annotation, ok := p.Annotations[proxyConfigAnnotation]
+		if !ok || !util.SidecarInjected(p) {
+			continue
+		}
+		cfg := proxyConfig{}
+		if err := yaml.Unmarshal([]byte(annotation), &cfg); err != nil {
+			glog.V(2).Infof("Unable to parse %s annotation of pod %s/%s: %s",
+				proxyConfigAnnotation, p.Namespace, p.Name, err)
+			continue
+		}
+		podEnabled, set := dnsCapture(cfg)
+		if !set || podEnabled == meshEnabled {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    dnsCaptureNoteType,
+			Summary: dnsCaptureNoteSummary,
+			Msg:     dnsCaptureNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"pod_name":     p.Name,
+				"namespace":    p.Namespace,
+				"annotation":   proxyConfigAnnotation,
+				"metadata_key": dnsCaptureMetadataKey,
+				"pod_value":    strconv.FormatBool(podEnabled),
+				"mesh_value":   strconv.FormatBool(meshEnabled),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (d *DNSCaptureOverride) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(d.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(d.nsLister, d.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			dnsCaptureNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createDNSCaptureNotes(meshDNSCapture(cm), pods), nil
+}
+
+// Info returns information about the vetter
+func (d *DNSCaptureOverride) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "DNSCaptureOverride" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *DNSCaptureOverride {
+	return &DNSCaptureOverride{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		cmLister:  factory.K8s().Core().V1().ConfigMaps().Lister(),
+	}
+}
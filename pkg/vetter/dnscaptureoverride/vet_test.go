@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dnscaptureoverride
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func injectedPod(proxyConfig string) []*corev1.Pod {
+	annotations := map[string]string{util.IstioInitializerPodAnnotation: "{}"}
+	if proxyConfig != "" {
+		annotations[proxyConfigAnnotation] = proxyConfig
+	}
+	return []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "reviews-1",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "reviews"},
+					{Name: util.IstioProxyContainerName},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("DNS capture overrides", func() {
+	meshCM := &corev1.ConfigMap{
+		Data: map[string]string{
+			util.IstioConfigMapKey: "defaultConfig:\n" +
+				"  proxyMetadata:\n" +
+				"    ISTIO_META_DNS_CAPTURE: \"true\"\n",
+		},
+	}
+
+	It("reads the mesh default", func() {
+		Expect(meshDNSCapture(meshCM)).To(BeTrue())
+		Expect(meshDNSCapture(&corev1.ConfigMap{})).To(BeFalse())
+	})
+
+	It("creates zero notes for a pod matching the mesh default", func() {
+		pods := injectedPod("proxyMetadata:\n  ISTIO_META_DNS_CAPTURE: \"true\"\n")
+		Expect(createDNSCaptureNotes(meshDNSCapture(meshCM), pods)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a pod without an override", func() {
+		pods := injectedPod("concurrency: 2\n")
+		Expect(createDNSCaptureNotes(meshDNSCapture(meshCM), pods)).To(HaveLen(0))
+		Expect(createDNSCaptureNotes(meshDNSCapture(meshCM), injectedPod(""))).To(HaveLen(0))
+	})
+
+	It("creates a note for a pod disabling DNS capture", func() {
+		expNote := &apiv1.Note{
+			Type:    dnsCaptureNoteType,
+			Summary: dnsCaptureNoteSummary,
+			Msg:     dnsCaptureNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"pod_name":     "reviews-1",
+				"namespace":    "default",
+				"annotation":   proxyConfigAnnotation,
+				"metadata_key": dnsCaptureMetadataKey,
+				"pod_value":    "false",
+				"mesh_value":   "true",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		pods := injectedPod("proxyMetadata:\n  ISTIO_META_DNS_CAPTURE: \"false\"\n")
+		Expect(createDNSCaptureNotes(meshDNSCapture(meshCM), pods)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
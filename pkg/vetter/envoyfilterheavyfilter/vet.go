@@ -0,0 +1,159 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package envoyfilterheavyfilter vets EnvoyFilter resources and generates
+// notes if a filter without a workload selector adds a Lua or Wasm filter.
+package envoyfilterheavyfilter
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	vetterID               = "EnvoyFilterHeavyFilter"
+	heavyFilterNoteType    = "envoyfilter-heavy-filter"
+	heavyFilterNoteSummary = "Unscoped EnvoyFilter adds a ${filter_name} filter - ${envoyfilter_name}"
+	heavyFilterNoteMsg     = "The EnvoyFilter ${envoyfilter_name} in namespace ${namespace}" +
+		" adds the ${filter_name} filter without a workload selector, so it is" +
+		" applied to every proxy in ${scope}. The filter runs on every request" +
+		" and can increase latency. Consider adding a workloadSelector to scope" +
+		" it to the workloads which need it."
+)
+
+// heavyFilterNames maps the names of the compute-heavy Envoy filters to
+// their kind.
+var heavyFilterNames = map[string]string{
+	"envoy.lua":                  "Lua",
+	"envoy.filters.http.lua":     "Lua",
+	"envoy.wasm":                 "Wasm",
+	"envoy.filters.http.wasm":    "Wasm",
+	"envoy.filters.network.wasm": "Wasm",
+}
+
+// EnvoyFilterHeavyFilter implements Vetter interface
+type EnvoyFilterHeavyFilter struct {
+	efLister netv1alpha3.EnvoyFilterLister
+}
+
+// heavyFilter returns the kind of the compute-heavy filter added by the
+// patch, or "" if it adds none.
+func heavyFilter(p *istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch) string {
+	switch p.GetApplyTo() {
+	case istiov1alpha3.EnvoyFilter_HTTP_FILTER, istiov1alpha3.EnvoyFilter_NETWORK_FILTER:
+	default:
+		return ""
+	}
+	if p.GetPatch().GetOperation() == istiov1alpha3.EnvoyFilter_Patch_REMOVE {
+		return ""
+	}
+	v := p.GetPatch().GetValue()
+	if v == nil {
+		return ""
+	}
+	name, ok := v.GetFields()["name"]
+	if !ok {
+		return ""
+	}
+	return heavyFilterNames[strings.ToLower(name.GetStringValue())]
+}
+
+// heavyFilterKinds returns the kinds of the compute-heavy filters added by the
+// EnvoyFilter, in the order of its configPatches followed by its deprecated
+// filters.
+func heavyFilterKinds(ef *v1alpha3.EnvoyFilter) []string {
+	kinds := []string{}
+	for _, p := range ef.Spec.GetConfigPatches() {
+		if kind := heavyFilter(p); kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	for _, f := range ef.Spec.GetFilters() {
+		if kind := heavyFilterNames[strings.ToLower(f.GetFilterName())]; kind != "" {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// createHeavyFilterNotes generates a note for every EnvoyFilter without a
+// workload selector which adds a Lua or Wasm filter, with either configPatches
+// or the deprecated filters. Selector-less
+// EnvoyFilters in the Istio namespace apply to the whole mesh, those in
+// other namespaces to every workload of their namespace.
+func createHeavyFilterNotes(efList []*v1alpha3.EnvoyFilter) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, ef := range efList {
+		if ef.Spec.GetWorkloadSelector() != nil || len(ef.Spec.GetWorkloadLabels()) > 0 {
+			continue
+		}
+		scope := "namespace " + ef.Namespace
+		if ef.Namespace == util.IstioNamespace {
+			scope = "the mesh"
+		}
+		kinds := heavyFilterKinds(ef)
+		if len(kinds) == 0 {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    heavyFilterNoteType,
+			Summary: heavyFilterNoteSummary,
+			Msg:     heavyFilterNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"envoyfilter_name": ef.Name,
+				"namespace":        ef.Namespace,
+				"filter_name":      kinds[0],
+				"scope":            scope,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (e *EnvoyFilterHeavyFilter) Vet() ([]*apiv1.Note, error) {
+	efList, err := e.efLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve EnvoyFilters: %s", err)
+		return nil, err
+	}
+	return createHeavyFilterNotes(efList), nil
+}
+
+// Info returns information about the vetter
+func (e *EnvoyFilterHeavyFilter) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "EnvoyFilterHeavyFilter" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *EnvoyFilterHeavyFilter {
+	return &EnvoyFilterHeavyFilter{
+		efLister: factory.Istio().Networking().V1alpha3().EnvoyFilters().Lister(),
+	}
+}
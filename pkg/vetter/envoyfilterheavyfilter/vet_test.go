@@ -0,0 +1,105 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package envoyfilterheavyfilter
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func envoyFilter(selector *istiov1alpha3.WorkloadSelector,
+	patch *istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch) []*v1alpha3.EnvoyFilter {
+	return []*v1alpha3.EnvoyFilter{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "filter", Namespace: "istio-system"},
+			Spec: v1alpha3.EnvoyFilterSpec{
+				EnvoyFilter: istiov1alpha3.EnvoyFilter{
+					WorkloadSelector: selector,
+					ConfigPatches:    []*istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{patch},
+				},
+			},
+		},
+	}
+}
+
+func namedPatch(applyTo istiov1alpha3.EnvoyFilter_ApplyTo,
+	name string) *istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch {
+	return &istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch{
+		ApplyTo: applyTo,
+		Patch: &istiov1alpha3.EnvoyFilter_Patch{
+			Operation: istiov1alpha3.EnvoyFilter_Patch_INSERT_BEFORE,
+			Value: &types.Struct{
+				Fields: map[string]*types.Value{
+					"name": {Kind: &types.Value_StringValue{StringValue: name}},
+				},
+			},
+		},
+	}
+}
+
+func routePatch() *istiov1alpha3.EnvoyFilter_EnvoyConfigObjectPatch {
+	return namedPatch(istiov1alpha3.EnvoyFilter_ROUTE_CONFIGURATION, "")
+}
+
+var _ = Describe("EnvoyFilters adding heavy filters", func() {
+	luaPatch := namedPatch(istiov1alpha3.EnvoyFilter_HTTP_FILTER, "envoy.lua")
+
+	It("creates zero notes for a scoped Lua filter", func() {
+		selector := &istiov1alpha3.WorkloadSelector{Labels: map[string]string{"app": "reviews"}}
+		Expect(createHeavyFilterNotes(envoyFilter(selector, luaPatch))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a mesh-wide routing patch", func() {
+		Expect(createHeavyFilterNotes(envoyFilter(nil, routePatch()))).To(HaveLen(0))
+	})
+
+	It("creates a note for a deprecated mesh-wide Wasm filter", func() {
+		efList := envoyFilter(nil, routePatch())
+		efList[0].Spec.Filters = []*istiov1alpha3.EnvoyFilter_Filter{
+			{
+				FilterType: istiov1alpha3.EnvoyFilter_Filter_HTTP,
+				FilterName: "envoy.wasm",
+			},
+		}
+		notes := createHeavyFilterNotes(efList)
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["filter_name"]).To(Equal("Wasm"))
+	})
+
+	It("creates a note for a mesh-wide Lua filter", func() {
+		expNote := &apiv1.Note{
+			Type:    heavyFilterNoteType,
+			Summary: heavyFilterNoteSummary,
+			Msg:     heavyFilterNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"envoyfilter_name": "filter",
+				"namespace":        "istio-system",
+				"filter_name":      "Lua",
+				"scope":            "the mesh",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createHeavyFilterNotes(envoyFilter(nil, luaPatch))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
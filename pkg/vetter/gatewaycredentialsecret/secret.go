@@ -0,0 +1,71 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycredentialsecret
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// stripSecretData removes the data of a Secret, leaving its metadata and
+// type. Other objects are returned unchanged.
+func stripSecretData(obj runtime.Object) runtime.Object {
+	s, ok := obj.(*corev1.Secret)
+	if !ok {
+		return obj
+	}
+	return &corev1.Secret{TypeMeta: s.TypeMeta, ObjectMeta: s.ObjectMeta, Type: s.Type}
+}
+
+// newSecretMetadataInformer returns an informer for the Secrets of all
+// namespaces which only keeps their metadata. The vetter only needs the names
+// and namespaces of the Secrets, so the key material is never cached.
+func newSecretMetadataInformer(client kubernetes.Interface, resync time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				list, err := client.CoreV1().Secrets(metav1.NamespaceAll).List(options)
+				if err != nil {
+					return nil, err
+				}
+				for i := range list.Items {
+					list.Items[i] = *stripSecretData(&list.Items[i]).(*corev1.Secret)
+				}
+				return list, nil
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				w, err := client.CoreV1().Secrets(metav1.NamespaceAll).Watch(options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+					e.Object = stripSecretData(e.Object)
+					return e, true
+				}), nil
+			},
+		},
+		&corev1.Secret{},
+		resync,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+}
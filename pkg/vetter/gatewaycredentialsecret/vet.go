@@ -0,0 +1,176 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewaycredentialsecret vets the credentialName of Gateway servers
+// and generates notes if the secret is not in the namespace of the gateway
+// workload.
+package gatewaycredentialsecret
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID              = "GatewayCredentialSecret"
+	credentialNoteType    = "gateway-credential-secret"
+	wrongNamespaceSummary = "Gateway secret in wrong namespace - ${gateway_name}"
+	wrongNamespaceMsg     = "The Gateway ${gateway_name} in namespace ${namespace}" +
+		" uses the credentialName ${secret_name} but the secret is only present" +
+		" in namespace(s) ${secret_namespaces} while the gateway workload runs in" +
+		" namespace ${gateway_namespace}. The gateway can't read the secret and TLS" +
+		" will fail. Consider creating the secret in namespace ${gateway_namespace}."
+	missingSecretSummary = "Gateway secret not found - ${gateway_name}"
+	missingSecretMsg     = "The Gateway ${gateway_name} in namespace ${namespace}" +
+		" uses the credentialName ${secret_name} but no such secret exists. TLS" +
+		" will fail. Consider creating the secret in namespace ${gateway_namespace}."
+)
+
+// GatewayCredentialSecret implements Vetter interface
+type GatewayCredentialSecret struct {
+	podLister    v1.PodLister
+	secretLister v1.SecretLister
+	gwLister     netv1alpha3.GatewayLister
+}
+
+// gatewayNamespaces returns the sorted namespaces of the pods selected by the
+// gateway.
+func gatewayNamespaces(gw *v1alpha3.Gateway, pods []*corev1.Pod) []string {
+	namespaces := []string{}
+	if len(gw.Spec.GetSelector()) == 0 {
+		return namespaces
+	}
+	selector := labels.SelectorFromSet(gw.Spec.GetSelector())
+	seen := map[string]bool{}
+	for _, p := range pods {
+		if !seen[p.Namespace] && selector.Matches(labels.Set(p.Labels)) {
+			seen[p.Namespace] = true
+			namespaces = append(namespaces, p.Namespace)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// createCredentialNotes generates notes for the Gateway servers whose
+// credentialName secret doesn't exist in the namespace of the gateway
+// workload. Gateways which select no pods are skipped.
+func createCredentialNotes(gateways []*v1alpha3.Gateway, pods []*corev1.Pod,
+	secrets []*corev1.Secret) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	// secret name -> namespaces
+	secretNs := map[string][]string{}
+	for _, s := range secrets {
+		secretNs[s.Name] = append(secretNs[s.Name], s.Namespace)
+	}
+	for _, gw := range gateways {
+		gwNamespaces := gatewayNamespaces(gw, pods)
+		seen := map[string]bool{}
+		for _, server := range gw.Spec.GetServers() {
+			name := server.GetTls().GetCredentialName()
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			for _, gwNs := range gwNamespaces {
+				found := false
+				for _, ns := range secretNs[name] {
+					if ns == gwNs {
+						found = true
+						break
+					}
+				}
+				if found {
+					continue
+				}
+				note := &apiv1.Note{
+					Type:    credentialNoteType,
+					Summary: missingSecretSummary,
+					Msg:     missingSecretMsg,
+					Level:   apiv1.NoteLevel_ERROR,
+					Attr: map[string]string{
+						"gateway_name":      gw.Name,
+						"namespace":         gw.Namespace,
+						"secret_name":       name,
+						"gateway_namespace": gwNs,
+					},
+				}
+				if others := secretNs[name]; len(others) > 0 {
+					sorted := append([]string{}, others...)
+					sort.Strings(sorted)
+					note.Summary = wrongNamespaceSummary
+					note.Msg = wrongNamespaceMsg
+					note.Attr["secret_namespaces"] = strings.Join(sorted, ", ")
+				}
+				notes = append(notes, note)
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayCredentialSecret) Vet() ([]*apiv1.Note, error) {
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	pods, err := g.podLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Pods: %s", err)
+		return nil, err
+	}
+	secrets, err := g.secretLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Secrets: %s", err)
+		return nil, err
+	}
+	return createCredentialNotes(gateways, pods, secrets), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayCredentialSecret) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayCredentialSecret" which implements Vetter Interface
+//
+// The secrets of every namespace are listed, since a credentialName secret
+// in the wrong namespace is the most common mistake. The informer only caches
+// their metadata, see newSecretMetadataInformer.
+func NewVetter(factory vetter.ResourceListGetter) *GatewayCredentialSecret {
+	secretInformer := factory.K8s().InformerFor(&corev1.Secret{}, newSecretMetadataInformer)
+	return &GatewayCredentialSecret{
+		podLister:    factory.K8s().Core().V1().Pods().Lister(),
+		secretLister: v1.NewSecretLister(secretInformer.GetIndexer()),
+		gwLister:     factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
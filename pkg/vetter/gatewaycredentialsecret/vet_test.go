@@ -0,0 +1,129 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaycredentialsecret
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func secret(name, namespace string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+var _ = Describe("Gateway credentialName secrets", func() {
+	gateways := []*v1alpha3.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookinfo-gateway", Namespace: "bookinfo"},
+			Spec: v1alpha3.GatewaySpec{
+				Gateway: istiov1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*istiov1alpha3.Server{
+						{
+							Port:  &istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+							Hosts: []string{"bookinfo.example.com"},
+							Tls: &istiov1alpha3.Server_TLSOptions{
+								Mode:           istiov1alpha3.Server_TLSOptions_SIMPLE,
+								CredentialName: "bookinfo-cert",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "istio-ingressgateway-1",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"istio": "ingressgateway"},
+			},
+		},
+	}
+
+	It("creates zero notes for a secret in the gateway namespace", func() {
+		secrets := []*corev1.Secret{secret("bookinfo-cert", "istio-system")}
+		Expect(createCredentialNotes(gateways, pods, secrets)).To(HaveLen(0))
+	})
+
+	It("creates a note for a secret in another namespace", func() {
+		secrets := []*corev1.Secret{
+			secret("bookinfo-cert", "bookinfo"),
+			secret("other-cert", "istio-system"),
+		}
+		expNote := &apiv1.Note{
+			Type:    credentialNoteType,
+			Summary: wrongNamespaceSummary,
+			Msg:     wrongNamespaceMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"gateway_name":      "bookinfo-gateway",
+				"namespace":         "bookinfo",
+				"secret_name":       "bookinfo-cert",
+				"gateway_namespace": "istio-system",
+				"secret_namespaces": "bookinfo",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createCredentialNotes(gateways, pods, secrets)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for a missing secret", func() {
+		expNote := &apiv1.Note{
+			Type:    credentialNoteType,
+			Summary: missingSecretSummary,
+			Msg:     missingSecretMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"gateway_name":      "bookinfo-gateway",
+				"namespace":         "bookinfo",
+				"secret_name":       "bookinfo-cert",
+				"gateway_namespace": "istio-system",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createCredentialNotes(gateways, pods, nil)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("caches only the metadata of secrets", func() {
+		tls := secret("ingress-cert", "istio-system")
+		tls.Type = corev1.SecretTypeTLS
+		tls.Data = map[string][]byte{"tls.key": []byte("key")}
+		informer := newSecretMetadataInformer(fake.NewSimpleClientset(tls), 0)
+		stopCh := make(chan struct{})
+		defer close(stopCh)
+		go informer.Run(stopCh)
+		Expect(cache.WaitForCacheSync(stopCh, informer.HasSynced)).To(BeTrue())
+
+		s, err := v1.NewSecretLister(informer.GetIndexer()).Secrets("istio-system").Get("ingress-cert")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Data).To(BeNil())
+		Expect(s.Type).To(Equal(corev1.SecretTypeTLS))
+	})
+})
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewayemptyhosts vets the servers of Gateway resources and
+// generates notes if a server doesn't list any hosts.
+package gatewayemptyhosts
+
+import (
+	"strconv"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	vetterID              = "GatewayEmptyHosts"
+	emptyHostsNoteType    = "gateway-server-empty-hosts"
+	emptyHostsNoteSummary = "Gateway server without hosts - ${gateway_name}"
+	emptyHostsNoteMsg     = "The server on port ${port} of the Gateway" +
+		" ${gateway_name} in namespace ${namespace} doesn't list any hosts." +
+		" A server without hosts matches no traffic. Consider adding the hosts" +
+		" exposed by the server, or \"*\" to expose all hosts."
+)
+
+// GatewayEmptyHosts implements Vetter interface
+type GatewayEmptyHosts struct {
+	gwLister netv1alpha3.GatewayLister
+}
+
+// createEmptyHostsNotes generates a note for every Gateway server with an
+// empty or missing hosts list.
+func createEmptyHostsNotes(gateways []*v1alpha3.Gateway) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, gw := range gateways {
+		for _, s := range gw.Spec.GetServers() {
+			if len(s.GetHosts()) > 0 {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    emptyHostsNoteType,
+				Summary: emptyHostsNoteSummary,
+				Msg:     emptyHostsNoteMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr: map[string]string{
+					"gateway_name": gw.Name,
+					"namespace":    gw.Namespace,
+					"port":         strconv.Itoa(int(s.GetPort().GetNumber())),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayEmptyHosts) Vet() ([]*apiv1.Note, error) {
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	return createEmptyHostsNotes(gateways), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayEmptyHosts) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayEmptyHosts" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayEmptyHosts {
+	return &GatewayEmptyHosts{
+		gwLister: factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
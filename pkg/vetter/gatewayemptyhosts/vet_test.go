@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayemptyhosts
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(hosts []string) []*v1alpha3.Gateway {
+	return []*v1alpha3.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookinfo-gateway", Namespace: "default"},
+			Spec: v1alpha3.GatewaySpec{
+				Gateway: istiov1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*istiov1alpha3.Server{
+						{
+							Port:  &istiov1alpha3.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+							Hosts: hosts,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Gateway servers without hosts", func() {
+	It("creates zero notes for a server with hosts", func() {
+		Expect(createEmptyHostsNotes(gateway([]string{"bookinfo.example.com"}))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a wildcard host", func() {
+		Expect(createEmptyHostsNotes(gateway([]string{"*"}))).To(HaveLen(0))
+	})
+
+	It("creates a note for an empty hosts list", func() {
+		expNote := &apiv1.Note{
+			Type:    emptyHostsNoteType,
+			Summary: emptyHostsNoteSummary,
+			Msg:     emptyHostsNoteMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"gateway_name": "bookinfo-gateway",
+				"namespace":    "default",
+				"port":         "80",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createEmptyHostsNotes(gateway([]string{}))).To(Equal([]*apiv1.Note{expNote}))
+		Expect(createEmptyHostsNotes(gateway(nil))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,180 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewayportprotocol vets the servers of Gateway resources bound to
+// the same gateway pods and generates notes if they use the same port with
+// incompatible protocols.
+package gatewayportprotocol
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "GatewayPortProtocol"
+	portConflictNoteType    = "gateway-port-protocol-conflict"
+	portConflictNoteSummary = "Gateway port protocol conflict - port ${port}"
+	portConflictNoteMsg     = "The Gateways ${gateway_protocols} bind port ${port}" +
+		" of the same gateway pods in namespace ${pod_namespace} with different" +
+		" protocols. The gateway has a single listener per port, so only one of" +
+		" these protocols is served. Consider moving the servers to different ports."
+
+	protocolHTTP           = "HTTP"
+	protocolTLSTermination = "TLS termination"
+	protocolTLSPassthrough = "TLS passthrough"
+	protocolTCP            = "TCP"
+)
+
+// GatewayPortProtocol implements Vetter interface
+type GatewayPortProtocol struct {
+	podLister v1.PodLister
+	gwLister  netv1alpha3.GatewayLister
+}
+
+// listenerProtocol returns how the gateway listener handles the server's
+// traffic. Servers which share a port must agree on it.
+func listenerProtocol(s *istiov1alpha3.Server) string {
+	tls := s.GetTls()
+	switch strings.ToUpper(s.GetPort().GetProtocol()) {
+	case "HTTP", "HTTP2", "GRPC":
+		return protocolHTTP
+	case "HTTPS", "TLS":
+		if tls == nil && strings.ToUpper(s.GetPort().GetProtocol()) == "TLS" {
+			return protocolTLSPassthrough
+		}
+		if tls != nil && (tls.GetMode() == istiov1alpha3.Server_TLSOptions_PASSTHROUGH ||
+			tls.GetMode() == istiov1alpha3.Server_TLSOptions_AUTO_PASSTHROUGH) {
+			return protocolTLSPassthrough
+		}
+		return protocolTLSTermination
+	}
+	return protocolTCP
+}
+
+type gatewayServer struct {
+	gateway  string
+	protocol string
+}
+
+// createPortConflictNotes generates a note for every port of a gateway pod
+// which Gateways selecting the pod bind with different listener protocols.
+// Pods with the same conflict, e.g. replicas, are reported once.
+func createPortConflictNotes(gateways []*v1alpha3.Gateway, pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	reported := map[string]bool{}
+	for _, p := range pods {
+		// port -> servers bound to it
+		ports := map[uint32][]gatewayServer{}
+		for _, gw := range gateways {
+			if len(gw.Spec.GetSelector()) == 0 ||
+				!labels.SelectorFromSet(gw.Spec.GetSelector()).Matches(labels.Set(p.Labels)) {
+				continue
+			}
+			for _, s := range gw.Spec.GetServers() {
+				n := s.GetPort().GetNumber()
+				ports[n] = append(ports[n], gatewayServer{
+					gateway:  gw.Namespace + "/" + gw.Name,
+					protocol: listenerProtocol(s),
+				})
+			}
+		}
+		portList := make([]int, 0, len(ports))
+		for n := range ports {
+			portList = append(portList, int(n))
+		}
+		sort.Ints(portList)
+		for _, n := range portList {
+			servers := ports[uint32(n)]
+			protocols := map[string]bool{}
+			desc := []string{}
+			seen := map[gatewayServer]bool{}
+			for _, s := range servers {
+				protocols[s.protocol] = true
+				if !seen[s] {
+					seen[s] = true
+					desc = append(desc, s.gateway+" ("+s.protocol+")")
+				}
+			}
+			if len(protocols) < 2 {
+				continue
+			}
+			sort.Strings(desc)
+			attr := map[string]string{
+				"port":              strconv.Itoa(n),
+				"pod_namespace":     p.Namespace,
+				"gateway_protocols": strings.Join(desc, ", "),
+			}
+			key := attr["port"] + "|" + attr["pod_namespace"] + "|" + attr["gateway_protocols"]
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			notes = append(notes, &apiv1.Note{
+				Type:    portConflictNoteType,
+				Summary: portConflictNoteSummary,
+				Msg:     portConflictNoteMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    attr,
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayPortProtocol) Vet() ([]*apiv1.Note, error) {
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	pods, err := g.podLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Pods: %s", err)
+		return nil, err
+	}
+	return createPortConflictNotes(gateways, pods), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayPortProtocol) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayPortProtocol" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayPortProtocol {
+	return &GatewayPortProtocol{
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		gwLister:  factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayportprotocol
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(name, host string, mode istiov1alpha3.Server_TLSOptions_TLSmode) *v1alpha3.Gateway {
+	return &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha3.GatewaySpec{
+			Gateway: istiov1alpha3.Gateway{
+				Selector: map[string]string{"istio": "ingressgateway"},
+				Servers: []*istiov1alpha3.Server{
+					{
+						Port:  &istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+						Hosts: []string{host},
+						Tls:   &istiov1alpha3.Server_TLSOptions{Mode: mode},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Gateway port protocol conflicts", func() {
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "istio-ingressgateway-1",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"istio": "ingressgateway"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "istio-ingressgateway-2",
+				Namespace: "istio-system",
+				Labels:    map[string]string{"istio": "ingressgateway"},
+			},
+		},
+	}
+
+	It("creates zero notes for servers sharing a port by SNI", func() {
+		gateways := []*v1alpha3.Gateway{
+			gateway("bookinfo", "bookinfo.example.com", istiov1alpha3.Server_TLSOptions_SIMPLE),
+			gateway("shop", "shop.example.com", istiov1alpha3.Server_TLSOptions_MUTUAL),
+		}
+		Expect(createPortConflictNotes(gateways, pods)).To(HaveLen(0))
+	})
+
+	It("creates a note for HTTPS and TLS passthrough on the same port", func() {
+		gateways := []*v1alpha3.Gateway{
+			gateway("bookinfo", "bookinfo.example.com", istiov1alpha3.Server_TLSOptions_SIMPLE),
+			gateway("legacy", "legacy.example.com", istiov1alpha3.Server_TLSOptions_PASSTHROUGH),
+		}
+		expNote := &apiv1.Note{
+			Type:    portConflictNoteType,
+			Summary: portConflictNoteSummary,
+			Msg:     portConflictNoteMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"port":              "443",
+				"pod_namespace":     "istio-system",
+				"gateway_protocols": "default/bookinfo (TLS termination), default/legacy (TLS passthrough)",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createPortConflictNotes(gateways, pods)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewayrouteport vets the http routes of VirtualService resources
+// bound to Gateways and generates notes if a route matches a port on which
+// no bound Gateway serves the host of the VirtualService.
+package gatewayrouteport
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID           = "GatewayRoutePort"
+	portGapNoteType    = "gateway-route-port-gap"
+	portGapNoteSummary = "Route port not served by gateway - ${vs_name}"
+	portGapNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" routes requests for ${host} on port ${port}, but its gateway(s)" +
+		" ${gateway_list} only serve the host on port(s) ${gateway_ports}. The" +
+		" route never receives traffic from the gateways. Consider adding a" +
+		" server for the port to the gateway or correcting the port of the route."
+	meshGateway = "mesh"
+)
+
+// GatewayRoutePort implements Vetter interface
+type GatewayRoutePort struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+	gwLister netv1alpha3.GatewayLister
+}
+
+// serverHostMatches returns true if the host of a Gateway server, which may
+// be a wildcard or prefixed with a namespace, covers the VirtualService host.
+func serverHostMatches(serverHost, host string) bool {
+	if i := strings.Index(serverHost, "/"); i >= 0 {
+		serverHost = serverHost[i+1:]
+	}
+	serverHost = strings.ToLower(serverHost)
+	host = strings.ToLower(host)
+	switch {
+	case serverHost == "*" || serverHost == host:
+		return true
+	case strings.HasPrefix(serverHost, "*."):
+		return strings.HasSuffix(host, serverHost[1:])
+	}
+	return false
+}
+
+// gatewayKey returns the "<namespace>/<name>" of a gateway bound by a
+// VirtualService in the given namespace.
+func gatewayKey(gateway, namespace string) string {
+	if strings.Contains(gateway, "/") {
+		return gateway
+	}
+	return namespace + "/" + gateway
+}
+
+// matchGateways returns the keys of the bound Gateways a match applies to. A
+// match without gateways applies to all Gateways of the VirtualService, and
+// a match naming only the "mesh" gateway applies to none of them.
+func matchGateways(names []string, bound []string, namespace string) []string {
+	if len(names) == 0 {
+		return bound
+	}
+	keys := []string{}
+	for _, b := range bound {
+		for _, n := range names {
+			if n != meshGateway && gatewayKey(n, namespace) == b {
+				keys = append(keys, b)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// createPortGapNotes generates a note for every port matched by an http route
+// of a VirtualService on which none of the Gateways the match applies to
+// serves one of its hosts. Hosts which these Gateways don't serve on any port
+// are skipped.
+func createPortGapNotes(vsList []*v1alpha3.VirtualService,
+	gateways []*v1alpha3.Gateway) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	gwMap := map[string]*v1alpha3.Gateway{}
+	for _, gw := range gateways {
+		gwMap[gw.Namespace+"/"+gw.Name] = gw
+	}
+	for _, vs := range vsList {
+		bound := []string{}
+		for _, g := range vs.Spec.GetGateways() {
+			if g == meshGateway {
+				continue
+			}
+			key := gatewayKey(g, vs.Namespace)
+			if _, ok := gwMap[key]; ok {
+				bound = append(bound, key)
+			}
+		}
+		if len(bound) == 0 {
+			continue
+		}
+		// The keys of the bound Gateways each matched port applies to.
+		portGateways := map[int]map[string]bool{}
+		for _, r := range vs.Spec.GetHttp() {
+			for _, m := range r.GetMatch() {
+				p := int(m.GetPort())
+				if p == 0 {
+					continue
+				}
+				for _, key := range matchGateways(m.GetGateways(), bound, vs.Namespace) {
+					if portGateways[p] == nil {
+						portGateways[p] = map[string]bool{}
+					}
+					portGateways[p][key] = true
+				}
+			}
+		}
+		routePorts := []int{}
+		for p := range portGateways {
+			routePorts = append(routePorts, p)
+		}
+		sort.Ints(routePorts)
+		for _, host := range vs.Spec.GetHosts() {
+			for _, p := range routePorts {
+				gwNames := []string{}
+				served := map[int]bool{}
+				for _, key := range bound {
+					if !portGateways[p][key] {
+						continue
+					}
+					gwNames = append(gwNames, key)
+					for _, s := range gwMap[key].Spec.GetServers() {
+						for _, sh := range s.GetHosts() {
+							if serverHostMatches(sh, host) {
+								served[int(s.GetPort().GetNumber())] = true
+							}
+						}
+					}
+				}
+				if len(served) == 0 || served[p] {
+					continue
+				}
+				servedPorts := []int{}
+				for sp := range served {
+					servedPorts = append(servedPorts, sp)
+				}
+				sort.Ints(servedPorts)
+				servedList := []string{}
+				for _, sp := range servedPorts {
+					servedList = append(servedList, strconv.Itoa(sp))
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    portGapNoteType,
+					Summary: portGapNoteSummary,
+					Msg:     portGapNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"vs_name":       vs.Name,
+						"namespace":     vs.Namespace,
+						"host":          host,
+						"port":          strconv.Itoa(p),
+						"gateway_list":  strings.Join(gwNames, ", "),
+						"gateway_ports": strings.Join(servedList, ", "),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayRoutePort) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(g.nsLister, g.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	return createPortGapNotes(vsList, gateways), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayRoutePort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayRoutePort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayRoutePort {
+	return &GatewayRoutePort{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		gwLister: factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayrouteport
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(host string) []*v1alpha3.Gateway {
+	return []*v1alpha3.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "public-gateway", Namespace: "istio-system"},
+			Spec: v1alpha3.GatewaySpec{
+				Gateway: istiov1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*istiov1alpha3.Server{
+						{
+							Port:  &istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+							Hosts: []string{host},
+							Tls:   &istiov1alpha3.Server_TLSOptions{Mode: istiov1alpha3.Server_TLSOptions_SIMPLE},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func virtualService(port uint32) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts:    []string{"api.example.com"},
+					Gateways: []string{"istio-system/public-gateway"},
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Match: []*istiov1alpha3.HTTPMatchRequest{{Port: port}},
+							Route: []*istiov1alpha3.HTTPRouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "api"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Gateway route ports", func() {
+	It("creates zero notes for aligned ports", func() {
+		Expect(createPortGapNotes(virtualService(443), gateway("api.example.com"))).To(HaveLen(0))
+		Expect(createPortGapNotes(virtualService(0), gateway("api.example.com"))).To(HaveLen(0))
+	})
+
+	It("creates a note for a port gap", func() {
+		expNote := &apiv1.Note{
+			Type:    portGapNoteType,
+			Summary: portGapNoteSummary,
+			Msg:     portGapNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":       "api",
+				"namespace":     "default",
+				"host":          "api.example.com",
+				"port":          "80",
+				"gateway_list":  "istio-system/public-gateway",
+				"gateway_ports": "443",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createPortGapNotes(virtualService(80), gateway("api.example.com"))).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("skips matches which don't apply to the gateway", func() {
+		vsList := virtualService(80)
+		vsList[0].Spec.Gateways = append(vsList[0].Spec.Gateways, "mesh")
+		m := vsList[0].Spec.Http[0].Match[0]
+		m.Gateways = []string{"mesh"}
+		Expect(createPortGapNotes(vsList, gateway("api.example.com"))).To(HaveLen(0))
+		m.Gateways = []string{"istio-system/private-gateway"}
+		Expect(createPortGapNotes(vsList, gateway("api.example.com"))).To(HaveLen(0))
+		m.Gateways = []string{"mesh", "istio-system/public-gateway"}
+		Expect(createPortGapNotes(vsList, gateway("api.example.com"))).To(HaveLen(1))
+	})
+
+	It("matches wildcard gateway hosts", func() {
+		Expect(createPortGapNotes(virtualService(80), gateway("*.example.com"))).To(HaveLen(1))
+		Expect(createPortGapNotes(virtualService(80), gateway("default/*"))).To(HaveLen(1))
+		Expect(createPortGapNotes(virtualService(80), gateway("*.example.org"))).To(HaveLen(0))
+	})
+})
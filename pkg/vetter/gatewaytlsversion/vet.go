@@ -0,0 +1,134 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewaytlsversion vets the TLS settings of Gateway servers and
+// generates notes if a server allows TLS versions older than TLS 1.2, either
+// explicitly or by leaving the minimum version to the proxy default.
+package gatewaytlsversion
+
+import (
+	"strconv"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	vetterID              = "GatewayTLSVersion"
+	tlsVersionNoteType    = "gateway-tls-min-version"
+	tlsVersionNoteSummary = "Gateway allows TLS versions below 1.2 - ${gateway_name}"
+	tlsVersionNoteMsg     = "The server on port ${port} of the Gateway ${gateway_name}" +
+		" in namespace ${namespace} sets minProtocolVersion to ${version}. TLS" +
+		" versions below 1.2 have known weaknesses and are flagged by security" +
+		" scanners. Consider setting minProtocolVersion to TLSV1_2 or higher."
+	tlsDefaultNoteType    = "gateway-tls-default-min-version"
+	tlsDefaultNoteSummary = "Gateway uses the default minimum TLS version - ${gateway_name}"
+	tlsDefaultNoteMsg     = "The server on port ${port} of the Gateway ${gateway_name}" +
+		" in namespace ${namespace} doesn't set minProtocolVersion, so the proxy" +
+		" default of TLS 1.0 applies. TLS versions below 1.2 have known weaknesses" +
+		" and are flagged by security scanners. Consider setting" +
+		" minProtocolVersion to TLSV1_2 or higher."
+)
+
+// GatewayTLSVersion implements Vetter interface
+type GatewayTLSVersion struct {
+	gwLister netv1alpha3.GatewayLister
+}
+
+// terminatesTLS returns true if the server terminates TLS. Plaintext servers
+// and passthrough servers don't, so their minimum version has no effect.
+func terminatesTLS(tls *istiov1alpha3.Server_TLSOptions) bool {
+	if tls == nil {
+		return false
+	}
+	switch tls.GetMode() {
+	case istiov1alpha3.Server_TLSOptions_PASSTHROUGH, istiov1alpha3.Server_TLSOptions_AUTO_PASSTHROUGH:
+		return false
+	}
+	return true
+}
+
+// createTLSVersionNotes generates a note for every Gateway server terminating
+// TLS whose settings allow a protocol version below TLS 1.2. Servers without
+// a minimum version get an info note since the proxy default for downstream
+// connections is TLS 1.0.
+func createTLSVersionNotes(gateways []*v1alpha3.Gateway) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, gw := range gateways {
+		for _, s := range gw.Spec.GetServers() {
+			if !terminatesTLS(s.GetTls()) {
+				continue
+			}
+			attr := map[string]string{
+				"gateway_name": gw.Name,
+				"namespace":    gw.Namespace,
+				"port":         strconv.Itoa(int(s.GetPort().GetNumber())),
+			}
+			switch s.GetTls().GetMinProtocolVersion() {
+			case istiov1alpha3.Server_TLSOptions_TLS_AUTO:
+				notes = append(notes, &apiv1.Note{
+					Type:    tlsDefaultNoteType,
+					Summary: tlsDefaultNoteSummary,
+					Msg:     tlsDefaultNoteMsg,
+					Level:   apiv1.NoteLevel_INFO,
+					Attr:    attr,
+				})
+			case istiov1alpha3.Server_TLSOptions_TLSV1_0, istiov1alpha3.Server_TLSOptions_TLSV1_1:
+				attr["version"] = s.GetTls().GetMinProtocolVersion().String()
+				notes = append(notes, &apiv1.Note{
+					Type:    tlsVersionNoteType,
+					Summary: tlsVersionNoteSummary,
+					Msg:     tlsVersionNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr:    attr,
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayTLSVersion) Vet() ([]*apiv1.Note, error) {
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	return createTLSVersionNotes(gateways), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayTLSVersion) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayTLSVersion" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayTLSVersion {
+	return &GatewayTLSVersion{
+		gwLister: factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
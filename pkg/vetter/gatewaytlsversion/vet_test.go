@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewaytlsversion
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(version istiov1alpha3.Server_TLSOptions_TLSProtocol) []*v1alpha3.Gateway {
+	return []*v1alpha3.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookinfo-gateway", Namespace: "default"},
+			Spec: v1alpha3.GatewaySpec{
+				Gateway: istiov1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*istiov1alpha3.Server{
+						{
+							Port:  &istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+							Hosts: []string{"bookinfo.example.com"},
+							Tls: &istiov1alpha3.Server_TLSOptions{
+								Mode:               istiov1alpha3.Server_TLSOptions_SIMPLE,
+								CredentialName:     "bookinfo-cert",
+								MinProtocolVersion: version,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Gateway TLS minimum protocol version", func() {
+	It("creates zero notes for TLSV1_2", func() {
+		Expect(createTLSVersionNotes(gateway(istiov1alpha3.Server_TLSOptions_TLSV1_2))).To(HaveLen(0))
+	})
+
+	It("creates an info note for an unset version", func() {
+		expNote := &apiv1.Note{
+			Type:    tlsDefaultNoteType,
+			Summary: tlsDefaultNoteSummary,
+			Msg:     tlsDefaultNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"gateway_name": "bookinfo-gateway",
+				"namespace":    "default",
+				"port":         "443",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createTLSVersionNotes(gateway(istiov1alpha3.Server_TLSOptions_TLS_AUTO))).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates zero notes for a server without TLS settings", func() {
+		gateways := gateway(istiov1alpha3.Server_TLSOptions_TLS_AUTO)
+		gateways[0].Spec.Servers[0].Tls = nil
+		Expect(createTLSVersionNotes(gateways)).To(HaveLen(0))
+	})
+
+	It("creates a note for TLSV1_0", func() {
+		expNote := &apiv1.Note{
+			Type:    tlsVersionNoteType,
+			Summary: tlsVersionNoteSummary,
+			Msg:     tlsVersionNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"gateway_name": "bookinfo-gateway",
+				"namespace":    "default",
+				"port":         "443",
+				"version":      "TLSV1_0",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createTLSVersionNotes(gateway(istiov1alpha3.Server_TLSOptions_TLSV1_0))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
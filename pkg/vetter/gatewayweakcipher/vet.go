@@ -0,0 +1,129 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gatewayweakcipher vets the TLS settings of Gateway servers and
+// generates notes if a server lists weak cipher suites.
+package gatewayweakcipher
+
+import (
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	vetterID              = "GatewayWeakCipher"
+	weakCipherNoteType    = "gateway-weak-cipher"
+	weakCipherNoteSummary = "Gateway allows weak cipher ${cipher} - ${gateway_name}"
+	weakCipherNoteMsg     = "The server on port ${port} of the Gateway ${gateway_name}" +
+		" in namespace ${namespace} lists the weak cipher suite ${cipher} in" +
+		" cipherSuites. Clients can negotiate connections which are vulnerable" +
+		" to known attacks. Consider removing the cipher suite."
+)
+
+// WeakCipherSuites are the cipher suites, in the OpenSSL naming used by
+// Envoy, which are considered weak: CBC-mode, RC4 and 3DES ciphers.
+var WeakCipherSuites = []string{
+	"ECDHE-ECDSA-AES128-SHA",
+	"ECDHE-RSA-AES128-SHA",
+	"ECDHE-ECDSA-AES256-SHA",
+	"ECDHE-RSA-AES256-SHA",
+	"AES128-SHA",
+	"AES256-SHA",
+	"DES-CBC3-SHA",
+	"ECDHE-RSA-DES-CBC3-SHA",
+	"RC4-SHA",
+	"RC4-MD5",
+	"ECDHE-RSA-RC4-SHA",
+	"ECDHE-ECDSA-RC4-SHA",
+}
+
+// GatewayWeakCipher implements Vetter interface
+type GatewayWeakCipher struct {
+	gwLister netv1alpha3.GatewayLister
+}
+
+// WeakCipherSuite returns true if the cipher suite is one of
+// WeakCipherSuites, ignoring case.
+func WeakCipherSuite(cipher string) bool {
+	for _, weak := range WeakCipherSuites {
+		if strings.EqualFold(cipher, weak) {
+			return true
+		}
+	}
+	return false
+}
+
+// createWeakCipherNotes generates a note for every weak cipher suite listed
+// by a Gateway server.
+func createWeakCipherNotes(gateways []*v1alpha3.Gateway) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, gw := range gateways {
+		for _, s := range gw.Spec.GetServers() {
+			for _, cipher := range s.GetTls().GetCipherSuites() {
+				if !WeakCipherSuite(cipher) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    weakCipherNoteType,
+					Summary: weakCipherNoteSummary,
+					Msg:     weakCipherNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"gateway_name": gw.Name,
+						"namespace":    gw.Namespace,
+						"port":         strconv.Itoa(int(s.GetPort().GetNumber())),
+						"cipher":       cipher,
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayWeakCipher) Vet() ([]*apiv1.Note, error) {
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	return createWeakCipherNotes(gateways), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayWeakCipher) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayWeakCipher" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayWeakCipher {
+	return &GatewayWeakCipher{
+		gwLister: factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gatewayweakcipher
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(ciphers []string) []*v1alpha3.Gateway {
+	return []*v1alpha3.Gateway{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookinfo-gateway", Namespace: "default"},
+			Spec: v1alpha3.GatewaySpec{
+				Gateway: istiov1alpha3.Gateway{
+					Selector: map[string]string{"istio": "ingressgateway"},
+					Servers: []*istiov1alpha3.Server{
+						{
+							Port:  &istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+							Hosts: []string{"bookinfo.example.com"},
+							Tls: &istiov1alpha3.Server_TLSOptions{
+								Mode:           istiov1alpha3.Server_TLSOptions_SIMPLE,
+								CredentialName: "bookinfo-cert",
+								CipherSuites:   ciphers,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Gateway weak cipher suites", func() {
+	It("creates zero notes for modern cipher suites", func() {
+		ciphers := []string{"ECDHE-ECDSA-AES128-GCM-SHA256", "ECDHE-RSA-CHACHA20-POLY1305"}
+		Expect(createWeakCipherNotes(gateway(ciphers))).To(HaveLen(0))
+	})
+
+	It("creates zero notes without explicit cipher suites", func() {
+		Expect(createWeakCipherNotes(gateway(nil))).To(HaveLen(0))
+	})
+
+	It("creates a note for a weak cipher suite", func() {
+		expNote := &apiv1.Note{
+			Type:    weakCipherNoteType,
+			Summary: weakCipherNoteSummary,
+			Msg:     weakCipherNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"gateway_name": "bookinfo-gateway",
+				"namespace":    "default",
+				"port":         "443",
+				"cipher":       "ECDHE-RSA-AES128-SHA",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		ciphers := []string{"ECDHE-RSA-AES128-GCM-SHA256", "ECDHE-RSA-AES128-SHA"}
+		Expect(createWeakCipherNotes(gateway(ciphers))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
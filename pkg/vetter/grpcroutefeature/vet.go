@@ -0,0 +1,175 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcroutefeature vets the http routes of VirtualService resources
+// and generates notes if they use gRPC specific features for destinations
+// whose service port isn't gRPC.
+package grpcroutefeature
+
+import (
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "GrpcRouteFeature"
+	grpcFeatureNoteType    = "grpc-feature-non-grpc-port"
+	grpcFeatureNoteSummary = "gRPC route feature on non-gRPC port - ${vs_name}"
+	grpcFeatureNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" retries on the gRPC status(es) ${retry_on} for the destination" +
+		" ${destination_host}, but the port ${port_name} of the service is" +
+		" ${protocol}. The retries only apply to gRPC traffic. Consider naming" +
+		" the service port with the grpc prefix if it serves gRPC."
+	protocolGRPC = "GRPC"
+)
+
+// grpcRetryOn are the retryOn conditions which only apply to gRPC responses.
+var grpcRetryOn = map[string]bool{
+	"cancelled":          true,
+	"deadline-exceeded":  true,
+	"internal":           true,
+	"resource-exhausted": true,
+	"unavailable":        true,
+}
+
+// GrpcRouteFeature implements Vetter interface
+type GrpcRouteFeature struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	vsLister  netv1alpha3.VirtualServiceLister
+}
+
+// grpcRetryConditions returns the gRPC specific conditions of the retry
+// policy of the route.
+func grpcRetryConditions(r *istiov1alpha3.HTTPRoute) []string {
+	conditions := []string{}
+	for _, c := range strings.Split(r.GetRetries().GetRetryOn(), ",") {
+		c = strings.TrimSpace(c)
+		if grpcRetryOn[c] {
+			conditions = append(conditions, c)
+		}
+	}
+	return conditions
+}
+
+// destinationPort returns the port of the service the destination routes to.
+// If the destination doesn't specify a port, the service must have exactly
+// one port.
+func destinationPort(d *istiov1alpha3.Destination, s *corev1.Service) *corev1.ServicePort {
+	if n := d.GetPort().GetNumber(); n != 0 {
+		for i := range s.Spec.Ports {
+			if uint32(s.Spec.Ports[i].Port) == n {
+				return &s.Spec.Ports[i]
+			}
+		}
+		return nil
+	}
+	if len(s.Spec.Ports) == 1 {
+		return &s.Spec.Ports[0]
+	}
+	return nil
+}
+
+// createGrpcFeatureNotes generates a note for every destination of an http
+// route with gRPC specific retry conditions whose service port isn't gRPC.
+func createGrpcFeatureNotes(svcs []*corev1.Service,
+	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, vs := range vsList {
+		for _, r := range vs.Spec.GetHttp() {
+			conditions := grpcRetryConditions(r)
+			if len(conditions) == 0 {
+				continue
+			}
+			for _, dw := range r.GetRoute() {
+				d := dw.GetDestination()
+				s := resolver.Resolve(d.GetHost(), vs.Namespace)
+				if s == nil {
+					continue
+				}
+				sp := destinationPort(d, s)
+				if sp == nil {
+					continue
+				}
+				protocol := util.ServicePortProtocol(*sp)
+				if protocol == protocolGRPC {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    grpcFeatureNoteType,
+					Summary: grpcFeatureNoteSummary,
+					Msg:     grpcFeatureNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"vs_name":          vs.Name,
+						"namespace":        vs.Namespace,
+						"retry_on":         strings.Join(conditions, ", "),
+						"destination_host": d.GetHost(),
+						"port_name":        sp.Name,
+						"port":             strconv.Itoa(int(sp.Port)),
+						"protocol":         protocol,
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GrpcRouteFeature) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(g.nsLister, g.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			grpcFeatureNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(g.nsLister, g.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createGrpcFeatureNotes(svcs, vsList), nil
+}
+
+// Info returns information about the vetter
+func (g *GrpcRouteFeature) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GrpcRouteFeature" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GrpcRouteFeature {
+	return &GrpcRouteFeature{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcroutefeature
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(retryOn string) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: []string{"ratings"},
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Route: []*istiov1alpha3.HTTPRouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "ratings"}},
+							},
+							Retries: &istiov1alpha3.HTTPRetry{Attempts: 3, RetryOn: retryOn},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func service(portName string) []*corev1.Service {
+	return []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ratings", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: portName, Port: 9080}},
+			},
+		},
+	}
+}
+
+var _ = Describe("gRPC route features", func() {
+	It("creates zero notes for gRPC retries on a grpc port", func() {
+		vsList := virtualService("unavailable,cancelled")
+		Expect(createGrpcFeatureNotes(service("grpc-ratings"), vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes without gRPC features", func() {
+		vsList := virtualService("5xx,connect-failure")
+		Expect(createGrpcFeatureNotes(service("http-ratings"), vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for gRPC retries on an http port", func() {
+		expNote := &apiv1.Note{
+			Type:    grpcFeatureNoteType,
+			Summary: grpcFeatureNoteSummary,
+			Msg:     grpcFeatureNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":          "ratings",
+				"namespace":        "default",
+				"retry_on":         "unavailable, cancelled",
+				"destination_host": "ratings",
+				"port_name":        "http-ratings",
+				"port":             "9080",
+				"protocol":         "HTTP",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		vsList := virtualService("5xx, unavailable,cancelled")
+		Expect(createGrpcFeatureNotes(service("http-ratings"), vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,109 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package h2cserviceport vets the port names of the services in the mesh and
+// generates notes if they use the h2c protocol prefix which the Istio control
+// plane doesn't recognize.
+package h2cserviceport
+
+import (
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID        = "H2CServicePort"
+	h2cPortNoteType = "h2c-service-port"
+	h2cPortSummary  = "Unrecognized h2c port prefix in service - ${service_name}"
+	h2cPortMsg      = "The port ${port_name} of service ${service_name} in namespace" +
+		" ${namespace} uses the h2c protocol prefix, which Istio doesn't" +
+		" recognize. Traffic on the port is treated as plain TCP and loses HTTP/2" +
+		" routing and telemetry. Consider renaming the port with the http2" +
+		" prefix, which Istio serves as cleartext HTTP/2."
+	h2cPrefix = "h2c"
+)
+
+// H2CServicePort implements Vetter interface
+type H2CServicePort struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+}
+
+// h2cPort returns true if the port name uses the h2c protocol prefix.
+func h2cPort(name string) bool {
+	name = strings.ToLower(name)
+	return name == h2cPrefix || strings.HasPrefix(name, h2cPrefix+"-")
+}
+
+// createH2CPortNotes generates a note for every service port using the h2c
+// prefix.
+func createH2CPortNotes(services []*corev1.Service) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		for _, p := range s.Spec.Ports {
+			if p.Protocol == util.ServiceProtocolUDP || !h2cPort(p.Name) {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    h2cPortNoteType,
+				Summary: h2cPortSummary,
+				Msg:     h2cPortMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"service_name": s.Name,
+					"namespace":    s.Namespace,
+					"port_name":    p.Name,
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (h *H2CServicePort) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(h.nsLister, h.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			h2cPortNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createH2CPortNotes(services), nil
+}
+
+// Info returns information about the vetter
+func (h *H2CServicePort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "H2CServicePort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *H2CServicePort {
+	return &H2CServicePort{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package h2cserviceport
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func service(portNames ...string) []*corev1.Service {
+	s := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "greeter", Namespace: "default"}}
+	for _, n := range portNames {
+		s.Spec.Ports = append(s.Spec.Ports, corev1.ServicePort{Name: n, Protocol: corev1.ProtocolTCP})
+	}
+	return []*corev1.Service{s}
+}
+
+var _ = Describe("Service ports with the h2c prefix", func() {
+	It("creates zero notes on empty lists", func() {
+		Expect(createH2CPortNotes(nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for http2 ports", func() {
+		Expect(createH2CPortNotes(service("http2", "http2-web"))).To(HaveLen(0))
+	})
+
+	It("creates a note for h2c ports", func() {
+		expNote := &apiv1.Note{
+			Type:    h2cPortNoteType,
+			Summary: h2cPortSummary,
+			Msg:     h2cPortMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name": "greeter",
+				"namespace":    "default",
+				"port_name":    "h2c-web",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createH2CPortNotes(service("http2", "h2c-web", "h2cache"))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,133 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package holdapplicationproxystart vets the pods in the mesh which ask the
+// application to wait for the sidecar proxy to start and generates notes if
+// the pod spec doesn't implement it.
+package holdapplicationproxystart
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "HoldApplicationProxyStart"
+	holdApplicationNoteType = "hold-application-not-applied"
+	holdApplicationSummary  = "Application not held until proxy starts - ${pod_name}"
+	holdApplicationMsg      = "The pod ${pod_name} in namespace ${namespace} enables" +
+		" holdApplicationUntilProxyStarts in the ${annotation} annotation but ${reason}." +
+		" The application containers may start before the sidecar is ready and" +
+		" fail their first requests. Consider recreating the pod with a sidecar" +
+		" injector which supports the setting."
+	proxyConfigAnnotation    = "proxy.istio.io/config"
+	holdApplicationConfigKey = "holdApplicationUntilProxyStarts"
+	reasonProxyNotFirst      = "the istio-proxy container is not the first container"
+	reasonMissingPostStart   = "the istio-proxy container has no postStart hook waiting for the proxy"
+)
+
+// HoldApplication implements Vetter interface
+type HoldApplication struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+}
+
+// holdApplicationEnabled returns true if the proxy config annotation of the
+// pod enables holdApplicationUntilProxyStarts.
+func holdApplicationEnabled(p *corev1.Pod) bool {
+	cfg, ok := p.Annotations[proxyConfigAnnotation]
+	if !ok {
+		return false
+	}
+	proxyConfig := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(cfg), &proxyConfig); err != nil {
+		glog.V(2).Infof("Unable to parse %s annotation of pod %s/%s: %s",
+			proxyConfigAnnotation, p.Namespace, p.Name, err)
+		return false
+	}
+	enabled, _ := proxyConfig[holdApplicationConfigKey].(bool)
+	return enabled
+}
+
+// createHoldApplicationNotes generates notes for the injected pods which
+// enable holdApplicationUntilProxyStarts but whose istio-proxy container is
+// not started first and blocking on a postStart hook.
+func createHoldApplicationNotes(pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, p := range pods {
+		if !util.SidecarInjected(p) || !holdApplicationEnabled(p) {
+			continue
+		}
+		reason := ""
+		containers := p.Spec.Containers
+		if containers[0].Name != util.IstioProxyContainerName {
+			reason = reasonProxyNotFirst
+		} else if containers[0].Lifecycle == nil || containers[0].Lifecycle.PostStart == nil {
+			reason = reasonMissingPostStart
+		}
+		if reason == "" {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    holdApplicationNoteType,
+			Summary: holdApplicationSummary,
+			Msg:     holdApplicationMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"pod_name":   p.Name,
+				"namespace":  p.Namespace,
+				"annotation": proxyConfigAnnotation,
+				"reason":     reason,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (h *HoldApplication) Vet() ([]*apiv1.Note, error) {
+	pods, err := util.ListPodsInMesh(h.nsLister, h.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			holdApplicationNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createHoldApplicationNotes(pods), nil
+}
+
+// Info returns information about the vetter
+func (h *HoldApplication) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "HoldApplication" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *HoldApplication {
+	return &HoldApplication{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package holdapplicationproxystart
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func injectedPod(proxyConfig string, containers ...corev1.Container) *corev1.Pod {
+	annotations := map[string]string{util.IstioInitializerPodAnnotation: "{}"}
+	if proxyConfig != "" {
+		annotations[proxyConfigAnnotation] = proxyConfig
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "reviews-1",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{Containers: containers},
+	}
+}
+
+var _ = Describe("Hold application until proxy starts", func() {
+	app := corev1.Container{Name: "reviews"}
+	holdingProxy := corev1.Container{
+		Name: util.IstioProxyContainerName,
+		Lifecycle: &corev1.Lifecycle{
+			PostStart: &corev1.Handler{
+				Exec: &corev1.ExecAction{Command: []string{"pilot-agent", "wait"}},
+			},
+		},
+	}
+	plainProxy := corev1.Container{Name: util.IstioProxyContainerName}
+
+	It("creates zero notes if the feature is off", func() {
+		pods := []*corev1.Pod{
+			injectedPod("", app, plainProxy),
+			injectedPod("holdApplicationUntilProxyStarts: false", app, plainProxy),
+		}
+		Expect(createHoldApplicationNotes(pods)).To(HaveLen(0))
+	})
+
+	It("creates zero notes if the feature is on and applied", func() {
+		pods := []*corev1.Pod{
+			injectedPod("holdApplicationUntilProxyStarts: true", holdingProxy, app),
+		}
+		Expect(createHoldApplicationNotes(pods)).To(HaveLen(0))
+	})
+
+	It("creates a note if the feature is on but not applied", func() {
+		pods := []*corev1.Pod{
+			injectedPod("holdApplicationUntilProxyStarts: true", app, holdingProxy),
+		}
+		expNote := &apiv1.Note{
+			Type:    holdApplicationNoteType,
+			Summary: holdApplicationSummary,
+			Msg:     holdApplicationMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"pod_name":   "reviews-1",
+				"namespace":  "default",
+				"annotation": proxyConfigAnnotation,
+				"reason":     reasonProxyNotFirst,
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createHoldApplicationNotes(pods)).To(Equal([]*apiv1.Note{expNote}))
+
+		pods = []*corev1.Pod{
+			injectedPod("holdApplicationUntilProxyStarts: true", plainProxy, app),
+		}
+		notes := createHoldApplicationNotes(pods)
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["reason"]).To(Equal(reasonMissingPostStart))
+	})
+})
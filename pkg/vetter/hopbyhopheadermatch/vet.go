@@ -0,0 +1,147 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hopbyhopheadermatch vets the http routes of VirtualService
+// resources and generates notes if a route matches on a hop-by-hop header.
+package hopbyhopheadermatch
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "HopByHopHeaderMatch"
+	hopByHopMatchNoteType    = "hop-by-hop-header-match"
+	hopByHopMatchNoteSummary = "Match on hop-by-hop header - ${vs_name}"
+	hopByHopMatchNoteMsg     = "The http route ${route} of VirtualService ${vs_name} in" +
+		" namespace ${namespace} matches on the hop-by-hop header(s) ${header_list}." +
+		" Proxies strip or rewrite these headers on every hop, so the match" +
+		" doesn't reliably reflect the request sent by the client. Consider" +
+		" matching on an end-to-end header instead."
+)
+
+// HopByHopHeaders are the lower-cased names of the hop-by-hop headers of
+// RFC 2616 section 13.5.1, plus the non-standard Proxy-Connection header.
+var HopByHopHeaders = []string{
+	"connection",
+	"keep-alive",
+	"proxy-authenticate",
+	"proxy-authorization",
+	"proxy-connection",
+	"te",
+	"trailer",
+	"transfer-encoding",
+	"upgrade",
+}
+
+// HopByHopHeaderMatch implements Vetter interface
+type HopByHopHeaderMatch struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// hopByHop returns true if the header is a hop-by-hop header.
+func hopByHop(header string) bool {
+	for _, h := range HopByHopHeaders {
+		if strings.EqualFold(h, header) {
+			return true
+		}
+	}
+	return false
+}
+
+// hopByHopMatches returns the sorted hop-by-hop headers the route matches on.
+func hopByHopMatches(r *istiov1alpha3.HTTPRoute) []string {
+	seen := map[string]bool{}
+	headers := []string{}
+	for _, m := range r.GetMatch() {
+		for h := range m.GetHeaders() {
+			if hopByHop(h) && !seen[h] {
+				seen[h] = true
+				headers = append(headers, h)
+			}
+		}
+	}
+	sort.Strings(headers)
+	return headers
+}
+
+// createHopByHopMatchNotes generates a note for every http route which
+// matches on hop-by-hop headers.
+func createHopByHopMatchNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for i, r := range vs.Spec.GetHttp() {
+			headers := hopByHopMatches(r)
+			if len(headers) == 0 {
+				continue
+			}
+			route := r.GetName()
+			if route == "" {
+				route = strconv.Itoa(i)
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    hopByHopMatchNoteType,
+				Summary: hopByHopMatchNoteSummary,
+				Msg:     hopByHopMatchNoteMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"vs_name":     vs.Name,
+					"namespace":   vs.Namespace,
+					"route":       route,
+					"header_list": strings.Join(headers, ", "),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (h *HopByHopHeaderMatch) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(h.nsLister, h.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createHopByHopMatchNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (h *HopByHopHeaderMatch) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "HopByHopHeaderMatch" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *HopByHopHeaderMatch {
+	return &HopByHopHeaderMatch{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hopbyhopheadermatch
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(headers ...string) []*v1alpha3.VirtualService {
+	match := map[string]*istiov1alpha3.StringMatch{}
+	for _, h := range headers {
+		match[h] = &istiov1alpha3.StringMatch{
+			MatchType: &istiov1alpha3.StringMatch_Exact{Exact: "value"},
+		}
+	}
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: []string{"reviews"},
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Match: []*istiov1alpha3.HTTPMatchRequest{{Headers: match}},
+							Route: []*istiov1alpha3.HTTPRouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "reviews"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Hop-by-hop header match", func() {
+	It("creates zero notes for end-to-end header matches", func() {
+		notes := createHopByHopMatchNotes(virtualService("end-user", "x-request-id"))
+		Expect(notes).To(HaveLen(0))
+	})
+
+	It("creates a note for hop-by-hop header matches", func() {
+		notes := createHopByHopMatchNotes(virtualService("end-user", "Connection", "transfer-encoding"))
+		expNote := &apiv1.Note{
+			Type:    hopByHopMatchNoteType,
+			Summary: hopByHopMatchNoteSummary,
+			Msg:     hopByHopMatchNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":     "reviews",
+				"namespace":   "default",
+				"route":       "0",
+				"header_list": "Connection, transfer-encoding",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates zero notes for pseudo-header matches", func() {
+		notes := createHopByHopMatchNotes(virtualService(":authority", ":path"))
+		Expect(notes).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostcasemismatch vets the route destination hosts of VirtualService
+// resources and generates notes if they only differ in case from the host of
+// a DestinationRule.
+package hostcasemismatch
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID            = "HostCaseMismatch"
+	hostCaseNoteType    = "host-case-mismatch"
+	hostCaseNoteSummary = "Host casing mismatch - ${vs_name} and ${dr_name}"
+	hostCaseNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" routes to host ${vs_host} while the DestinationRule ${dr_name} in" +
+		" namespace ${dr_namespace} is defined for host ${dr_host}. Hosts are" +
+		" matched case-sensitively, so the DestinationRule and its subsets don't" +
+		" apply to these routes. Consider using the same casing in both resources."
+)
+
+// HostCaseMismatch implements Vetter interface
+type HostCaseMismatch struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+	drLister netv1alpha3.DestinationRuleLister
+}
+
+// destinationHosts returns the unique route destination hosts of the
+// VirtualService.
+func destinationHosts(vs *v1alpha3.VirtualService) []string {
+	seen := map[string]bool{}
+	hosts := []string{}
+	add := func(d *istiov1alpha3.Destination) {
+		if h := d.GetHost(); h != "" && !seen[h] {
+			seen[h] = true
+			hosts = append(hosts, h)
+		}
+	}
+	for _, r := range vs.Spec.GetHttp() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination())
+		}
+	}
+	for _, r := range vs.Spec.GetTcp() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination())
+		}
+	}
+	for _, r := range vs.Spec.GetTls() {
+		for _, dw := range r.GetRoute() {
+			add(dw.GetDestination())
+		}
+	}
+	return hosts
+}
+
+// createHostCaseNotes generates notes for the VirtualService route
+// destination hosts which differ only in case from a DestinationRule host.
+// Short names are qualified with the namespace of their resource before
+// comparing.
+func createHostCaseNotes(vsList []*v1alpha3.VirtualService,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for _, vsHost := range destinationHosts(vs) {
+			vsFqdn, err := util.ConvertHostnameToFQDN(vsHost, vs.Namespace)
+			if err != nil {
+				continue
+			}
+			for _, dr := range drList {
+				drFqdn, err := util.ConvertHostnameToFQDN(dr.Spec.GetHost(), dr.Namespace)
+				if err != nil || vsFqdn == drFqdn || !strings.EqualFold(vsFqdn, drFqdn) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    hostCaseNoteType,
+					Summary: hostCaseNoteSummary,
+					Msg:     hostCaseNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"vs_name":      vs.Name,
+						"namespace":    vs.Namespace,
+						"vs_host":      vsHost,
+						"dr_name":      dr.Name,
+						"dr_namespace": dr.Namespace,
+						"dr_host":      dr.Spec.GetHost(),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (h *HostCaseMismatch) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(h.nsLister, h.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(h.nsLister, h.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createHostCaseNotes(vsList, drList), nil
+}
+
+// Info returns information about the vetter
+func (h *HostCaseMismatch) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "HostCaseMismatch" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *HostCaseMismatch {
+	return &HostCaseMismatch{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
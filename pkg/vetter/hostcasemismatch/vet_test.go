@@ -0,0 +1,92 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostcasemismatch
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(host string) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: []string{"myservice"},
+				Http: []*istiov1alpha3.HTTPRoute{
+					{
+						Route: []*istiov1alpha3.HTTPRouteDestination{
+							{Destination: &istiov1alpha3.Destination{Host: host, Subset: "v1"}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func destinationRule(host string) *v1alpha3.DestinationRule {
+	return &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "subsets", Namespace: "default"},
+		Spec: v1alpha3.DestinationRuleSpec{
+			DestinationRule: istiov1alpha3.DestinationRule{
+				Host:    host,
+				Subsets: []*istiov1alpha3.Subset{{Name: "v1"}},
+			},
+		},
+	}
+}
+
+var _ = Describe("Host casing mismatches", func() {
+	It("creates zero notes for matching casing", func() {
+		vsList := []*v1alpha3.VirtualService{virtualService("myservice")}
+		drList := []*v1alpha3.DestinationRule{destinationRule("myservice.default.svc.cluster.local")}
+		Expect(createHostCaseNotes(vsList, drList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for entirely different hosts", func() {
+		vsList := []*v1alpha3.VirtualService{virtualService("MyService")}
+		drList := []*v1alpha3.DestinationRule{destinationRule("otherservice")}
+		Expect(createHostCaseNotes(vsList, drList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a case-only mismatch", func() {
+		vsList := []*v1alpha3.VirtualService{virtualService("MyService")}
+		drList := []*v1alpha3.DestinationRule{destinationRule("myservice")}
+		expNote := &apiv1.Note{
+			Type:    hostCaseNoteType,
+			Summary: hostCaseNoteSummary,
+			Msg:     hostCaseNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":      "routes",
+				"namespace":    "default",
+				"vs_host":      "MyService",
+				"dr_name":      "subsets",
+				"dr_namespace": "default",
+				"dr_host":      "myservice",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createHostCaseNotes(vsList, drList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
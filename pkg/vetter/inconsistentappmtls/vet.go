@@ -0,0 +1,183 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package inconsistentappmtls vets the effective mTLS modes of the services
+// of an application and generates notes if they are not the same.
+package inconsistentappmtls
+
+import (
+	"sort"
+	"strings"
+
+	authv1alpha1 "github.com/aspenmesh/istio-client-go/pkg/client/listers/authentication/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "InconsistentAppMtls"
+	inconsistentMtlsNoteType = "inconsistent-app-mtls"
+	inconsistentMtlsSummary  = "Inconsistent mTLS modes for app - ${app}"
+	inconsistentMtlsMsg      = "The services of app ${app} don't use the same mTLS mode:" +
+		" ${service_modes}. Mixing modes within an application is hard to reason" +
+		" about and leaves some of its services accepting plaintext. Consider" +
+		" applying the same authentication policy to all services of the app."
+)
+
+// AppMtls implements Vetter interface
+type AppMtls struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	apLister  authv1alpha1.PolicyLister
+	mpLister  authv1alpha1.MeshPolicyLister
+}
+
+// appName returns the application a service belongs to, taken from the app
+// label of its selector, or of the service itself if the selector has none.
+func appName(s *corev1.Service) string {
+	if app := s.Spec.Selector[util.IstioAppLabel]; app != "" {
+		return app
+	}
+	return s.Labels[util.IstioAppLabel]
+}
+
+// modeName returns the name of the mTLS setting.
+func modeName(m mtlspolicyutil.MTLSSetting) string {
+	switch m {
+	case mtlspolicyutil.MTLSSetting_ENABLED:
+		return "STRICT"
+	case mtlspolicyutil.MTLSSetting_PERMISSIVE:
+		return "PERMISSIVE"
+	case mtlspolicyutil.MTLSSetting_MIXED:
+		return "MIXED"
+	case mtlspolicyutil.MTLSSetting_DISABLED:
+		return "DISABLED"
+	}
+	return ""
+}
+
+// createInconsistentMtlsNotes groups the services by application and
+// generates a note for every application whose services have different
+// effective mTLS modes.
+func createInconsistentMtlsNotes(services []*corev1.Service,
+	ap *mtlspolicyutil.AuthPolicies) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	// app -> mode -> service names
+	apps := map[string]map[string][]string{}
+	for _, s := range services {
+		app := appName(s)
+		if app == "" {
+			continue
+		}
+		mtls, _, err := ap.TLSDetailsByName(
+			mtlspolicyutil.Service{Name: s.Name, Namespace: s.Namespace})
+		mode := modeName(mtls)
+		if err != nil || mode == "" {
+			continue
+		}
+		if apps[app] == nil {
+			apps[app] = map[string][]string{}
+		}
+		apps[app][mode] = append(apps[app][mode], s.Name+"."+s.Namespace)
+	}
+
+	appList := make([]string, 0, len(apps))
+	for app := range apps {
+		appList = append(appList, app)
+	}
+	sort.Strings(appList)
+	for _, app := range appList {
+		modes := apps[app]
+		if len(modes) < 2 {
+			continue
+		}
+		modeList := make([]string, 0, len(modes))
+		for m := range modes {
+			modeList = append(modeList, m)
+		}
+		sort.Strings(modeList)
+		desc := make([]string, 0, len(modeList))
+		for _, m := range modeList {
+			svcs := modes[m]
+			sort.Strings(svcs)
+			desc = append(desc, m+" ("+strings.Join(svcs, ", ")+")")
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    inconsistentMtlsNoteType,
+			Summary: inconsistentMtlsSummary,
+			Msg:     inconsistentMtlsMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"app":           app,
+				"service_modes": strings.Join(desc, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (a *AppMtls) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(a.nsLister, a.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			inconsistentMtlsNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	policyList, err := a.apLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Policies: %s", err)
+		return nil, err
+	}
+	meshPolicyList, err := a.mpLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve MeshPolicies: %s", err)
+		return nil, err
+	}
+	authPolicies, err := mtlspolicyutil.LoadAuthPolicies(policyList, meshPolicyList)
+	if err != nil {
+		glog.Errorln("Unable to load auth policies")
+		return nil, err
+	}
+	return createInconsistentMtlsNotes(services, authPolicies), nil
+}
+
+// Info returns information about the vetter
+func (a *AppMtls) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "AppMtls" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *AppMtls {
+	return &AppMtls{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		apLister:  factory.Istio().Authentication().V1alpha1().Policies().Lister(),
+		mpLister:  factory.Istio().Authentication().V1alpha1().MeshPolicies().Lister(),
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package inconsistentappmtls
+
+import (
+	authv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/authentication/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istioauthv1alpha1 "istio.io/api/authentication/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespacePolicy(namespace string, mode istioauthv1alpha1.MutualTls_Mode) *authv1alpha1api.Policy {
+	return &authv1alpha1api.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace},
+		Spec: authv1alpha1api.PolicySpec{
+			Policy: istioauthv1alpha1.Policy{
+				Peers: []*istioauthv1alpha1.PeerAuthenticationMethod{
+					{
+						Params: &istioauthv1alpha1.PeerAuthenticationMethod_Mtls{
+							Mtls: &istioauthv1alpha1.MutualTls{Mode: mode},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func appService(name, namespace string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "shop"},
+		},
+	}
+}
+
+var _ = Describe("Inconsistent mTLS modes within an app", func() {
+	var ap *mtlspolicyutil.AuthPolicies
+
+	BeforeEach(func() {
+		var err error
+		ap, err = mtlspolicyutil.LoadAuthPolicies([]*authv1alpha1api.Policy{
+			namespacePolicy("strict-a", istioauthv1alpha1.MutualTls_STRICT),
+			namespacePolicy("strict-b", istioauthv1alpha1.MutualTls_STRICT),
+			namespacePolicy("permissive-a", istioauthv1alpha1.MutualTls_PERMISSIVE),
+			namespacePolicy("permissive-b", istioauthv1alpha1.MutualTls_PERMISSIVE),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates zero notes for a uniformly STRICT app", func() {
+		svcs := []*corev1.Service{
+			appService("cart", "strict-a"),
+			appService("checkout", "strict-b"),
+		}
+		Expect(createInconsistentMtlsNotes(svcs, ap)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a uniformly PERMISSIVE app", func() {
+		svcs := []*corev1.Service{
+			appService("cart", "permissive-a"),
+			appService("checkout", "permissive-b"),
+		}
+		Expect(createInconsistentMtlsNotes(svcs, ap)).To(HaveLen(0))
+	})
+
+	It("creates a note for an app with mixed modes", func() {
+		svcs := []*corev1.Service{
+			appService("checkout", "strict-a"),
+			appService("cart", "permissive-a"),
+			appService("cart", "strict-b"),
+		}
+		expNote := &apiv1.Note{
+			Type:    inconsistentMtlsNoteType,
+			Summary: inconsistentMtlsSummary,
+			Msg:     inconsistentMtlsMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"app":           "shop",
+				"service_modes": "PERMISSIVE (cart.permissive-a), STRICT (cart.strict-b, checkout.strict-a)",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createInconsistentMtlsNotes(svcs, ap)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("tells PERMISSIVE services apart from services without a policy", func() {
+		svcs := []*corev1.Service{
+			appService("cart", "permissive-a"),
+			appService("checkout", "no-policy"),
+		}
+		expNote := &apiv1.Note{
+			Type:    inconsistentMtlsNoteType,
+			Summary: inconsistentMtlsSummary,
+			Msg:     inconsistentMtlsMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"app":           "shop",
+				"service_modes": "DISABLED (checkout.no-policy), PERMISSIVE (cart.permissive-a)",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createInconsistentMtlsNotes(svcs, ap)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package injectioncontrolplane vets the Namespaces with sidecar injection
+// enabled and generates notes if no running control plane injects sidecars
+// for the revision they select.
+package injectioncontrolplane
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "InjectionControlPlane"
+	missingInjectorNoteType = "injection-control-plane-missing"
+	missingInjectorSummary  = "No control plane for injection - ${namespace}"
+	missingInjectorMsg      = "The namespace ${namespace} enables sidecar injection" +
+		" by the control plane revision ${revision}, but no running control plane" +
+		" serves this revision. Pods created in the namespace don't get a sidecar." +
+		" Consider installing the control plane revision or changing the injection" +
+		" labels of the namespace."
+)
+
+// injectorApps are the values of the app label of the control plane
+// deployments which run the sidecar injector webhook.
+var injectorApps = map[string]bool{
+	"sidecarInjectorWebhook": true,
+	"istiod":                 true,
+}
+
+// InjectionControlPlane implements Vetter interface
+type InjectionControlPlane struct {
+	nsLister     v1.NamespaceLister
+	deployLister appsv1listers.DeploymentLister
+}
+
+// runningRevisions returns the revisions of the control plane deployments
+// which run the sidecar injector and have available replicas.
+func runningRevisions(deployments []*appsv1.Deployment) map[string]bool {
+	revisions := map[string]bool{}
+	for _, d := range deployments {
+		if !injectorApps[d.Labels[util.IstioAppLabel]] || d.Status.AvailableReplicas == 0 {
+			continue
+		}
+		rev := d.Labels[util.IstioRevisionLabel]
+		if rev == "" {
+			rev = util.IstioDefaultRevision
+		}
+		revisions[rev] = true
+	}
+	return revisions
+}
+
+// createMissingInjectorNotes generates a note for every Namespace with
+// injection enabled for a revision which isn't running.
+func createMissingInjectorNotes(namespaces []*corev1.Namespace,
+	deployments []*appsv1.Deployment) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	revisions := runningRevisions(deployments)
+	for _, ns := range namespaces {
+		rev, ok := util.InjectionRevision(ns)
+		if !ok || revisions[rev] {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    missingInjectorNoteType,
+			Summary: missingInjectorSummary,
+			Msg:     missingInjectorMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"namespace": ns.Name,
+				"revision":  rev,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (i *InjectionControlPlane) Vet() ([]*apiv1.Note, error) {
+	namespaces, err := i.nsLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve namespaces: %s", err)
+		return nil, err
+	}
+	deployments, err := i.deployLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Deployments: %s", err)
+		return nil, err
+	}
+	return createMissingInjectorNotes(namespaces, deployments), nil
+}
+
+// Info returns information about the vetter
+func (i *InjectionControlPlane) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "InjectionControlPlane" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *InjectionControlPlane {
+	return &InjectionControlPlane{
+		nsLister:     factory.K8s().Core().V1().Namespaces().Lister(),
+		deployLister: factory.K8s().Apps().V1().Deployments().Lister(),
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injectioncontrolplane
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespace(l map[string]string) []*corev1.Namespace {
+	return []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: l}},
+	}
+}
+
+func controlPlane(name string, l map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "istio-system", Labels: l},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+	}
+}
+
+var _ = Describe("Injection without control plane", func() {
+	deployments := []*appsv1.Deployment{
+		controlPlane("istio-sidecar-injector", map[string]string{"app": "sidecarInjectorWebhook"}),
+		controlPlane("istiod-canary", map[string]string{"app": "istiod", "istio.io/rev": "canary"}),
+	}
+
+	It("creates zero notes if the default revision is running", func() {
+		ns := namespace(map[string]string{"istio-injection": "enabled"})
+		Expect(createMissingInjectorNotes(ns, deployments)).To(HaveLen(0))
+	})
+
+	It("creates zero notes if the named revision is running", func() {
+		ns := namespace(map[string]string{"istio.io/rev": "canary"})
+		Expect(createMissingInjectorNotes(ns, deployments)).To(HaveLen(0))
+	})
+
+	It("creates a note if the named revision is absent", func() {
+		expNote := &apiv1.Note{
+			Type:    missingInjectorNoteType,
+			Summary: missingInjectorSummary,
+			Msg:     missingInjectorMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"namespace": "bookinfo",
+				"revision":  "stable",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		ns := namespace(map[string]string{"istio.io/rev": "stable"})
+		Expect(createMissingInjectorNotes(ns, deployments)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note if the control plane has no available replicas", func() {
+		scaledDown := controlPlane("istio-sidecar-injector", map[string]string{"app": "sidecarInjectorWebhook"})
+		scaledDown.Status.AvailableReplicas = 0
+		ns := namespace(map[string]string{"istio-injection": "enabled"})
+		notes := createMissingInjectorNotes(ns, []*appsv1.Deployment{scaledDown})
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["revision"]).To(Equal(util.IstioDefaultRevision))
+	})
+})
@@ -0,0 +1,104 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package injectionlabelconflict vets the injection labels of Namespaces and
+// generates notes if a Namespace carries both the istio-injection and the
+// istio.io/rev label.
+package injectionlabelconflict
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "InjectionLabelConflict"
+	labelConflictNoteType    = "injection-label-conflict"
+	labelConflictNoteSummary = "Conflicting injection labels - ${namespace}"
+	labelConflictNoteMsg     = "The namespace ${namespace} has both the" +
+		" istio-injection=${injection} and the istio.io/rev=${revision} labels." +
+		" The istio-injection label takes precedence, so ${effective}. Consider" +
+		" removing the istio-injection label once the namespace is migrated to" +
+		" the revision."
+	effectiveInjected    = "pods are injected by the default revision"
+	effectiveNotInjected = "pods are not injected"
+)
+
+// InjectionLabelConflict implements Vetter interface
+type InjectionLabelConflict struct {
+	nsLister v1.NamespaceLister
+}
+
+// createLabelConflictNotes generates a note for every Namespace with both
+// injection labels, describing the effective injection.
+func createLabelConflictNotes(namespaces []*corev1.Namespace) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, ns := range namespaces {
+		injection, ok := ns.Labels[util.IstioInjectionLabel]
+		revision := ns.Labels[util.IstioRevisionLabel]
+		if !ok || revision == "" {
+			continue
+		}
+		effective := effectiveNotInjected
+		if _, injected := util.InjectionRevision(ns); injected {
+			effective = effectiveInjected
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    labelConflictNoteType,
+			Summary: labelConflictNoteSummary,
+			Msg:     labelConflictNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"namespace": ns.Name,
+				"injection": injection,
+				"revision":  revision,
+				"effective": effective,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (i *InjectionLabelConflict) Vet() ([]*apiv1.Note, error) {
+	namespaces, err := i.nsLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve namespaces: %s", err)
+		return nil, err
+	}
+	return createLabelConflictNotes(namespaces), nil
+}
+
+// Info returns information about the vetter
+func (i *InjectionLabelConflict) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "InjectionLabelConflict" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *InjectionLabelConflict {
+	return &InjectionLabelConflict{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package injectionlabelconflict
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespace(l map[string]string) []*corev1.Namespace {
+	return []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo", Labels: l}},
+	}
+}
+
+var _ = Describe("Conflicting injection labels", func() {
+	It("creates zero notes for the istio-injection label only", func() {
+		ns := namespace(map[string]string{"istio-injection": "enabled"})
+		Expect(createLabelConflictNotes(ns)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for the revision label only", func() {
+		ns := namespace(map[string]string{"istio.io/rev": "canary"})
+		Expect(createLabelConflictNotes(ns)).To(HaveLen(0))
+	})
+
+	It("creates a note for both labels", func() {
+		expNote := &apiv1.Note{
+			Type:    labelConflictNoteType,
+			Summary: labelConflictNoteSummary,
+			Msg:     labelConflictNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"namespace": "bookinfo",
+				"injection": "enabled",
+				"revision":  "canary",
+				"effective": effectiveInjected,
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		ns := namespace(map[string]string{"istio-injection": "enabled", "istio.io/rev": "canary"})
+		Expect(createLabelConflictNotes(ns)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("describes disabled injection", func() {
+		ns := namespace(map[string]string{"istio-injection": "disabled", "istio.io/rev": "canary"})
+		notes := createLabelConflictNotes(ns)
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["effective"]).To(Equal(effectiveNotInjected))
+	})
+})
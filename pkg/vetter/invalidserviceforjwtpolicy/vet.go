@@ -116,7 +116,7 @@ func createAuthPolicyNotes(policy *v1alpha1.Policy, nsServiceLookup map[string]*
 							"service_target": t.Name,
 						},
 					}
-					n.Id = util.ComputeID(&n)
+					n.Id = util.ComputeIDStable(&n)
 					notes = append(notes, &n)
 					continue
 				}
@@ -134,7 +134,7 @@ func createAuthPolicyNotes(policy *v1alpha1.Policy, nsServiceLookup map[string]*
 							"service_target": targetSvc.Name,
 						},
 					}
-					n.Id = util.ComputeID(&n)
+					n.Id = util.ComputeIDStable(&n)
 					notes = append(notes, &n)
 				}
 			}
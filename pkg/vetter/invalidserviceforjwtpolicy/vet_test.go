@@ -294,7 +294,7 @@ var _ = Describe("Invalid Service For JWT Policy Vet Notes", func() {
 					"service_target": "httpbin",
 				},
 			}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			nsServices := []*corev1.Service{
 				{
 					TypeMeta: metav1.TypeMeta{
@@ -354,7 +354,7 @@ var _ = Describe("Invalid Service For JWT Policy Vet Notes", func() {
 					"service_target": "httpbin",
 				},
 			}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			nsServices := []*corev1.Service{
 				{
 					TypeMeta: metav1.TypeMeta{
@@ -412,7 +412,7 @@ var _ = Describe("Invalid Service For JWT Policy Vet Notes", func() {
 					"service_target": "httpbin",
 				},
 			}
-			expectedNote.Id = util.ComputeID(expectedNote)
+			expectedNote.Id = util.ComputeIDStable(expectedNote)
 			var nsServices []*corev1.Service
 			nsServiceLookup := createServiceLookup(nsServices)
 			actualNotes := createAuthPolicyNotes(httpbinJWTAuthPolicy, nsServiceLookup)
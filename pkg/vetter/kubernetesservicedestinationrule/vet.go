@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubernetesservicedestinationrule vets the hosts of DestinationRule
+// resources and generates notes if a DestinationRule applies to the
+// kubernetes Service fronting the API server.
+package kubernetesservicedestinationrule
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID            = "KubernetesServiceDestinationRule"
+	apiServerDRNoteType = "dr-kubernetes-service"
+	apiServerDRSummary  = "DestinationRule for the API server - ${dr_name}"
+	apiServerDRNoteMsg  = "The DestinationRule ${dr_name} in namespace ${namespace}" +
+		" applies to host ${host}, the kubernetes service fronting the API" +
+		" server. Its traffic policy affects every in-cluster client of the API" +
+		" server, including the Istio control plane. Consider removing the" +
+		" DestinationRule or correcting its host."
+)
+
+// KubernetesServiceDR implements Vetter interface
+type KubernetesServiceDR struct {
+	nsLister v1.NamespaceLister
+	drLister netv1alpha3.DestinationRuleLister
+}
+
+// createAPIServerDRNotes generates a note for every DestinationRule whose host
+// resolves to the kubernetes Service.
+func createAPIServerDRNotes(drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, dr := range drList {
+		host := dr.Spec.GetHost()
+		if !util.IsKubernetesServiceHost(host, dr.Namespace) {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    apiServerDRNoteType,
+			Summary: apiServerDRSummary,
+			Msg:     apiServerDRNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":   dr.Name,
+				"namespace": dr.Namespace,
+				"host":      host,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (k *KubernetesServiceDR) Vet() ([]*apiv1.Note, error) {
+	drList, err := util.ListDestinationRulesInMesh(k.nsLister, k.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createAPIServerDRNotes(drList), nil
+}
+
+// Info returns information about the vetter
+func (k *KubernetesServiceDR) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "KubernetesServiceDR" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *KubernetesServiceDR {
+	return &KubernetesServiceDR{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
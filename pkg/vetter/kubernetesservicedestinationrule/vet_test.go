@@ -0,0 +1,73 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetesservicedestinationrule
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func destinationRule(namespace, host string) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: namespace},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{Host: host},
+			},
+		},
+	}
+}
+
+func apiServerDRNote(namespace, host string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    apiServerDRNoteType,
+		Summary: apiServerDRSummary,
+		Msg:     apiServerDRNoteMsg,
+		Level:   apiv1.NoteLevel_WARNING,
+		Attr: map[string]string{
+			"dr_name":   "api",
+			"namespace": namespace,
+			"host":      host,
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("DestinationRule for the kubernetes service", func() {
+	It("creates zero notes for a normal host", func() {
+		Expect(createAPIServerDRNotes(destinationRule("default", "reviews"))).To(HaveLen(0))
+		Expect(createAPIServerDRNotes(destinationRule("foo", "kubernetes"))).To(HaveLen(0))
+	})
+
+	It("creates a note for the short kubernetes host", func() {
+		notes := createAPIServerDRNotes(destinationRule("default", "kubernetes"))
+		Expect(notes).To(Equal([]*apiv1.Note{apiServerDRNote("default", "kubernetes")}))
+	})
+
+	It("creates a note for the kubernetes FQDN", func() {
+		notes := createAPIServerDRNotes(destinationRule("foo", "kubernetes.default.svc.cluster.local"))
+		Expect(notes).To(Equal([]*apiv1.Note{
+			apiServerDRNote("foo", "kubernetes.default.svc.cluster.local"),
+		}))
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package latestimagetag vets the container images of pods in the mesh and
+// generates notes if they aren't pinned to a version.
+package latestimagetag
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "LatestImageTag"
+	latestImageNoteType    = "latest-image-tag"
+	latestImageNoteSummary = "Unpinned image - ${pod_name}"
+	latestImageNoteMsg     = "The container ${container_name} of pod ${pod_name} in" +
+		" namespace ${namespace} runs the image ${image}, which uses the latest tag." +
+		" The version of the container can't be vetted and changes when the image" +
+		" is pulled again. Consider pinning the image to a version tag or digest."
+	latestTag = "latest"
+)
+
+// LatestImageTag implements Vetter interface
+type LatestImageTag struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+}
+
+// latestImage returns true if the image uses the latest tag, either by name
+// or by omitting the tag. Images pinned by digest are never latest.
+func latestImage(image string) bool {
+	ref, err := util.ParseImage(image)
+	return err == nil && ref.Digest == "" && ref.Tag == latestTag
+}
+
+// createLatestImageNotes generates a note for every container of the pods,
+// including the sidecar and its init container, whose image uses the latest
+// tag.
+func createLatestImageNotes(pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, p := range pods {
+		// The containers are looked up directly rather than with util.Image,
+		// which logs an error for every pod without the container.
+		images := []corev1.Container{}
+		for _, c := range p.Spec.InitContainers {
+			if c.Name == util.IstioInitContainerName {
+				images = append(images, c)
+			}
+		}
+		for _, c := range p.Spec.Containers {
+			if c.Name == util.IstioProxyContainerName {
+				images = append(images, c)
+			}
+		}
+		for _, c := range p.Spec.Containers {
+			if c.Name != util.IstioProxyContainerName {
+				images = append(images, c)
+			}
+		}
+		for _, c := range images {
+			if !latestImage(c.Image) {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    latestImageNoteType,
+				Summary: latestImageNoteSummary,
+				Msg:     latestImageNoteMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"pod_name":       p.Name,
+					"namespace":      p.Namespace,
+					"container_name": c.Name,
+					"image":          c.Image,
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (l *LatestImageTag) Vet() ([]*apiv1.Note, error) {
+	pods, err := util.ListPodsInMesh(l.nsLister, l.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			latestImageNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createLatestImageNotes(pods), nil
+}
+
+// Info returns information about the vetter
+func (l *LatestImageTag) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "LatestImageTag" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *LatestImageTag {
+	return &LatestImageTag{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
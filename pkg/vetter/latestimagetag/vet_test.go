@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latestimagetag
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(appImage string) []*corev1.Pod {
+	return []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews-1", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{
+					{Name: util.IstioInitContainerName, Image: "docker.io/istio/proxy_init:1.4.3"},
+				},
+				Containers: []corev1.Container{
+					{Name: "reviews", Image: appImage},
+					{Name: util.IstioProxyContainerName, Image: "docker.io/istio/proxyv2:1.4.3"},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Latest image tags", func() {
+	It("creates zero notes for pinned tags", func() {
+		Expect(createLatestImageNotes(pod("registry:5000/reviews:1.0"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for images pinned by digest", func() {
+		image := "example/reviews@sha256:4bf5e1a3c6c2d8b5f8a0a3b1c9d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9"
+		Expect(createLatestImageNotes(pod(image))).To(HaveLen(0))
+	})
+
+	It("creates a note for the latest tag", func() {
+		expNote := &apiv1.Note{
+			Type:    latestImageNoteType,
+			Summary: latestImageNoteSummary,
+			Msg:     latestImageNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"pod_name":       "reviews-1",
+				"namespace":      "default",
+				"container_name": "reviews",
+				"image":          "example/reviews:latest",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createLatestImageNotes(pod("example/reviews:latest"))).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for images without a tag", func() {
+		notes := createLatestImageNotes(pod("registry:5000/reviews"))
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["image"]).To(Equal("registry:5000/reviews"))
+	})
+})
@@ -111,7 +111,7 @@ func vetPods(pods []*corev1.Pod, injImages injectImages) []*apiv1.Note {
 	}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 	return notes
 }
@@ -0,0 +1,210 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mirrorstrictmtls vets the mirror destinations of VirtualService
+// routes and generates notes if traffic is mirrored to destinations which
+// enforce STRICT mTLS from sources which don't originate mTLS for the mirror
+// host.
+package mirrorstrictmtls
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	authv1alpha1 "github.com/aspenmesh/istio-client-go/pkg/client/listers/authentication/v1alpha1"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	"github.com/golang/glog"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID             = "MirrorStrictMtls"
+	mirrorStrictNoteType = "mirror-strict-mtls"
+	mirrorStrictSummary  = "Mirror source can't satisfy STRICT mTLS - ${vs_name}"
+	mirrorStrictNoteMsg  = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" mirrors traffic to ${mirror} which enforces STRICT mTLS, but neither" +
+		" auto mTLS nor a DestinationRule with TLS mode ISTIO_MUTUAL makes the" +
+		" source sidecars originate mTLS for ${mirror}. The mirror destination" +
+		" rejects the mirrored requests without the failures surfacing to the" +
+		" clients. Consider enabling auto mTLS or adding a DestinationRule for" +
+		" ${mirror} with TLS mode ISTIO_MUTUAL."
+)
+
+// MirrorStrictMtls implements Vetter interface
+type MirrorStrictMtls struct {
+	nsLister v1.NamespaceLister
+	cmLister v1.ConfigMapLister
+	vsLister netv1alpha3.VirtualServiceLister
+	drLister netv1alpha3.DestinationRuleLister
+	apLister authv1alpha1.PolicyLister
+	mpLister authv1alpha1.MeshPolicyLister
+}
+
+// meshWideMtls returns true if a mesh-wide DestinationRule, with host
+// "*.local" or "*", sets the client TLS mode to ISTIO_MUTUAL.
+func meshWideMtls(drList []*v1alpha3.DestinationRule) bool {
+	for _, dr := range drList {
+		if dr.Spec.GetHost() != "*.local" && dr.Spec.GetHost() != "*" {
+			continue
+		}
+		if mtlspolicyutil.DestRuleIsMtls(dr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientMtls returns true if the DestinationRules set the client TLS mode of
+// the service port to ISTIO_MUTUAL. The most specific rule wins: port level
+// settings first, then the service rule, the namespace rule and finally the
+// mesh-wide rule. Without any rule the sidecars only originate mTLS if auto
+// mTLS is enabled. The second return value is false if conflicting rules make
+// the mode ambiguous.
+func clientMtls(dr *mtlspolicyutil.DestRules, meshMtls bool,
+	s mtlspolicyutil.Service, port uint32) (bool, bool) {
+	if port != 0 {
+		if rules := dr.ByPort(s, port); len(rules) > 1 {
+			return false, false
+		} else if len(rules) == 1 {
+			return mtlspolicyutil.PortDestRuleIsMtls(rules[0]), true
+		}
+	}
+	if rules := dr.ByName(s); len(rules) > 1 {
+		return false, false
+	} else if len(rules) == 1 {
+		return mtlspolicyutil.DestRuleIsMtls(rules[0]), true
+	}
+	if rules := dr.ByNamespace(s.Namespace); len(rules) > 1 {
+		return false, false
+	} else if len(rules) == 1 {
+		return mtlspolicyutil.DestRuleIsMtls(rules[0]), true
+	}
+	return meshMtls, true
+}
+
+// createMirrorStrictNotes generates notes for the HTTP routes of the
+// VirtualServices which mirror traffic to destinations enforcing STRICT mTLS
+// while the source sidecars, which apply the routes, don't originate mTLS for
+// the mirror host.
+func createMirrorStrictNotes(mc *meshv1alpha1.MeshConfig,
+	vsList []*v1alpha3.VirtualService,
+	drList []*v1alpha3.DestinationRule,
+	ap *mtlspolicyutil.AuthPolicies) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	dr, err := mtlspolicyutil.LoadDestRules(drList)
+	if err != nil {
+		glog.Errorln("Unable to load destination rules")
+		return notes
+	}
+	// An explicit DestinationRule TLS mode overrides auto mTLS, so auto mTLS
+	// only takes the place of the mesh-wide rule.
+	meshMtls := meshWideMtls(drList) || mc.GetEnableAutoMtls().GetValue()
+	for _, vs := range vsList {
+		for _, route := range vs.Spec.GetHttp() {
+			m := route.GetMirror()
+			if m == nil || m.GetHost() == "" {
+				continue
+			}
+			fqdn, err := util.ConvertHostnameToFQDN(m.GetHost(), vs.Namespace)
+			if err != nil || !ap.IsStrict(fqdn, m.GetPort().GetNumber()) {
+				continue
+			}
+			s, err := mtlspolicyutil.ServiceFromFqdn(fqdn)
+			if err != nil {
+				continue
+			}
+			if isMtls, ok := clientMtls(dr, meshMtls, s, m.GetPort().GetNumber()); !ok || isMtls {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    mirrorStrictNoteType,
+				Summary: mirrorStrictSummary,
+				Msg:     mirrorStrictNoteMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"vs_name":   vs.Name,
+					"namespace": vs.Namespace,
+					"mirror":    m.GetHost(),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MirrorStrictMtls) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(m.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := util.GetMeshConfig(cm)
+	if err != nil {
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(m.nsLister, m.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	// DestinationRules of every namespace are listed since the rules of a
+	// mirror host may live outside the namespaces in the mesh.
+	drList, err := m.drLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve DestinationRules: %s", err)
+		return nil, err
+	}
+	policyList, err := m.apLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Policies: %s", err)
+		return nil, err
+	}
+	meshPolicyList, err := m.mpLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve MeshPolicies: %s", err)
+		return nil, err
+	}
+	authPolicies, err := mtlspolicyutil.LoadAuthPolicies(policyList, meshPolicyList)
+	if err != nil {
+		glog.Errorln("Unable to load auth policies")
+		return nil, err
+	}
+	return createMirrorStrictNotes(mc, vsList, drList, authPolicies), nil
+}
+
+// Info returns information about the vetter
+func (m *MirrorStrictMtls) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MirrorStrictMtls" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MirrorStrictMtls {
+	return &MirrorStrictMtls{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister: factory.K8s().Core().V1().ConfigMaps().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+		apLister: factory.Istio().Authentication().V1alpha1().Policies().Lister(),
+		mpLister: factory.Istio().Authentication().V1alpha1().MeshPolicies().Lister(),
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mirrorstrictmtls
+
+import (
+	authv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/authentication/v1alpha1"
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	"github.com/gogo/protobuf/types"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istioauthv1alpha1 "istio.io/api/authentication/v1alpha1"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespacePolicy(namespace string, mode istioauthv1alpha1.MutualTls_Mode) *authv1alpha1api.Policy {
+	return &authv1alpha1api.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace},
+		Spec: authv1alpha1api.PolicySpec{
+			Policy: istioauthv1alpha1.Policy{
+				Peers: []*istioauthv1alpha1.PeerAuthenticationMethod{
+					{
+						Params: &istioauthv1alpha1.PeerAuthenticationMethod_Mtls{
+							Mtls: &istioauthv1alpha1.MutualTls{Mode: mode},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func destinationRule(name, host string, mode istiov1alpha3.TLSSettings_TLSmode) *v1alpha3.DestinationRule {
+	return &v1alpha3.DestinationRule{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1alpha3.DestinationRuleSpec{
+			DestinationRule: istiov1alpha3.DestinationRule{
+				Host: host,
+				TrafficPolicy: &istiov1alpha3.TrafficPolicy{
+					Tls: &istiov1alpha3.TLSSettings{Mode: mode},
+				},
+			},
+		},
+	}
+}
+
+func mirroredVirtualService(mirror string) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-routes", Namespace: "default"},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: []string{"frontend"},
+				Http: []*istiov1alpha3.HTTPRoute{
+					{
+						Route: []*istiov1alpha3.HTTPRouteDestination{
+							{Destination: &istiov1alpha3.Destination{Host: "frontend"}},
+						},
+						Mirror: &istiov1alpha3.Destination{Host: mirror},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Mirror traffic to STRICT mTLS destinations", func() {
+	var (
+		ap     *mtlspolicyutil.AuthPolicies
+		vsList []*v1alpha3.VirtualService
+	)
+	mc := &meshv1alpha1.MeshConfig{}
+	autoMtls := &meshv1alpha1.MeshConfig{EnableAutoMtls: &types.BoolValue{Value: true}}
+
+	BeforeEach(func() {
+		var err error
+		ap, err = mtlspolicyutil.LoadAuthPolicies([]*authv1alpha1api.Policy{
+			namespacePolicy("shadow", istioauthv1alpha1.MutualTls_STRICT),
+			namespacePolicy("legacy", istioauthv1alpha1.MutualTls_PERMISSIVE),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		vsList = []*v1alpha3.VirtualService{
+			mirroredVirtualService("frontend.shadow.svc.cluster.local"),
+		}
+	})
+
+	It("creates zero notes on empty lists", func() {
+		Expect(createMirrorStrictNotes(mc, nil, nil, ap)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a meshed source mirroring to a STRICT destination", func() {
+		By("having a DestinationRule for the mirror host using ISTIO_MUTUAL")
+		drList := []*v1alpha3.DestinationRule{
+			destinationRule("frontend-shadow", "frontend.shadow.svc.cluster.local",
+				istiov1alpha3.TLSSettings_ISTIO_MUTUAL),
+		}
+		Expect(createMirrorStrictNotes(mc, vsList, drList, ap)).To(HaveLen(0))
+
+		By("having a mesh-wide DestinationRule using ISTIO_MUTUAL")
+		drList = []*v1alpha3.DestinationRule{
+			destinationRule("default", "*.local", istiov1alpha3.TLSSettings_ISTIO_MUTUAL),
+		}
+		Expect(createMirrorStrictNotes(mc, vsList, drList, ap)).To(HaveLen(0))
+
+		By("enabling auto mTLS without any DestinationRule")
+		Expect(createMirrorStrictNotes(autoMtls, vsList, nil, ap)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a PERMISSIVE mirror destination", func() {
+		vsList = []*v1alpha3.VirtualService{
+			mirroredVirtualService("frontend.legacy.svc.cluster.local"),
+		}
+		Expect(createMirrorStrictNotes(mc, vsList, nil, ap)).To(HaveLen(0))
+	})
+
+	It("creates notes for a non-mesh source mirroring to a STRICT destination", func() {
+		expNote := &apiv1.Note{
+			Type:    mirrorStrictNoteType,
+			Summary: mirrorStrictSummary,
+			Msg:     mirrorStrictNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":   "frontend-routes",
+				"namespace": "default",
+				"mirror":    "frontend.shadow.svc.cluster.local",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+
+		By("having neither auto mTLS nor a DestinationRule for the mirror host")
+		Expect(createMirrorStrictNotes(mc, vsList, nil, ap)).To(Equal([]*apiv1.Note{expNote}))
+
+		By("having a DestinationRule disabling TLS for the mirror host")
+		drList := []*v1alpha3.DestinationRule{
+			destinationRule("default", "*.local", istiov1alpha3.TLSSettings_ISTIO_MUTUAL),
+			destinationRule("frontend-shadow", "frontend.shadow.svc.cluster.local",
+				istiov1alpha3.TLSSettings_DISABLE),
+		}
+		Expect(createMirrorStrictNotes(mc, vsList, drList, ap)).To(Equal([]*apiv1.Note{expNote}))
+
+		By("having a DestinationRule disabling TLS despite auto mTLS")
+		Expect(createMirrorStrictNotes(autoMtls, vsList, drList, ap)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package missingnamespacepolicy vets the namespaces in the mesh and generates
+// notes if they have no namespace-wide authentication policy.
+package missingnamespacepolicy
+
+import (
+	authv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/authentication/v1alpha1"
+	authv1alpha1 "github.com/aspenmesh/istio-client-go/pkg/client/listers/authentication/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID              = "MissingNamespacePolicy"
+	missingPolicyNoteType = "missing-namespace-policy"
+	missingPolicySummary  = "No namespace authentication policy - ${namespace}"
+	missingPolicyMsg      = "The namespace ${namespace} is in the mesh but has no" +
+		" namespace-wide authentication Policy, so its mTLS mode is inherited from" +
+		" the MeshPolicy which may be PERMISSIVE. Consider creating a Policy named" +
+		" \"default\" without targets in the namespace to make its peer" +
+		" authentication explicit."
+	namespacePolicyName = "default"
+)
+
+// MissingNamespacePolicy implements Vetter interface
+type MissingNamespacePolicy struct {
+	nsLister v1.NamespaceLister
+	apLister authv1alpha1.PolicyLister
+}
+
+// createMissingPolicyNotes generates notes for the namespaces which have no
+// namespace-wide Policy, i.e. a Policy named "default" without targets.
+func createMissingPolicyNotes(namespaces []*corev1.Namespace,
+	policies []*authv1alpha1api.Policy) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	covered := map[string]bool{}
+	for _, p := range policies {
+		if p.Name == namespacePolicyName && len(p.Spec.GetTargets()) == 0 {
+			covered[p.Namespace] = true
+		}
+	}
+	for _, ns := range namespaces {
+		if covered[ns.Name] {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    missingPolicyNoteType,
+			Summary: missingPolicySummary,
+			Msg:     missingPolicyMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"namespace": ns.Name,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MissingNamespacePolicy) Vet() ([]*apiv1.Note, error) {
+	namespaces, err := util.ListNamespacesInMesh(m.nsLister)
+	if err != nil {
+		return nil, err
+	}
+	policies, err := m.apLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Policies: %s", err)
+		return nil, err
+	}
+	return createMissingPolicyNotes(namespaces, policies), nil
+}
+
+// Info returns information about the vetter
+func (m *MissingNamespacePolicy) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MissingNamespacePolicy" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MissingNamespacePolicy {
+	return &MissingNamespacePolicy{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		apLister: factory.Istio().Authentication().V1alpha1().Policies().Lister(),
+	}
+}
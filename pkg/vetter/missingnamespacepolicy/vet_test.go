@@ -0,0 +1,63 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package missingnamespacepolicy
+
+import (
+	authv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/authentication/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istioauthv1alpha1 "istio.io/api/authentication/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("Namespaces without an authentication policy", func() {
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+	}
+	policies := []*authv1alpha1api.Policy{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "bookinfo"}},
+		// Policies with targets only apply to some services.
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "cart", Namespace: "shop"},
+			Spec: authv1alpha1api.PolicySpec{
+				Policy: istioauthv1alpha1.Policy{
+					Targets: []*istioauthv1alpha1.TargetSelector{{Name: "cart"}},
+				},
+			},
+		},
+	}
+
+	It("creates zero notes for a namespace with a policy", func() {
+		Expect(createMissingPolicyNotes(namespaces[:1], policies)).To(HaveLen(0))
+	})
+
+	It("creates a note for a namespace without a policy", func() {
+		expNote := &apiv1.Note{
+			Type:    missingPolicyNoteType,
+			Summary: missingPolicySummary,
+			Msg:     missingPolicyMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr:    map[string]string{"namespace": "shop"},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createMissingPolicyNotes(namespaces, policies)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,112 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mixedendpoints vets the Endpoints in the mesh and generates notes
+// if they mix addresses of pods with externally managed addresses.
+package mixedendpoints
+
+import (
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "MixedEndpoints"
+	mixedEndpointsNoteType = "mixed-pod-external-endpoints"
+	mixedEndpointsSummary  = "Endpoints mix pods and external addresses - ${service_name}"
+	mixedEndpointsMsg      = "The Endpoints of service ${service_name} in namespace" +
+		" ${namespace} mix pod addresses with the externally managed addresses" +
+		" ${address_list}. Istio expects all endpoints of a service to behave" +
+		" alike, e.g. for mutual TLS. Consider moving the external addresses to a" +
+		" separate service or a ServiceEntry."
+)
+
+// MixedEndpoints implements Vetter interface
+type MixedEndpoints struct {
+	nsLister v1.NamespaceLister
+	epLister v1.EndpointsLister
+}
+
+// createMixedEndpointsNotes generates a note for every Endpoints object with
+// both addresses referencing a pod and addresses without a target reference.
+func createMixedEndpointsNotes(endpoints []*corev1.Endpoints) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, ep := range endpoints {
+		pods := false
+		external := []string{}
+		for _, subset := range ep.Subsets {
+			addrs := append(append([]corev1.EndpointAddress{}, subset.Addresses...),
+				subset.NotReadyAddresses...)
+			for _, a := range addrs {
+				if a.TargetRef == nil {
+					external = append(external, a.IP)
+				} else if a.TargetRef.Kind == "Pod" {
+					pods = true
+				}
+			}
+		}
+		if !pods || len(external) == 0 {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    mixedEndpointsNoteType,
+			Summary: mixedEndpointsSummary,
+			Msg:     mixedEndpointsMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"service_name": ep.Name,
+				"namespace":    ep.Namespace,
+				"address_list": strings.Join(external, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MixedEndpoints) Vet() ([]*apiv1.Note, error) {
+	endpoints, err := util.ListEndpointsInMesh(m.nsLister, m.epLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			mixedEndpointsNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createMixedEndpointsNotes(endpoints), nil
+}
+
+// Info returns information about the vetter
+func (m *MixedEndpoints) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MixedEndpoints" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MixedEndpoints {
+	return &MixedEndpoints{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		epLister: factory.K8s().Core().V1().Endpoints().Lister(),
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mixedendpoints
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func podAddress(ip string) corev1.EndpointAddress {
+	return corev1.EndpointAddress{
+		IP:        ip,
+		TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "reviews-" + ip, Namespace: "default"},
+	}
+}
+
+func endpoints(addrs ...corev1.EndpointAddress) []*corev1.Endpoints {
+	return []*corev1.Endpoints{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: addrs}},
+		},
+	}
+}
+
+var _ = Describe("Mixed endpoints", func() {
+	It("creates zero notes for pod-only endpoints", func() {
+		notes := createMixedEndpointsNotes(endpoints(podAddress("10.1.0.1"), podAddress("10.1.0.2")))
+		Expect(notes).To(HaveLen(0))
+	})
+
+	It("creates zero notes for external-only endpoints", func() {
+		notes := createMixedEndpointsNotes(endpoints(
+			corev1.EndpointAddress{IP: "192.168.0.10"},
+			corev1.EndpointAddress{IP: "192.168.0.11"},
+		))
+		Expect(notes).To(HaveLen(0))
+	})
+
+	It("creates a note for endpoints mixing pods and external addresses", func() {
+		eps := endpoints(podAddress("10.1.0.1"), corev1.EndpointAddress{IP: "192.168.0.10"})
+		eps[0].Subsets[0].NotReadyAddresses = []corev1.EndpointAddress{{IP: "192.168.0.11"}}
+		expNote := &apiv1.Note{
+			Type:    mixedEndpointsNoteType,
+			Summary: mixedEndpointsSummary,
+			Msg:     mixedEndpointsMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"service_name": "reviews",
+				"namespace":    "default",
+				"address_list": "192.168.0.10, 192.168.0.11",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createMixedEndpointsNotes(eps)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -88,7 +88,7 @@ func (m *MeshStats) Vet() ([]*apiv1.Note, error) {
 				"num_system_pods": strconv.Itoa(totalSystemPods)}}}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 
 	return notes, nil
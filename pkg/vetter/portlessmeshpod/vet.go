@@ -0,0 +1,132 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portlessmeshpod vets the pods in the mesh and generates notes if a
+// pod with an injected sidecar exposes no ports and no Service selects it.
+package portlessmeshpod
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "PortlessMeshPod"
+	portlessPodNoteType    = "portless-mesh-pod"
+	portlessPodNoteSummary = "Sidecar injected into a pod without ports - ${workload}"
+	portlessPodNoteMsg     = "The pods of ${workload} in namespace ${namespace} have" +
+		" an injected sidecar, but expose no container ports and no service" +
+		" selects them. They gain little from the mesh. Consider annotating the" +
+		" pods with sidecar.istio.io/inject: \"false\"."
+)
+
+// PortlessMeshPod implements Vetter interface
+type PortlessMeshPod struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+	rsLister  appsv1.ReplicaSetLister
+}
+
+// exposesPorts returns true if an application container of the pod declares
+// a container port. The ports of the sidecar proxy are ignored.
+func exposesPorts(p *corev1.Pod) bool {
+	for _, c := range p.Spec.Containers {
+		if c.Name != util.IstioProxyContainerName && len(c.Ports) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// createPortlessPodNotes generates a note for every workload with an injected
+// pod which exposes no container ports and isn't selected by a Service.
+func createPortlessPodNotes(services []*corev1.Service, pods []*corev1.Pod,
+	rsLister appsv1.ReplicaSetLister) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	fronted := map[*corev1.Pod]bool{}
+	for _, s := range services {
+		for _, p := range util.SubsetPods(s, nil, pods) {
+			fronted[p] = true
+		}
+	}
+	seen := map[util.Workload]bool{}
+	for _, p := range pods {
+		if !util.SidecarInjected(p) || exposesPorts(p) || fronted[p] {
+			continue
+		}
+		w := util.ResolveWorkload(p, rsLister)
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		notes = append(notes, &apiv1.Note{
+			Type:    portlessPodNoteType,
+			Summary: portlessPodNoteSummary,
+			Msg:     portlessPodNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"workload":  w.String(),
+				"namespace": w.Namespace,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *PortlessMeshPod) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			portlessPodNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(m.nsLister, m.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			portlessPodNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createPortlessPodNotes(services, pods, m.rsLister), nil
+}
+
+// Info returns information about the vetter
+func (m *PortlessMeshPod) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "PortlessMeshPod" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *PortlessMeshPod {
+	return &PortlessMeshPod{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		rsLister:  factory.K8s().Apps().V1().ReplicaSets().Lister(),
+	}
+}
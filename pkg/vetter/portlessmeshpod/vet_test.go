@@ -0,0 +1,95 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portlessmeshpod
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func meshPod(name string, ports []corev1.ContainerPort) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "default",
+			Labels:      map[string]string{"app": name},
+			Annotations: map[string]string{util.IstioInitializerPodAnnotation: "{}"},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: name, Ports: ports},
+				{
+					Name:  util.IstioProxyContainerName,
+					Ports: []corev1.ContainerPort{{ContainerPort: 15090}},
+				},
+			},
+		},
+	}
+}
+
+func expectedNote(workload string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    portlessPodNoteType,
+		Summary: portlessPodNoteSummary,
+		Msg:     portlessPodNoteMsg,
+		Level:   apiv1.NoteLevel_INFO,
+		Attr: map[string]string{
+			"workload":  workload,
+			"namespace": "default",
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("Mesh pods without ports", func() {
+	It("creates zero notes for a pod with container ports", func() {
+		pods := []*corev1.Pod{meshPod("web", []corev1.ContainerPort{{ContainerPort: 8080}})}
+		Expect(createPortlessPodNotes(nil, pods, nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a portless pod selected by a service", func() {
+		svcs := []*corev1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+				Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "worker"}},
+			},
+		}
+		pods := []*corev1.Pod{meshPod("worker", nil)}
+		Expect(createPortlessPodNotes(svcs, pods, nil)).To(HaveLen(0))
+	})
+
+	It("creates a note for a portless pod", func() {
+		pods := []*corev1.Pod{meshPod("worker", nil)}
+		Expect(createPortlessPodNotes(nil, pods, nil)).To(Equal([]*apiv1.Note{expectedNote("Pod/worker")}))
+	})
+
+	It("creates a single note for the pods of a Job", func() {
+		controller := true
+		pods := []*corev1.Pod{meshPod("report-1", nil), meshPod("report-2", nil)}
+		for _, p := range pods {
+			p.OwnerReferences = []metav1.OwnerReference{
+				{Kind: "Job", Name: "report", Controller: &controller},
+			}
+		}
+		Expect(createPortlessPodNotes(nil, pods, nil)).To(Equal([]*apiv1.Note{expectedNote("Job/report")}))
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxyportconflict vets the containers of pods in the mesh and
+// generates notes if an application container uses a port of the sidecar
+// proxy.
+package proxyportconflict
+
+import (
+	"strconv"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID             = "ProxyPortConflict"
+	proxyPortNoteType    = "app-port-proxy-conflict"
+	proxyPortNoteSummary = "Application uses sidecar port ${port} - ${pod_name}"
+	proxyPortNoteMsg     = "The container ${container_name} of pod ${pod_name} in" +
+		" namespace ${namespace} uses port ${port}, which is the ${proxy_port} port" +
+		" of the sidecar proxy. The application and the sidecar can't both bind the" +
+		" port, so either of them fails. Consider moving the application to another" +
+		" port."
+)
+
+// ProxyPortConflict implements Vetter interface
+type ProxyPortConflict struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+}
+
+// createProxyPortNotes generates a note for every port of the application
+// containers of the pods which is one of util.IstioProxyPorts. The ports of
+// the istio-proxy container are its own and are skipped.
+func createProxyPortNotes(pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, p := range pods {
+		for _, c := range p.Spec.Containers {
+			if c.Name == util.IstioProxyContainerName {
+				continue
+			}
+			for _, cp := range c.Ports {
+				proxyPort, ok := util.IstioProxyPorts[cp.ContainerPort]
+				if !ok {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    proxyPortNoteType,
+					Summary: proxyPortNoteSummary,
+					Msg:     proxyPortNoteMsg,
+					Level:   apiv1.NoteLevel_ERROR,
+					Attr: map[string]string{
+						"pod_name":       p.Name,
+						"namespace":      p.Namespace,
+						"container_name": c.Name,
+						"port":           strconv.Itoa(int(cp.ContainerPort)),
+						"proxy_port":     proxyPort,
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (c *ProxyPortConflict) Vet() ([]*apiv1.Note, error) {
+	pods, err := util.ListPodsInMesh(c.nsLister, c.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			proxyPortNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createProxyPortNotes(pods), nil
+}
+
+// Info returns information about the vetter
+func (c *ProxyPortConflict) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ProxyPortConflict" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ProxyPortConflict {
+	return &ProxyPortConflict{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
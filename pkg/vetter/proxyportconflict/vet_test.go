@@ -0,0 +1,77 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxyportconflict
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(appPort int32) []*corev1.Pod {
+	return []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews-1", Namespace: "default"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "reviews",
+						Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: appPort}},
+					},
+					{
+						Name: util.IstioProxyContainerName,
+						Ports: []corev1.ContainerPort{
+							{Name: "http-envoy-prom", ContainerPort: 15090},
+							{Name: "status-port", ContainerPort: 15020},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Application ports conflicting with the sidecar", func() {
+	It("creates zero notes for a safe application port", func() {
+		Expect(createProxyPortNotes(pod(9080))).To(HaveLen(0))
+	})
+
+	It("ignores the ports of the sidecar", func() {
+		Expect(createProxyPortNotes(pod(15010))).To(HaveLen(0))
+	})
+
+	It("creates a note for an application port used by the sidecar", func() {
+		expNote := &apiv1.Note{
+			Type:    proxyPortNoteType,
+			Summary: proxyPortNoteSummary,
+			Msg:     proxyPortNoteMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"pod_name":       "reviews-1",
+				"namespace":      "default",
+				"container_name": "reviews",
+				"port":           "15001",
+				"proxy_port":     "outbound capture",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createProxyPortNotes(pod(15001))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
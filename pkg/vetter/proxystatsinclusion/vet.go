@@ -0,0 +1,147 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package proxystatsinclusion vets the stats inclusion annotations of the
+// pods in the mesh and generates notes if they would suppress the metrics of
+// the sidecar proxy.
+package proxystatsinclusion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "ProxyStatsInclusion"
+	statsSuppressedNoteType = "proxy-stats-suppressed"
+	statsSuppressedSummary  = "Proxy stats suppressed - ${pod_name}"
+	statsSuppressedMsg      = "The pod ${pod_name} in namespace ${namespace} sets the" +
+		" ${annotation} annotation but ${reason}. The sidecar proxy of the pod may" +
+		" not report the standard Istio metrics. Consider fixing or removing the" +
+		" annotation."
+	statsInclusionPrefixesAnnotation = "sidecar.istio.io/statsInclusionPrefixes"
+	statsInclusionSuffixesAnnotation = "sidecar.istio.io/statsInclusionSuffixes"
+	statsInclusionRegexpsAnnotation  = "sidecar.istio.io/statsInclusionRegexps"
+	reasonEmptyList                  = "the inclusion list is empty"
+	reasonInvalidRegexp              = "the regular expression %q is invalid"
+)
+
+// statsInclusionAnnotations are checked in this order for every pod.
+var statsInclusionAnnotations = []string{
+	statsInclusionPrefixesAnnotation,
+	statsInclusionSuffixesAnnotation,
+	statsInclusionRegexpsAnnotation,
+}
+
+// ProxyStatsInclusion implements Vetter interface
+type ProxyStatsInclusion struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+}
+
+// suppressedReason returns the reason why the value of the stats inclusion
+// annotation suppresses the proxy stats, or "" if it doesn't.
+func suppressedReason(annotation, value string) string {
+	entries := []string{}
+	for _, e := range strings.Split(value, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			entries = append(entries, e)
+		}
+	}
+	if len(entries) == 0 {
+		return reasonEmptyList
+	}
+	if annotation != statsInclusionRegexpsAnnotation {
+		return ""
+	}
+	for _, e := range entries {
+		if _, err := regexp.Compile(e); err != nil {
+			return fmt.Sprintf(reasonInvalidRegexp, e)
+		}
+	}
+	return ""
+}
+
+// createStatsSuppressedNotes generates a note for every stats inclusion
+// annotation of the injected pods which suppresses the proxy stats.
+func createStatsSuppressedNotes(pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, p := range pods {
+		if !util.SidecarInjected(p) {
+			continue
+		}
+		for _, a := range statsInclusionAnnotations {
+			value, ok := p.Annotations[a]
+			if !ok {
+				continue
+			}
+			reason := suppressedReason(a, value)
+			if reason == "" {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    statsSuppressedNoteType,
+				Summary: statsSuppressedSummary,
+				Msg:     statsSuppressedMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"pod_name":   p.Name,
+					"namespace":  p.Namespace,
+					"annotation": a,
+					"reason":     reason,
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (s *ProxyStatsInclusion) Vet() ([]*apiv1.Note, error) {
+	pods, err := util.ListPodsInMesh(s.nsLister, s.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			statsSuppressedNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createStatsSuppressedNotes(pods), nil
+}
+
+// Info returns information about the vetter
+func (s *ProxyStatsInclusion) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ProxyStatsInclusion" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ProxyStatsInclusion {
+	return &ProxyStatsInclusion{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxystatsinclusion
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func injectedPod(annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "reviews-1",
+			Namespace:   "default",
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "reviews"},
+				{Name: util.IstioProxyContainerName},
+			},
+		},
+	}
+}
+
+func statsSuppressedNote(annotation, reason string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    statsSuppressedNoteType,
+		Summary: statsSuppressedSummary,
+		Msg:     statsSuppressedMsg,
+		Level:   apiv1.NoteLevel_INFO,
+		Attr: map[string]string{
+			"pod_name":   "reviews-1",
+			"namespace":  "default",
+			"annotation": annotation,
+			"reason":     reason,
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("Proxy stats inclusion", func() {
+	It("creates zero notes without the annotations", func() {
+		pods := []*corev1.Pod{injectedPod(nil)}
+		Expect(createStatsSuppressedNotes(pods)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a valid customization", func() {
+		pods := []*corev1.Pod{injectedPod(map[string]string{
+			statsInclusionPrefixesAnnotation: "cluster.outbound,listener",
+			statsInclusionSuffixesAnnotation: "upstream_rq_timeout",
+			statsInclusionRegexpsAnnotation:  "http.*\\.downstream_rq_.*",
+		})}
+		Expect(createStatsSuppressedNotes(pods)).To(HaveLen(0))
+	})
+
+	It("creates a note for an empty inclusion list", func() {
+		pods := []*corev1.Pod{injectedPod(map[string]string{
+			statsInclusionPrefixesAnnotation: " , ",
+		})}
+		Expect(createStatsSuppressedNotes(pods)).To(Equal([]*apiv1.Note{
+			statsSuppressedNote(statsInclusionPrefixesAnnotation, reasonEmptyList),
+		}))
+	})
+
+	It("creates a note for an invalid regular expression", func() {
+		pods := []*corev1.Pod{injectedPod(map[string]string{
+			statsInclusionRegexpsAnnotation: "http.*,cluster.(outbound",
+		})}
+		Expect(createStatsSuppressedNotes(pods)).To(Equal([]*apiv1.Note{
+			statsSuppressedNote(statsInclusionRegexpsAnnotation,
+				`the regular expression "cluster.(outbound" is invalid`),
+		}))
+	})
+
+	It("ignores pods without a sidecar", func() {
+		p := injectedPod(map[string]string{statsInclusionSuffixesAnnotation: ""})
+		p.Spec.Containers = p.Spec.Containers[:1]
+		Expect(createStatsSuppressedNotes([]*corev1.Pod{p})).To(HaveLen(0))
+	})
+})
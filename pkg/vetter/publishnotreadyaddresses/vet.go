@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package publishnotreadyaddresses vets the Services in the mesh and
+// generates notes if they publish the addresses of pods which aren't ready.
+package publishnotreadyaddresses
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "PublishNotReadyAddresses"
+	publishNotReadyNoteType = "publish-not-ready-addresses"
+	publishNotReadySummary  = "Service publishes not ready addresses - ${service_name}"
+	publishNotReadyNoteMsg  = "The service ${service_name} in namespace ${namespace}" +
+		" sets publishNotReadyAddresses. Sidecars route traffic to its pods" +
+		" before they are ready, including pods whose own sidecar hasn't" +
+		" received its configuration yet. Consider removing the setting unless" +
+		" the service is only used for peer discovery."
+	statefulSetKind = "StatefulSet"
+)
+
+// PublishNotReady implements Vetter interface
+type PublishNotReady struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+}
+
+// statefulSetService returns true if the Service is headless and selects pods
+// of a StatefulSet, which commonly publishes not ready pods for peer
+// discovery.
+func statefulSetService(s *corev1.Service, pods []*corev1.Pod) bool {
+	if s.Spec.ClusterIP != corev1.ClusterIPNone {
+		return false
+	}
+	for _, p := range util.SubsetPods(s, nil, pods) {
+		if owner := metav1.GetControllerOf(p); owner != nil && owner.Kind == statefulSetKind {
+			return true
+		}
+	}
+	return false
+}
+
+// createPublishNotReadyNotes generates a note for every Service which
+// publishes not ready addresses, except headless Services of StatefulSets.
+func createPublishNotReadyNotes(services []*corev1.Service,
+	pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		if !s.Spec.PublishNotReadyAddresses || statefulSetService(s, pods) {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    publishNotReadyNoteType,
+			Summary: publishNotReadySummary,
+			Msg:     publishNotReadyNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name": s.Name,
+				"namespace":    s.Namespace,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (p *PublishNotReady) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(p.nsLister, p.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			publishNotReadyNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(p.nsLister, p.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			publishNotReadyNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createPublishNotReadyNotes(services, pods), nil
+}
+
+// Info returns information about the vetter
+func (p *PublishNotReady) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "PublishNotReady" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *PublishNotReady {
+	return &PublishNotReady{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
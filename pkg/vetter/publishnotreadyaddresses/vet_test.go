@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package publishnotreadyaddresses
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var controller = true
+
+func service(name, clusterIP string, publishNotReady bool) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			ClusterIP:                clusterIP,
+			Selector:                 map[string]string{"app": name},
+			PublishNotReadyAddresses: publishNotReady,
+		},
+	}
+}
+
+func pod(app, ownerKind string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app + "-0",
+			Namespace: "default",
+			Labels:    map[string]string{"app": app},
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: ownerKind, Name: app, Controller: &controller},
+			},
+		},
+	}
+}
+
+var _ = Describe("Services publishing not ready addresses", func() {
+	It("creates zero notes if the flag is unset", func() {
+		services := []*corev1.Service{service("reviews", "10.0.0.1", false)}
+		pods := []*corev1.Pod{pod("reviews", "ReplicaSet")}
+		Expect(createPublishNotReadyNotes(services, pods)).To(HaveLen(0))
+	})
+
+	It("creates a note if the flag is set on a normal service", func() {
+		services := []*corev1.Service{
+			service("reviews", "10.0.0.1", true),
+			service("ratings", corev1.ClusterIPNone, true),
+		}
+		pods := []*corev1.Pod{pod("reviews", "StatefulSet"), pod("ratings", "ReplicaSet")}
+		notes := createPublishNotReadyNotes(services, pods)
+		expNotes := []*apiv1.Note{}
+		for _, name := range []string{"reviews", "ratings"} {
+			n := &apiv1.Note{
+				Type:    publishNotReadyNoteType,
+				Summary: publishNotReadySummary,
+				Msg:     publishNotReadyNoteMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"service_name": name,
+					"namespace":    "default",
+				},
+			}
+			n.Id = util.ComputeIDStable(n)
+			expNotes = append(expNotes, n)
+		}
+		Expect(notes).To(Equal(expNotes))
+	})
+
+	It("creates zero notes for the headless service of a StatefulSet", func() {
+		services := []*corev1.Service{service("zookeeper", corev1.ClusterIPNone, true)}
+		pods := []*corev1.Pod{pod("zookeeper", "StatefulSet")}
+		Expect(createPublishNotReadyNotes(services, pods)).To(HaveLen(0))
+	})
+})
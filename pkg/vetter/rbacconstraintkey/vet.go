@@ -0,0 +1,143 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rbacconstraintkey vets the constraints of ServiceRole resources and
+// generates notes if they use keys which are not supported attributes.
+package rbacconstraintkey
+
+import (
+	"strings"
+
+	rbacv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/rbac/v1alpha1"
+	rbacv1alpha1 "github.com/aspenmesh/istio-client-go/pkg/client/listers/rbac/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	vetterID              = "RbacConstraintKey"
+	unknownKeyNoteType    = "unknown-constraint-key"
+	unknownKeyNoteSummary = "Unknown constraint key - ${role_name}"
+	unknownKeyNoteMsg     = "The ServiceRole ${role_name} in namespace ${namespace}" +
+		" has a constraint with the unknown key ${key}. A constraint with an" +
+		" unknown key never matches, so the rule doesn't grant access. Consider" +
+		" using one of the supported keys: ${supported_keys}."
+)
+
+// SupportedConstraintKeys are the keys which ServiceRole constraints support.
+// A key ending in "[*]" must be followed by a name in brackets, e.g.
+// "request.headers[User-Agent]", and a key ending in ".*" matches any key
+// with that prefix.
+var SupportedConstraintKeys = []string{
+	"destination.ip",
+	"destination.port",
+	"destination.labels[*]",
+	"destination.name",
+	"destination.namespace",
+	"destination.user",
+	"request.headers[*]",
+	"experimental.envoy.filters.*",
+}
+
+// RbacConstraintKey implements Vetter interface
+type RbacConstraintKey struct {
+	srLister rbacv1alpha1.ServiceRoleLister
+}
+
+// keyMatches returns true if the key matches the supported key pattern.
+func keyMatches(key, pattern string) bool {
+	switch {
+	case strings.HasSuffix(pattern, "[*]"):
+		prefix := strings.TrimSuffix(pattern, "*]")
+		return strings.HasPrefix(key, prefix) && strings.HasSuffix(key, "]") &&
+			len(key) > len(prefix)+1
+	case strings.HasSuffix(pattern, ".*"):
+		prefix := strings.TrimSuffix(pattern, "*")
+		return strings.HasPrefix(key, prefix) && len(key) > len(prefix)
+	}
+	return key == pattern
+}
+
+// SupportedConstraintKey returns true if the key matches any of
+// SupportedConstraintKeys.
+func SupportedConstraintKey(key string) bool {
+	for _, pattern := range SupportedConstraintKeys {
+		if keyMatches(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// createUnknownKeyNotes generates a note for every unsupported constraint key
+// of the ServiceRoles.
+func createUnknownKeyNotes(roles []*rbacv1alpha1api.ServiceRole) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, r := range roles {
+		seen := map[string]bool{}
+		for _, rule := range r.Spec.GetRules() {
+			for _, c := range rule.GetConstraints() {
+				key := c.GetKey()
+				if seen[key] || SupportedConstraintKey(key) {
+					continue
+				}
+				seen[key] = true
+				notes = append(notes, &apiv1.Note{
+					Type:    unknownKeyNoteType,
+					Summary: unknownKeyNoteSummary,
+					Msg:     unknownKeyNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"role_name":      r.Name,
+						"namespace":      r.Namespace,
+						"key":            key,
+						"supported_keys": strings.Join(SupportedConstraintKeys, ", "),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (r *RbacConstraintKey) Vet() ([]*apiv1.Note, error) {
+	roles, err := r.srLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve ServiceRoles: %s", err)
+		return nil, err
+	}
+	return createUnknownKeyNotes(roles), nil
+}
+
+// Info returns information about the vetter
+func (r *RbacConstraintKey) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "RbacConstraintKey" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *RbacConstraintKey {
+	return &RbacConstraintKey{
+		srLister: factory.Istio().Rbac().V1alpha1().ServiceRoles().Lister(),
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rbacconstraintkey
+
+import (
+	"strings"
+
+	rbacv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/rbac/v1alpha1"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiorbacv1alpha1 "istio.io/api/rbac/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceRole(keys ...string) []*rbacv1alpha1api.ServiceRole {
+	constraints := []*istiorbacv1alpha1.AccessRule_Constraint{}
+	for _, k := range keys {
+		constraints = append(constraints, &istiorbacv1alpha1.AccessRule_Constraint{
+			Key:    k,
+			Values: []string{"v1"},
+		})
+	}
+	return []*rbacv1alpha1api.ServiceRole{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews-viewer", Namespace: "default"},
+			Spec: rbacv1alpha1api.ServiceRoleSpec{
+				ServiceRole: istiorbacv1alpha1.ServiceRole{
+					Rules: []*istiorbacv1alpha1.AccessRule{
+						{
+							Services:    []string{"reviews.default.svc.cluster.local"},
+							Methods:     []string{"GET"},
+							Constraints: constraints,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("ServiceRole constraint keys", func() {
+	It("creates zero notes for valid keys", func() {
+		roles := serviceRole("destination.labels[version]", "destination.port")
+		Expect(createUnknownKeyNotes(roles)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for header-indexed keys", func() {
+		roles := serviceRole("request.headers[X-Tenant]")
+		Expect(createUnknownKeyNotes(roles)).To(HaveLen(0))
+	})
+
+	It("creates a note for a typo'd key", func() {
+		expNote := &apiv1.Note{
+			Type:    unknownKeyNoteType,
+			Summary: unknownKeyNoteSummary,
+			Msg:     unknownKeyNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"role_name":      "reviews-viewer",
+				"namespace":      "default",
+				"key":            "destination.label[version]",
+				"supported_keys": strings.Join(SupportedConstraintKeys, ", "),
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		roles := serviceRole("destination.label[version]", "request.headers[]")
+		notes := createUnknownKeyNotes(roles)
+		Expect(notes).To(HaveLen(2))
+		Expect(notes[0]).To(Equal(expNote))
+		Expect(notes[1].Attr["key"]).To(Equal("request.headers[]"))
+	})
+})
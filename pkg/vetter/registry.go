@@ -0,0 +1,292 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vetter
+
+import (
+	"fmt"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+)
+
+const (
+	unknownVetterNoteType = "unknown-vetter"
+	unknownVetterSummary  = "Unknown vetter - ${vetter_name}"
+	unknownVetterMsg      = "The vetter ${vetter_name} was selected to ${selection}" +
+		" but no vetter with this ID is registered. The selection has no effect," +
+		" check the spelling of the vetter ID."
+	optInVetterNoteType = "opt-in-vetter-not-enabled"
+	optInVetterSummary  = "Opt-in vetter not enabled - ${vetter_name}"
+	optInVetterMsg      = "The vetter ${vetter_name} was selected to run but it is" +
+		" an opt-in vetter which isn't enabled. The selection has no effect," +
+		" enable the vetter with --enable-vetter ${vetter_name}."
+)
+
+// NoteSink receives the notes generated by vetters as soon as they are
+// available.
+type NoteSink interface {
+	Emit(n *apiv1.Note)
+}
+
+// VetterSink is a NoteSink which is also told when a vetter completes without
+// an error, with the number of notes emitted for it.
+type VetterSink interface {
+	NoteSink
+	VetterDone(id string, emitted int)
+}
+
+// BufferSink is a NoteSink which keeps every emitted note in order.
+type BufferSink struct {
+	notes []*apiv1.Note
+}
+
+// Emit appends the note to the buffer.
+func (b *BufferSink) Emit(n *apiv1.Note) {
+	b.notes = append(b.notes, n)
+}
+
+// Notes returns the notes emitted so far.
+func (b *BufferSink) Notes() []*apiv1.Note {
+	return b.notes
+}
+
+// VetterError is the error reported by a vetter during RunAll.
+type VetterError struct {
+	ID  string
+	Err error
+}
+
+func (e *VetterError) Error() string {
+	return fmt.Sprintf("vetter %q reported error: %s", e.ID, e.Err)
+}
+
+// RunErrors is returned by RunAll if one or more vetters reported an error.
+type RunErrors []*VetterError
+
+func (e RunErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Registry holds the vetters to run and the sinks their notes are emitted to.
+type Registry struct {
+	vetters  []Vetter
+	sinks    []NoteSink
+	only     []string
+	disabled []string
+	optIn    []string
+	failOn   apiv1.NoteLevel
+	// downgrade emits suppressed notes at level INFO instead of dropping
+	// them.
+	downgrade bool
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds vetters to the registry. Vetters run in registration order.
+func (r *Registry) Register(v ...Vetter) {
+	r.vetters = append(r.vetters, v...)
+}
+
+// RunOnly restricts RunAll to the vetters with the given IDs. Calling it
+// again adds to the IDs. IDs are matched case-insensitively.
+func (r *Registry) RunOnly(names ...string) {
+	r.only = append(r.only, names...)
+}
+
+// Disable excludes the vetters with the given IDs from RunAll, even if they
+// are selected by RunOnly. IDs are matched case-insensitively.
+func (r *Registry) Disable(names ...string) {
+	r.disabled = append(r.disabled, names...)
+}
+
+// OptIn records the IDs of opt-in vetters which aren't registered since they
+// weren't enabled, so that selecting them with RunOnly explains how to enable
+// them. IDs are matched case-insensitively.
+func (r *Registry) OptIn(names ...string) {
+	r.optIn = append(r.optIn, names...)
+}
+
+// FailOnLevel makes ExitCode return a non-zero code if a note is at or above
+// the level. NoteLevel_UNUSED, the default, always returns 0.
+func (r *Registry) FailOnLevel(level apiv1.NoteLevel) {
+	r.failOn = level
+}
+
+// ExitCode returns the process exit code for the notes returned by RunAll.
+// It is 0 unless FailOnLevel was set and the highest level of the notes meets
+// it, in which case it is 2 for ERROR notes and 1 otherwise.
+func (r *Registry) ExitCode(notes []*apiv1.Note) int {
+	if r.failOn == apiv1.NoteLevel_UNUSED {
+		return 0
+	}
+	max := util.MaxLevel(notes)
+	switch {
+	case max < r.failOn:
+		return 0
+	case max == apiv1.NoteLevel_ERROR:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// DowngradeSuppressed makes RunAll emit the notes suppressed with
+// util.SuppressAnnotation at level INFO instead of dropping them.
+func (r *Registry) DowngradeSuppressed(downgrade bool) {
+	r.downgrade = downgrade
+}
+
+// containsID returns true if the vetter ID is in names.
+func containsID(names []string, id string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// enabled returns true if the vetter is selected by RunOnly, if called, and
+// not disabled.
+func (r *Registry) enabled(v Vetter) bool {
+	id := v.Info().GetId()
+	if len(r.only) > 0 && !containsID(r.only, id) {
+		return false
+	}
+	return !containsID(r.disabled, id)
+}
+
+// unknownVetterNotes returns a warning note for every ID passed to RunOnly or
+// Disable which doesn't match a registered vetter. IDs of opt-in vetters
+// passed to RunOnly get a note explaining that they must be enabled, and
+// are ignored by Disable since they don't run anyway.
+func (r *Registry) unknownVetterNotes() []*apiv1.Note {
+	ids := make([]string, len(r.vetters))
+	for i, v := range r.vetters {
+		ids[i] = v.Info().GetId()
+	}
+	notes := []*apiv1.Note{}
+	for _, sel := range []struct {
+		names     []string
+		selection string
+	}{{r.only, "run"}, {r.disabled, "be disabled"}} {
+		for _, n := range sel.names {
+			if containsID(ids, n) {
+				continue
+			}
+			if containsID(r.optIn, n) {
+				if sel.selection == "run" {
+					notes = append(notes, &apiv1.Note{
+						Type:    optInVetterNoteType,
+						Summary: optInVetterSummary,
+						Msg:     optInVetterMsg,
+						Level:   apiv1.NoteLevel_WARNING,
+						Attr:    map[string]string{"vetter_name": n},
+					})
+				}
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    unknownVetterNoteType,
+				Summary: unknownVetterSummary,
+				Msg:     unknownVetterMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"vetter_name": n,
+					"selection":   sel.selection,
+				},
+			})
+		}
+	}
+	return notes
+}
+
+// AddSink adds a sink which receives the notes of every vetter run.
+func (r *Registry) AddSink(s NoteSink) {
+	r.sinks = append(r.sinks, s)
+}
+
+// Vetters returns the registered vetters.
+func (r *Registry) Vetters() []Vetter {
+	return r.vetters
+}
+
+// RunAll runs the registered vetters in order, skipping the vetters not
+// selected by RunOnly or Disable. A warning note is generated for every
+// selected ID without a registered vetter. The notes of each vetter are
+// emitted to the sinks as soon as the vetter returns, followed by a call to
+// VetterDone for sinks implementing VetterSink, and all notes are
+// returned once every vetter has run. Notes returned without an ID are
+// assigned one by util.ComputeIDStable. Notes whose ID is in
+// util.SuppressedNotes are dropped, or downgraded to INFO, see
+// DowngradeSuppressed. Only the annotations recorded by
+// util.RecordSuppressedNotes are honored. A vetter reporting an error does not stop the run,
+// the errors are returned as RunErrors.
+func (r *Registry) RunAll() ([]*apiv1.Note, error) {
+	buf := &BufferSink{}
+	var errs RunErrors
+	emit := func(notes []*apiv1.Note) int {
+		for _, n := range notes {
+			if n.Id == "" {
+				n.Id = util.ComputeIDStable(n)
+			}
+		}
+		// The suppressed notes are read after every vetter since it records
+		// the annotations of the objects it lists.
+		if r.downgrade {
+			notes = util.DowngradeSuppressed(notes, util.SuppressedNotes())
+		} else {
+			notes = util.FilterSuppressed(notes, util.SuppressedNotes())
+		}
+		for _, n := range notes {
+			buf.Emit(n)
+			for _, s := range r.sinks {
+				s.Emit(n)
+			}
+		}
+		return len(notes)
+	}
+	emit(r.unknownVetterNotes())
+	for _, v := range r.vetters {
+		if !r.enabled(v) {
+			continue
+		}
+		notes, err := v.Vet()
+		if err != nil {
+			errs = append(errs, &VetterError{ID: v.Info().GetId(), Err: err})
+			continue
+		}
+		emitted := emit(notes)
+		for _, s := range r.sinks {
+			if vs, ok := s.(VetterSink); ok {
+				vs.VetterDone(v.Info().GetId(), emitted)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return buf.Notes(), errs
+	}
+	return buf.Notes(), nil
+}
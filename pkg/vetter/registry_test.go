@@ -0,0 +1,238 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vetter
+
+import (
+	"errors"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeVetter struct {
+	id    string
+	notes []*apiv1.Note
+	err   error
+	calls int
+}
+
+func (f *fakeVetter) Vet() ([]*apiv1.Note, error) {
+	f.calls++
+	return f.notes, f.err
+}
+
+func (f *fakeVetter) Info() *apiv1.Info {
+	return &apiv1.Info{Id: f.id, Version: "0.1.0"}
+}
+
+type doneSink struct {
+	BufferSink
+	done map[string]int
+}
+
+func (d *doneSink) VetterDone(id string, emitted int) {
+	d.done[id] = emitted
+}
+
+var _ = Describe("Registry", func() {
+	var (
+		first, second, failing *fakeVetter
+		registry               *Registry
+	)
+
+	BeforeEach(func() {
+		first = &fakeVetter{
+			id: "First",
+			notes: []*apiv1.Note{
+				{Type: "first-a"},
+				{Type: "first-b"},
+			},
+		}
+		second = &fakeVetter{
+			id:    "Second",
+			notes: []*apiv1.Note{{Type: "second-a"}},
+		}
+		failing = &fakeVetter{id: "Failing", err: errors.New("lister failed")}
+		registry = NewRegistry()
+	})
+
+	It("emits each note to every sink in vetter order", func() {
+		registry.Register(first, failing, second)
+		sinkA, sinkB := &BufferSink{}, &BufferSink{}
+		registry.AddSink(sinkA)
+		registry.AddSink(sinkB)
+
+		notes, err := registry.RunAll()
+		expected := []*apiv1.Note{first.notes[0], first.notes[1], second.notes[0]}
+		Expect(notes).To(Equal(expected))
+		Expect(sinkA.Notes()).To(Equal(expected))
+		Expect(sinkB.Notes()).To(Equal(expected))
+		Expect(err).To(Equal(RunErrors{
+			&VetterError{ID: "Failing", Err: failing.err},
+		}))
+	})
+
+	It("tells vetter sinks about every vetter completing without error", func() {
+		empty := &fakeVetter{id: "Empty"}
+		registry.Register(first, failing, empty)
+		sink := &doneSink{done: map[string]int{}}
+		registry.AddSink(sink)
+		registry.RunAll()
+		Expect(sink.done).To(Equal(map[string]int{"First": 2, "Empty": 0}))
+	})
+
+	It("returns no error if every vetter succeeds", func() {
+		registry.Register(second)
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(1))
+	})
+	It("assigns an ID to notes returned without one", func() {
+		withID := &apiv1.Note{Id: "fixed", Type: "second-b"}
+		second.notes = append(second.notes, withID)
+		registry.Register(second)
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(2))
+		Expect(notes[0].Id).To(Equal(util.ComputeIDStable(&apiv1.Note{Type: "second-a"})))
+		Expect(notes[1].Id).To(Equal("fixed"))
+	})
+	It("never runs disabled vetters", func() {
+		registry.Register(first, failing, second)
+		registry.Disable("failing", "First")
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(Equal(second.notes))
+		Expect(first.calls).To(Equal(0))
+		Expect(failing.calls).To(Equal(0))
+		Expect(second.calls).To(Equal(1))
+	})
+
+	It("runs only the selected vetters which aren't disabled", func() {
+		registry.Register(first, failing, second)
+		registry.RunOnly("first", "second")
+		registry.Disable("second")
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(Equal(first.notes))
+		Expect(failing.calls).To(Equal(0))
+		Expect(second.calls).To(Equal(0))
+	})
+
+	It("generates a warning note for unknown vetter IDs", func() {
+		registry.Register(second)
+		registry.RunOnly("second", "mtlsprobe")
+		registry.Disable("applabel")
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(3))
+		Expect(notes[0].Type).To(Equal(unknownVetterNoteType))
+		Expect(notes[0].Level).To(Equal(apiv1.NoteLevel_WARNING))
+		Expect(notes[0].Attr).To(Equal(map[string]string{"vetter_name": "mtlsprobe", "selection": "run"}))
+		Expect(notes[1].Attr).To(Equal(map[string]string{"vetter_name": "applabel", "selection": "be disabled"}))
+		Expect(notes[2]).To(Equal(second.notes[0]))
+	})
+
+	It("explains that opt-in vetters must be enabled", func() {
+		registry.Register(second)
+		registry.OptIn("StaleIstioConfig")
+		registry.RunOnly("second", "staleistioconfig")
+		registry.Disable("StaleIstioConfig")
+		notes, err := registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(2))
+		Expect(notes[0].Type).To(Equal(optInVetterNoteType))
+		Expect(notes[0].Level).To(Equal(apiv1.NoteLevel_WARNING))
+		Expect(notes[0].Attr).To(Equal(map[string]string{"vetter_name": "staleistioconfig"}))
+		Expect(notes[1]).To(Equal(second.notes[0]))
+	})
+
+	Context("suppressed notes", func() {
+		BeforeEach(func() {
+			first.notes = []*apiv1.Note{
+				{Id: "keep", Type: "first-a", Level: apiv1.NoteLevel_WARNING},
+				{Id: "drop", Type: "first-b", Level: apiv1.NoteLevel_ERROR},
+			}
+			registry.Register(first)
+			util.SetSuppressedNotes([]string{"drop"})
+		})
+
+		AfterEach(func() {
+			util.SetSuppressedNotes(nil)
+		})
+
+		It("drops suppressed notes by default", func() {
+			sink := &BufferSink{}
+			registry.AddSink(sink)
+			notes, err := registry.RunAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notes).To(Equal([]*apiv1.Note{first.notes[0]}))
+			Expect(sink.Notes()).To(Equal(notes))
+		})
+
+		It("downgrades suppressed notes to INFO if enabled", func() {
+			registry.DowngradeSuppressed(true)
+			notes, err := registry.RunAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(notes).To(HaveLen(2))
+			Expect(notes[0].Level).To(Equal(apiv1.NoteLevel_WARNING))
+			Expect(notes[1].Id).To(Equal("drop"))
+			Expect(notes[1].Level).To(Equal(apiv1.NoteLevel_INFO))
+		})
+	})
+
+	Context("exit code", func() {
+		notes := func(l apiv1.NoteLevel) []*apiv1.Note {
+			return []*apiv1.Note{{Type: "a", Level: apiv1.NoteLevel_INFO}, {Type: "b", Level: l}}
+		}
+
+		It("is 0 by default", func() {
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_ERROR))).To(Equal(0))
+		})
+
+		It("is 0 without notes", func() {
+			registry.FailOnLevel(apiv1.NoteLevel_INFO)
+			Expect(registry.ExitCode(nil)).To(Equal(0))
+		})
+
+		It("is 0 below the threshold", func() {
+			registry.FailOnLevel(apiv1.NoteLevel_WARNING)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_INFO))).To(Equal(0))
+			registry.FailOnLevel(apiv1.NoteLevel_ERROR)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_WARNING))).To(Equal(0))
+		})
+
+		It("is non-zero at the threshold", func() {
+			registry.FailOnLevel(apiv1.NoteLevel_INFO)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_INFO))).To(Equal(1))
+			registry.FailOnLevel(apiv1.NoteLevel_WARNING)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_WARNING))).To(Equal(1))
+			registry.FailOnLevel(apiv1.NoteLevel_ERROR)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_ERROR))).To(Equal(2))
+		})
+
+		It("reflects the highest level above the threshold", func() {
+			registry.FailOnLevel(apiv1.NoteLevel_INFO)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_WARNING))).To(Equal(1))
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_ERROR))).To(Equal(2))
+			registry.FailOnLevel(apiv1.NoteLevel_WARNING)
+			Expect(registry.ExitCode(notes(apiv1.NoteLevel_ERROR))).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,183 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registryonlydestinationrule vets the DestinationRule resources for
+// external hosts when the mesh only allows traffic to registered services and
+// generates notes if no ServiceEntry registers the host.
+package registryonlydestinationrule
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "RegistryOnlyDestinationRule"
+	unregisteredHostNoteType = "registry-only-unregistered-host"
+	unregisteredHostSummary  = "DestinationRule for unregistered host - ${dr_name}"
+	unregisteredHostMsg      = "The DestinationRule ${dr_name} in namespace ${namespace}" +
+		" applies to the external host ${host}, but the mesh only allows traffic" +
+		" to registered services and no ServiceEntry registers the host. The" +
+		" DestinationRule has no effect and traffic to the host is blocked." +
+		" Consider adding a ServiceEntry for the host."
+)
+
+// RegistryOnlyDestinationRule implements Vetter interface
+type RegistryOnlyDestinationRule struct {
+	nsLister  v1.NamespaceLister
+	cmLister  v1.ConfigMapLister
+	svcLister v1.ServiceLister
+	drLister  netv1alpha3.DestinationRuleLister
+	seLister  netv1alpha3.ServiceEntryLister
+}
+
+// registryOnly returns true if the outbound traffic policy of the mesh is
+// REGISTRY_ONLY. Istio defaults to ALLOW_ANY if the policy isn't set.
+func registryOnly(mc *meshv1alpha1.MeshConfig) bool {
+	return mc.GetOutboundTrafficPolicy() != nil &&
+		mc.GetOutboundTrafficPolicy().GetMode() == meshv1alpha1.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY
+}
+
+// clusterHost returns true if the host used in a resource in the namespace
+// refers to a Kubernetes Service. Besides short names and FQDNs, the partially
+// qualified "<name>.<namespace>" and "<name>.<namespace>.svc" forms are
+// recognized.
+func clusterHost(r *util.HostResolver, host, namespace string) bool {
+	host = strings.ToLower(host)
+	if r.Resolve(host, namespace) != nil || strings.HasSuffix(host, ".svc") {
+		return true
+	}
+	fqdn, err := util.ConvertHostnameToFQDN(host, namespace)
+	if err == nil && strings.HasSuffix(fqdn, util.KubernetesDomainSuffix) {
+		return true
+	}
+	return len(strings.Split(host, ".")) == 2 &&
+		r.Resolve(host+util.KubernetesDomainSuffix, namespace) != nil
+}
+
+// externalHost returns true if the host isn't a Kubernetes Service.
+func externalHost(r *util.HostResolver, host, namespace string) bool {
+	return host != "" && host != "*" && !clusterHost(r, host, namespace)
+}
+
+// hostRegistered returns true if a host of a ServiceEntry visible in the
+// namespace, which may be a wildcard, covers the host.
+func hostRegistered(mc *meshv1alpha1.MeshConfig, host, namespace string,
+	seList []*v1alpha3.ServiceEntry) bool {
+	host = strings.ToLower(host)
+	for _, se := range seList {
+		if !util.ExportedTo(util.ServiceEntryExportTo(se, mc), se.Namespace, namespace) {
+			continue
+		}
+		for _, h := range se.Spec.GetHosts() {
+			h = strings.ToLower(h)
+			if h == host || (strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// createUnregisteredHostNotes generates a note for every DestinationRule for
+// an external host which no ServiceEntry visible in its namespace registers,
+// if the mesh is in REGISTRY_ONLY mode.
+func createUnregisteredHostNotes(mc *meshv1alpha1.MeshConfig, svcs []*corev1.Service,
+	drList []*v1alpha3.DestinationRule, seList []*v1alpha3.ServiceEntry) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	if !registryOnly(mc) {
+		return notes
+	}
+	r := util.NewHostResolver(svcs)
+	for _, dr := range drList {
+		host := dr.Spec.GetHost()
+		if !externalHost(r, host, dr.Namespace) || hostRegistered(mc, host, dr.Namespace, seList) {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    unregisteredHostNoteType,
+			Summary: unregisteredHostSummary,
+			Msg:     unregisteredHostMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":   dr.Name,
+				"namespace": dr.Namespace,
+				"host":      host,
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (r *RegistryOnlyDestinationRule) Vet() ([]*apiv1.Note, error) {
+	cm, err := util.GetMeshConfigMap(r.cmLister)
+	if err != nil {
+		return nil, err
+	}
+	mc, err := util.GetMeshConfig(cm)
+	if err != nil {
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(r.nsLister, r.drLister)
+	if err != nil {
+		return nil, err
+	}
+	// Services and ServiceEntries of every namespace are listed, since a
+	// DestinationRule may refer to a host defined outside the mesh
+	// namespaces.
+	svcs, err := r.svcLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Services: %s", err)
+		return nil, err
+	}
+	seList, err := r.seLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve ServiceEntries: %s", err)
+		return nil, err
+	}
+	return createUnregisteredHostNotes(mc, svcs, drList, seList), nil
+}
+
+// Info returns information about the vetter
+func (r *RegistryOnlyDestinationRule) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "RegistryOnlyDestinationRule" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *RegistryOnlyDestinationRule {
+	return &RegistryOnlyDestinationRule{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		cmLister:  factory.K8s().Core().V1().ConfigMaps().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+		seLister:  factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
+	}
+}
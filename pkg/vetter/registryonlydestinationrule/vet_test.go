@@ -0,0 +1,115 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryonlydestinationrule
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func meshConfig(mode meshv1alpha1.MeshConfig_OutboundTrafficPolicy_Mode) *meshv1alpha1.MeshConfig {
+	return &meshv1alpha1.MeshConfig{
+		OutboundTrafficPolicy: &meshv1alpha1.MeshConfig_OutboundTrafficPolicy{Mode: mode},
+	}
+}
+
+func serviceEntry(host string) []*v1alpha3.ServiceEntry {
+	return []*v1alpha3.ServiceEntry{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"},
+			Spec: v1alpha3.ServiceEntrySpec{
+				ServiceEntry: istiov1alpha3.ServiceEntry{Hosts: []string{host}},
+			},
+		},
+	}
+}
+
+var _ = Describe("DestinationRules for unregistered hosts", func() {
+	drList := []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{Host: "api.payments.example.com"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{Host: "reviews.default.svc.cluster.local"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews-partial", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{Host: "reviews.default"},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ratings-svc", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{Host: "ratings.bookinfo.svc"},
+			},
+		},
+	}
+	svcs := []*corev1.Service{
+		{ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"}},
+	}
+
+	It("creates zero notes in ALLOW_ANY mode", func() {
+		mc := meshConfig(meshv1alpha1.MeshConfig_OutboundTrafficPolicy_ALLOW_ANY)
+		Expect(createUnregisteredHostNotes(mc, svcs, drList, nil)).To(HaveLen(0))
+		Expect(createUnregisteredHostNotes(&meshv1alpha1.MeshConfig{}, svcs, drList, nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes in REGISTRY_ONLY mode with a ServiceEntry", func() {
+		mc := meshConfig(meshv1alpha1.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY)
+		Expect(createUnregisteredHostNotes(mc, svcs, drList, serviceEntry("api.payments.example.com"))).To(HaveLen(0))
+		Expect(createUnregisteredHostNotes(mc, svcs, drList, serviceEntry("*.example.com"))).To(HaveLen(0))
+	})
+
+	It("creates a note in REGISTRY_ONLY mode without a ServiceEntry", func() {
+		expNote := &apiv1.Note{
+			Type:    unregisteredHostNoteType,
+			Summary: unregisteredHostSummary,
+			Msg:     unregisteredHostMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"dr_name":   "payments",
+				"namespace": "default",
+				"host":      "api.payments.example.com",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		mc := meshConfig(meshv1alpha1.MeshConfig_OutboundTrafficPolicy_REGISTRY_ONLY)
+		notes := createUnregisteredHostNotes(mc, svcs, drList, serviceEntry("api.example.org"))
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+
+		By("having a ServiceEntry which isn't exported to the namespace")
+		seList := serviceEntry("api.payments.example.com")
+		seList[0].Namespace = "payments"
+		seList[0].Spec.ExportTo = []string{"."}
+		notes = createUnregisteredHostNotes(mc, svcs, drList, seList)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
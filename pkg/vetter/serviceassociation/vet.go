@@ -106,7 +106,7 @@ func (m *SvcAssociation) Vet() ([]*apiv1.Note, error) {
 	}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 
 	return notes, nil
@@ -0,0 +1,130 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serviceentryaddress vets the addresses of ServiceEntry resources and
+// generates notes if they are the ClusterIP of a service in the mesh.
+package serviceentryaddress
+
+import (
+	"net"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "ServiceEntryAddress"
+	addressConflictNoteType = "service-entry-address-conflict"
+	addressConflictSummary  = "ServiceEntry address conflicts with service - ${se_name}"
+	addressConflictMsg      = "The ServiceEntry ${se_name} in namespace ${namespace}" +
+		" declares the address ${address} which is the ClusterIP ${cluster_ip}" +
+		" of service ${service_name} in namespace ${service_namespace}. Traffic for" +
+		" the service is captured by the ServiceEntry. Consider removing the address" +
+		" from the ServiceEntry."
+)
+
+// ServiceEntryAddress implements Vetter interface
+type ServiceEntryAddress struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	seLister  netv1alpha3.ServiceEntryLister
+}
+
+// addressMatches returns true if the ServiceEntry address, an IP or a single
+// address CIDR, is the IP. Wider CIDR blocks only overlap the IP: the more
+// specific service VIP still wins, so they don't capture its traffic.
+func addressMatches(address string, ip net.IP) bool {
+	if a, cidr, err := net.ParseCIDR(address); err == nil {
+		ones, bits := cidr.Mask.Size()
+		return ones == bits && a.Equal(ip)
+	}
+	if a := net.ParseIP(address); a != nil {
+		return a.Equal(ip)
+	}
+	return false
+}
+
+// createAddressConflictNotes generates notes for the ServiceEntry addresses
+// which are the ClusterIP of a service. Headless services are skipped.
+func createAddressConflictNotes(services []*corev1.Service,
+	seList []*v1alpha3.ServiceEntry) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, se := range seList {
+		for _, address := range se.Spec.GetAddresses() {
+			for _, s := range services {
+				ip := net.ParseIP(s.Spec.ClusterIP)
+				if ip == nil || !addressMatches(address, ip) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    addressConflictNoteType,
+					Summary: addressConflictSummary,
+					Msg:     addressConflictMsg,
+					Level:   apiv1.NoteLevel_ERROR,
+					Attr: map[string]string{
+						"se_name":           se.Name,
+						"namespace":         se.Namespace,
+						"address":           address,
+						"cluster_ip":        s.Spec.ClusterIP,
+						"service_name":      s.Name,
+						"service_namespace": s.Namespace,
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (a *ServiceEntryAddress) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(a.nsLister, a.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			addressConflictNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	seList, err := util.ListServiceEntriesInMesh(a.nsLister, a.seLister)
+	if err != nil {
+		return nil, err
+	}
+	return createAddressConflictNotes(services, seList), nil
+}
+
+// Info returns information about the vetter
+func (a *ServiceEntryAddress) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ServiceEntryAddress" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ServiceEntryAddress {
+	return &ServiceEntryAddress{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		seLister:  factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceentryaddress
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceEntry(addresses ...string) []*v1alpha3.ServiceEntry {
+	return []*v1alpha3.ServiceEntry{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "default"},
+			Spec: v1alpha3.ServiceEntrySpec{
+				ServiceEntry: istiov1alpha3.ServiceEntry{
+					Hosts:     []string{"db.legacy.example.com"},
+					Addresses: addresses,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("ServiceEntry addresses", func() {
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "bookinfo"},
+			Spec:       corev1.ServiceSpec{ClusterIP: "10.96.12.7"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "headless", Namespace: "bookinfo"},
+			Spec:       corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone},
+		},
+	}
+
+	It("creates zero notes for a unique address", func() {
+		Expect(createAddressConflictNotes(services, serviceEntry("192.168.4.2", "10.96.13.0/24"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a CIDR block overlapping a ClusterIP", func() {
+		Expect(createAddressConflictNotes(services, serviceEntry("10.96.12.0/24", "10.0.0.0/8"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a hostname-only ServiceEntry", func() {
+		Expect(createAddressConflictNotes(services, serviceEntry())).To(HaveLen(0))
+	})
+
+	It("creates a note for an address colliding with a ClusterIP", func() {
+		expNote := &apiv1.Note{
+			Type:    addressConflictNoteType,
+			Summary: addressConflictSummary,
+			Msg:     addressConflictMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"se_name":           "legacy-db",
+				"namespace":         "default",
+				"address":           "10.96.12.7",
+				"cluster_ip":        "10.96.12.7",
+				"service_name":      "reviews",
+				"service_namespace": "bookinfo",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes := createAddressConflictNotes(services, serviceEntry("10.96.12.7"))
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+
+		expNote.Attr["address"] = "10.96.12.7/32"
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes = createAddressConflictNotes(services, serviceEntry("10.96.12.7/32"))
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serviceentryduplicateport vets the ports of ServiceEntry resources
+// and generates notes if a ServiceEntry declares the same port number more
+// than once.
+package serviceentryduplicateport
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "ServiceEntryDuplicatePort"
+	duplicatePortNoteType    = "service-entry-duplicate-port"
+	duplicatePortNoteSummary = "Duplicate ServiceEntry port - ${se_name}"
+	duplicatePortNoteMsg     = "The ServiceEntry ${se_name} in namespace ${namespace}" +
+		" declares port ${port} more than once, named ${port_names}. The proxy" +
+		" has a single listener per port, so only one of these port definitions" +
+		" is used. Consider removing the duplicates or moving them to different" +
+		" port numbers."
+)
+
+// ServiceEntryDuplicatePort implements Vetter interface
+type ServiceEntryDuplicatePort struct {
+	nsLister v1.NamespaceLister
+	seLister netv1alpha3.ServiceEntryLister
+}
+
+// createDuplicatePortNotes generates a note for every port number declared
+// more than once by a ServiceEntry.
+func createDuplicatePortNotes(seList []*v1alpha3.ServiceEntry) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, se := range seList {
+		// port number -> names of the ports declaring it
+		ports := map[uint32][]string{}
+		for _, p := range se.Spec.GetPorts() {
+			ports[p.GetNumber()] = append(ports[p.GetNumber()], p.GetName())
+		}
+		portList := make([]int, 0, len(ports))
+		for n, names := range ports {
+			if len(names) > 1 {
+				portList = append(portList, int(n))
+			}
+		}
+		sort.Ints(portList)
+		for _, n := range portList {
+			notes = append(notes, &apiv1.Note{
+				Type:    duplicatePortNoteType,
+				Summary: duplicatePortNoteSummary,
+				Msg:     duplicatePortNoteMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr: map[string]string{
+					"se_name":    se.Name,
+					"namespace":  se.Namespace,
+					"port":       strconv.Itoa(n),
+					"port_names": strings.Join(ports[uint32(n)], ", "),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (s *ServiceEntryDuplicatePort) Vet() ([]*apiv1.Note, error) {
+	seList, err := util.ListServiceEntriesInMesh(s.nsLister, s.seLister)
+	if err != nil {
+		return nil, err
+	}
+	return createDuplicatePortNotes(seList), nil
+}
+
+// Info returns information about the vetter
+func (s *ServiceEntryDuplicatePort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ServiceEntryDuplicatePort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ServiceEntryDuplicatePort {
+	return &ServiceEntryDuplicatePort{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		seLister: factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceentryduplicateport
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func serviceEntry(ports ...*istiov1alpha3.Port) []*v1alpha3.ServiceEntry {
+	return []*v1alpha3.ServiceEntry{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "payments", Namespace: "default"},
+			Spec: v1alpha3.ServiceEntrySpec{
+				ServiceEntry: istiov1alpha3.ServiceEntry{
+					Hosts: []string{"api.payments.example.com"},
+					Ports: ports,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("ServiceEntry duplicate ports", func() {
+	It("creates zero notes on empty lists", func() {
+		Expect(createDuplicatePortNotes(nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a single port", func() {
+		seList := serviceEntry(&istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"})
+		Expect(createDuplicatePortNotes(seList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for distinct ports", func() {
+		seList := serviceEntry(
+			&istiov1alpha3.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+			&istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+		)
+		Expect(createDuplicatePortNotes(seList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a duplicate port number", func() {
+		seList := serviceEntry(
+			&istiov1alpha3.Port{Number: 443, Name: "https", Protocol: "HTTPS"},
+			&istiov1alpha3.Port{Number: 80, Name: "http", Protocol: "HTTP"},
+			&istiov1alpha3.Port{Number: 443, Name: "tls", Protocol: "TLS"},
+		)
+		expNote := &apiv1.Note{
+			Type:    duplicatePortNoteType,
+			Summary: duplicatePortNoteSummary,
+			Msg:     duplicatePortNoteMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr: map[string]string{
+				"se_name":    "payments",
+				"namespace":  "default",
+				"port":       "443",
+				"port_names": "https, tls",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createDuplicatePortNotes(seList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
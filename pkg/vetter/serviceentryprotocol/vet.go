@@ -0,0 +1,158 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package serviceentryprotocol vets the services in the mesh which front hosts
+// registered with ServiceEntry resources and generates notes if the protocol of
+// a service port disagrees with the ServiceEntry port.
+package serviceentryprotocol
+
+import (
+	"strconv"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "ServiceEntryProtocol"
+	protocolMismatchNoteType = "service-entry-protocol-mismatch"
+	protocolMismatchSummary  = "Service and ServiceEntry protocol mismatch - ${service_name}"
+	protocolMismatchMsg      = "The service ${service_name} in namespace ${namespace}" +
+		" fronts host ${host} of the ServiceEntry ${se_name} in namespace" +
+		" ${se_namespace}. Port ${port} is named ${port_name} (protocol" +
+		" ${service_protocol}) but the ServiceEntry declares protocol ${se_protocol}." +
+		" Consider renaming the service port or updating the ServiceEntry port" +
+		" protocol so that both agree."
+)
+
+// ServiceEntryProtocol implements Vetter interface
+type ServiceEntryProtocol struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	seLister  netv1alpha3.ServiceEntryLister
+}
+
+// protocolFamily returns whether the proxy handles the protocol as HTTP or as
+// opaque TCP/UDP, which is what matters for the protocols to agree.
+func protocolFamily(protocol string) string {
+	switch strings.ToUpper(protocol) {
+	case "HTTP", "HTTP2", "GRPC":
+		return "HTTP"
+	case util.ServiceProtocolUDP:
+		return util.ServiceProtocolUDP
+	}
+	return "TCP"
+}
+
+// frontedHost returns the host of the ServiceEntry fronted by the service, or
+// the empty string. A service fronts a host if the host is the FQDN of the
+// service or if the service is an ExternalName service for the host.
+func frontedHost(s *corev1.Service, se *v1alpha3.ServiceEntry) string {
+	svcFqdn := s.Name + "." + s.Namespace + util.KubernetesDomainSuffix
+	for _, h := range se.Spec.GetHosts() {
+		if s.Spec.ExternalName != "" && h == s.Spec.ExternalName {
+			return h
+		}
+		if fqdn, err := util.ConvertHostnameToFQDN(h, se.Namespace); err == nil && fqdn == svcFqdn {
+			return h
+		}
+	}
+	return ""
+}
+
+// createProtocolMismatchNotes generates notes for the ports of the services
+// fronting ServiceEntry hosts whose protocol, inferred from the port name,
+// disagrees with the protocol of the ServiceEntry port with the same number.
+func createProtocolMismatchNotes(services []*corev1.Service,
+	seList []*v1alpha3.ServiceEntry) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		for _, se := range seList {
+			host := frontedHost(s, se)
+			if host == "" {
+				continue
+			}
+			for _, p := range s.Spec.Ports {
+				svcProtocol := util.ServicePortProtocol(p)
+				for _, sp := range se.Spec.GetPorts() {
+					if sp.GetNumber() != uint32(p.Port) ||
+						protocolFamily(sp.GetProtocol()) == protocolFamily(svcProtocol) {
+						continue
+					}
+					notes = append(notes, &apiv1.Note{
+						Type:    protocolMismatchNoteType,
+						Summary: protocolMismatchSummary,
+						Msg:     protocolMismatchMsg,
+						Level:   apiv1.NoteLevel_WARNING,
+						Attr: map[string]string{
+							"service_name":     s.Name,
+							"namespace":        s.Namespace,
+							"host":             host,
+							"se_name":          se.Name,
+							"se_namespace":     se.Namespace,
+							"port":             strconv.Itoa(int(p.Port)),
+							"port_name":        p.Name,
+							"service_protocol": svcProtocol,
+							"se_protocol":      sp.GetProtocol(),
+						},
+					})
+				}
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *ServiceEntryProtocol) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			protocolMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	seList, err := util.ListServiceEntriesInMesh(m.nsLister, m.seLister)
+	if err != nil {
+		return nil, err
+	}
+	return createProtocolMismatchNotes(services, seList), nil
+}
+
+// Info returns information about the vetter
+func (m *ServiceEntryProtocol) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ServiceEntryProtocol" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ServiceEntryProtocol {
+	return &ServiceEntryProtocol{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		seLister:  factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceentryprotocol
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func externalNameService(portName string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: "billing.example.com",
+			Ports:        []corev1.ServicePort{{Name: portName, Port: 5432}},
+		},
+	}
+}
+
+var _ = Describe("Service and ServiceEntry protocols", func() {
+	seList := []*v1alpha3.ServiceEntry{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "billing-db", Namespace: "default"},
+			Spec: v1alpha3.ServiceEntrySpec{
+				ServiceEntry: istiov1alpha3.ServiceEntry{
+					Hosts: []string{"billing.example.com"},
+					Ports: []*istiov1alpha3.Port{
+						{Number: 5432, Name: "tcp-postgres", Protocol: "TCP"},
+					},
+					Location:   istiov1alpha3.ServiceEntry_MESH_EXTERNAL,
+					Resolution: istiov1alpha3.ServiceEntry_DNS,
+				},
+			},
+		},
+	}
+
+	It("creates zero notes for agreeing protocols", func() {
+		svcs := []*corev1.Service{externalNameService("tcp-postgres")}
+		Expect(createProtocolMismatchNotes(svcs, seList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for services without a ServiceEntry", func() {
+		svc := externalNameService("http")
+		svc.Spec.ExternalName = "reports.example.com"
+		Expect(createProtocolMismatchNotes([]*corev1.Service{svc}, seList)).To(HaveLen(0))
+	})
+
+	It("creates a note for disagreeing protocols", func() {
+		svcs := []*corev1.Service{externalNameService("http")}
+		expNote := &apiv1.Note{
+			Type:    protocolMismatchNoteType,
+			Summary: protocolMismatchSummary,
+			Msg:     protocolMismatchMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name":     "billing",
+				"namespace":        "default",
+				"host":             "billing.example.com",
+				"se_name":          "billing-db",
+				"se_namespace":     "default",
+				"port":             "5432",
+				"port_name":        "http",
+				"service_protocol": "HTTP",
+				"se_protocol":      "TCP",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createProtocolMismatchNotes(svcs, seList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,136 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicemultiplecontrollers vets the pods selected by Services in
+// the mesh and generates notes if they are managed by several controllers.
+package servicemultiplecontrollers
+
+import (
+	"sort"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                       = "ServiceMultipleControllers"
+	multipleControllersNoteType    = "service-multiple-controllers"
+	multipleControllersNoteSummary = "Service backed by multiple controllers - ${service_name}"
+	multipleControllersNoteMsg     = "The pods selected by the service ${service_name}" +
+		" in namespace ${namespace} are managed by the controllers" +
+		" ${controller_list}. The service load balances across all of them," +
+		" even if they run different images. Consider narrowing the selector" +
+		" of the service unless this is intended."
+)
+
+// MultipleControllers implements Vetter interface
+type MultipleControllers struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+	rsLister  appsv1.ReplicaSetLister
+}
+
+// createMultipleControllersNotes generates a note for every Service whose
+// selected pods resolve to more than one controller. Bare pods aren't managed
+// by a controller and are ignored.
+func createMultipleControllersNotes(services []*corev1.Service, pods []*corev1.Pod,
+	rsLister appsv1.ReplicaSetLister) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		if len(s.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(s.Spec.Selector)
+		controllers := map[string]bool{}
+		for _, p := range pods {
+			if p.Namespace != s.Namespace || !selector.Matches(labels.Set(p.Labels)) {
+				continue
+			}
+			w := util.ResolveWorkload(p, rsLister)
+			if w.Kind == "Pod" {
+				continue
+			}
+			controllers[w.String()] = true
+		}
+		if len(controllers) < 2 {
+			continue
+		}
+		controllerList := make([]string, 0, len(controllers))
+		for c := range controllers {
+			controllerList = append(controllerList, c)
+		}
+		sort.Strings(controllerList)
+		notes = append(notes, &apiv1.Note{
+			Type:    multipleControllersNoteType,
+			Summary: multipleControllersNoteSummary,
+			Msg:     multipleControllersNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name":    s.Name,
+				"namespace":       s.Namespace,
+				"controller_list": strings.Join(controllerList, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MultipleControllers) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			multipleControllersNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(m.nsLister, m.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			multipleControllersNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createMultipleControllersNotes(services, pods, m.rsLister), nil
+}
+
+// Info returns information about the vetter
+func (m *MultipleControllers) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MultipleControllers" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MultipleControllers {
+	return &MultipleControllers{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		rsLister:  factory.K8s().Apps().V1().ReplicaSets().Lister(),
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicemultiplecontrollers
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var controller = true
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func pod(name string, owners []metav1.OwnerReference) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "reviews"},
+			OwnerReferences: owners,
+		},
+	}
+}
+
+var _ = Describe("Services backed by multiple controllers", func() {
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, rs := range []*appsv1.ReplicaSet{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-5d8f9",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "reviews")}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-5d8f9-old",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "reviews")}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-legacy-7c4b2",
+			Namespace:       "default",
+			OwnerReferences: controllerRef("Deployment", "reviews-legacy")}},
+	} {
+		indexer.Add(rs)
+	}
+	rsLister := appsv1listers.NewReplicaSetLister(indexer)
+
+	It("creates zero notes for pods of a single controller", func() {
+		pods := []*corev1.Pod{
+			pod("reviews-5d8f9-a", controllerRef("ReplicaSet", "reviews-5d8f9")),
+			pod("reviews-5d8f9-b", controllerRef("ReplicaSet", "reviews-5d8f9-old")),
+		}
+		Expect(createMultipleControllersNotes(services, pods, rsLister)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for bare pods", func() {
+		pods := []*corev1.Pod{
+			pod("reviews-5d8f9-a", controllerRef("ReplicaSet", "reviews-5d8f9")),
+			pod("reviews-debug", nil),
+			pod("reviews-debug-2", nil),
+		}
+		Expect(createMultipleControllersNotes(services, pods, rsLister)).To(HaveLen(0))
+	})
+
+	It("creates a note for pods of multiple controllers", func() {
+		expNote := &apiv1.Note{
+			Type:    multipleControllersNoteType,
+			Summary: multipleControllersNoteSummary,
+			Msg:     multipleControllersNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name":    "reviews",
+				"namespace":       "default",
+				"controller_list": "Deployment/reviews, Deployment/reviews-legacy",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		pods := []*corev1.Pod{
+			pod("reviews-5d8f9-a", controllerRef("ReplicaSet", "reviews-5d8f9")),
+			pod("reviews-legacy-7c4b2-a", controllerRef("ReplicaSet", "reviews-legacy-7c4b2")),
+		}
+		Expect(createMultipleControllersNotes(services, pods, rsLister)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicenameapplabel vets the Services in the mesh and generates
+// notes if the name of a Service differs from the app label of its pods.
+//
+// The vetter is opt-in, it only runs if enabled with the --enable-vetter
+// flag.
+package servicenameapplabel
+
+import (
+	"sort"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "ServiceNameAppLabel"
+	nameMismatchNoteType    = "service-name-app-label"
+	nameMismatchNoteSummary = "Service name differs from app label - ${service_name}"
+	nameMismatchNoteMsg     = "The name of the service ${service_name} in namespace" +
+		" ${namespace} differs from the app label ${app_list} of its pods." +
+		" Istio dashboards assume the service name matches the app label of its" +
+		" workload. Consider renaming the service or relabeling its pods."
+)
+
+// ServiceNameAppLabel implements Vetter interface
+type ServiceNameAppLabel struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+}
+
+// createNameMismatchNotes generates a note for every Service whose name is
+// not the app label of any of its pods. Services without app-labeled pods
+// are skipped.
+func createNameMismatchNotes(svcs []*corev1.Service, pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range svcs {
+		apps := map[string]bool{}
+		for _, p := range util.SubsetPods(s, nil, pods) {
+			if app, ok := p.Labels[util.IstioAppLabel]; ok {
+				apps[app] = true
+			}
+		}
+		if len(apps) == 0 || apps[s.Name] {
+			continue
+		}
+		appList := []string{}
+		for app := range apps {
+			appList = append(appList, app)
+		}
+		sort.Strings(appList)
+		notes = append(notes, &apiv1.Note{
+			Type:    nameMismatchNoteType,
+			Summary: nameMismatchNoteSummary,
+			Msg:     nameMismatchNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"service_name": s.Name,
+				"namespace":    s.Namespace,
+				"app_list":     strings.Join(appList, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *ServiceNameAppLabel) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			nameMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(m.nsLister, m.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			nameMismatchNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createNameMismatchNotes(svcs, pods), nil
+}
+
+// Info returns information about the vetter
+func (m *ServiceNameAppLabel) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "ServiceNameAppLabel" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *ServiceNameAppLabel {
+	return &ServiceNameAppLabel{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
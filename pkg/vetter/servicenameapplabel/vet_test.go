@@ -0,0 +1,80 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicenameapplabel
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func service(name string) []*corev1.Service {
+	return []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"tier": "web"}},
+		},
+	}
+}
+
+var _ = Describe("Service name and app label", func() {
+	pods := []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "frontend-1",
+				Namespace: "default",
+				Labels:    map[string]string{"tier": "web", "app": "frontend"},
+			},
+		},
+	}
+
+	It("creates zero notes if the name matches the app label", func() {
+		Expect(createNameMismatchNotes(service("frontend"), pods)).To(HaveLen(0))
+	})
+
+	It("creates zero notes if no pod has an app label", func() {
+		unlabeled := []*corev1.Pod{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "frontend-1",
+					Namespace: "default",
+					Labels:    map[string]string{"tier": "web"},
+				},
+			},
+		}
+		Expect(createNameMismatchNotes(service("frontend-svc"), unlabeled)).To(HaveLen(0))
+	})
+
+	It("creates a note if the name differs from the app label", func() {
+		expNote := &apiv1.Note{
+			Type:    nameMismatchNoteType,
+			Summary: nameMismatchNoteSummary,
+			Msg:     nameMismatchNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"service_name": "frontend-svc",
+				"namespace":    "default",
+				"app_list":     "frontend",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createNameMismatchNotes(service("frontend-svc"), pods)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
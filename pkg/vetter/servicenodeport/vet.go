@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicenodeport vets the ports of the services in the mesh and
+// generates notes if they are exposed on node ports.
+package servicenodeport
+
+import (
+	"strconv"
+	"strings"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "ServiceNodePort"
+	serviceNodePortNoteType = "service-node-port"
+	serviceNodePortSummary  = "Node port in mesh service - ${service_name}"
+	serviceNodePortMsg      = "The service ${service_name} of type ${service_type} in" +
+		" namespace ${namespace} is exposed on the node port(s) ${node_ports}," +
+		" whether set in its manifest or allocated by the API server. Traffic" +
+		" entering through a node port bypasses the mesh ingress. Consider" +
+		" exposing the service through the Istio ingress gateway instead."
+)
+
+// SvcNodePort implements Vetter interface
+type SvcNodePort struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+}
+
+// createNodePortNotes generates notes for the services which have a node port
+// on any of their ports, regardless of the service type. The service doesn't
+// record whether a node port was allocated by the API server, so allocated
+// and explicit node ports are reported alike.
+func createNodePortNotes(services []*corev1.Service) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		var nodePorts []string
+		for _, p := range s.Spec.Ports {
+			if p.NodePort != 0 {
+				nodePorts = append(nodePorts, strconv.Itoa(int(p.NodePort)))
+			}
+		}
+		if len(nodePorts) == 0 {
+			continue
+		}
+		svcType := s.Spec.Type
+		if svcType == "" {
+			svcType = corev1.ServiceTypeClusterIP
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    serviceNodePortNoteType,
+			Summary: serviceNodePortSummary,
+			Msg:     serviceNodePortMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"service_name": s.Name,
+				"namespace":    s.Namespace,
+				"service_type": string(svcType),
+				"node_ports":   strings.Join(nodePorts, ", ")}})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *SvcNodePort) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			serviceNodePortNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	return createNodePortNotes(services), nil
+}
+
+// Info returns information about the vetter
+func (m *SvcNodePort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "SvcNodePort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *SvcNodePort {
+	return &SvcNodePort{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+	}
+}
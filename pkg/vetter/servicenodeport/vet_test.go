@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicenodeport
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func service(name string, svcType corev1.ServiceType, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: corev1.ServiceSpec{
+			Type:  svcType,
+			Ports: ports,
+		},
+	}
+}
+
+func nodePortNote(name, svcType, nodePorts string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    serviceNodePortNoteType,
+		Summary: serviceNodePortSummary,
+		Msg:     serviceNodePortMsg,
+		Level:   apiv1.NoteLevel_INFO,
+		Attr: map[string]string{
+			"service_name": name,
+			"namespace":    "default",
+			"service_type": svcType,
+			"node_ports":   nodePorts,
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("Service node ports", func() {
+	It("creates zero notes for services without a node port", func() {
+		svcs := []*corev1.Service{
+			service("reviews", corev1.ServiceTypeClusterIP,
+				corev1.ServicePort{Name: "http", Port: 9080}),
+		}
+		Expect(createNodePortNotes(svcs)).To(HaveLen(0))
+	})
+
+	It("creates a note for the node ports of a NodePort service", func() {
+		svcs := []*corev1.Service{
+			service("productpage", corev1.ServiceTypeNodePort,
+				corev1.ServicePort{Name: "http", Port: 9080, NodePort: 30080},
+				corev1.ServicePort{Name: "https", Port: 9443, NodePort: 30443}),
+		}
+		Expect(createNodePortNotes(svcs)).To(Equal([]*apiv1.Note{
+			nodePortNote("productpage", "NodePort", "30080, 30443"),
+		}))
+	})
+
+	It("creates a note for a stray node port on a ClusterIP service", func() {
+		svcs := []*corev1.Service{
+			service("ratings", "",
+				corev1.ServicePort{Name: "http", Port: 9080, NodePort: 31000}),
+		}
+		Expect(createNodePortNotes(svcs)).To(Equal([]*apiv1.Note{
+			nodePortNote("ratings", "ClusterIP", "31000"),
+		}))
+	})
+})
@@ -77,7 +77,7 @@ func (m *SvcPortPrefix) Vet() ([]*apiv1.Note, error) {
 	}
 
 	for i := range notes {
-		notes[i].Id = util.ComputeID(notes[i])
+		notes[i].Id = util.ComputeIDStable(notes[i])
 	}
 
 	return notes, nil
@@ -0,0 +1,79 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package serviceportprefix
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("Service port prefix notes", func() {
+	var p *SvcPortPrefix
+
+	BeforeEach(func() {
+		nsIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		nsIndexer.Add(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   "default",
+			Labels: map[string]string{"istio-injection": "enabled"}}})
+		svcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		svcIndexer.Add(&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "web", Port: 9080}},
+			},
+		})
+		p = &SvcPortPrefix{
+			nsLister:  v1.NewNamespaceLister(nsIndexer),
+			svcLister: v1.NewServiceLister(svcIndexer),
+		}
+	})
+
+	AfterEach(func() {
+		util.SetSuppressedNotes(nil)
+	})
+
+	It("can be suppressed by the stable ID of the note", func() {
+		id := util.ComputeIDStable(&apiv1.Note{
+			Type:    servicePortPrefixNoteType,
+			Summary: servicePortPrefixSummary,
+			Attr: map[string]string{
+				"service_name":  "reviews",
+				"namespace":     "default",
+				"port_prefixes": "web",
+			},
+		})
+		notes, err := p.Vet()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Id).To(Equal(id))
+
+		registry := vetter.NewRegistry()
+		registry.Register(p)
+		util.SetSuppressedNotes([]string{id})
+		notes, err = registry.RunAll()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notes).To(HaveLen(0))
+	})
+})
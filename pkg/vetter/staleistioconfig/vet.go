@@ -0,0 +1,164 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package staleistioconfig vets the creation time of Istio config resources
+// and generates notes for resources created well before the Istio control
+// plane, whose schema the control plane may have deprecated.
+//
+// The vetter is opt-in, it only runs if enabled with the --enable-vetter
+// flag.
+package staleistioconfig
+
+import (
+	"time"
+
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "StaleIstioConfig"
+	staleConfigNoteType    = "istio-config-predates-control-plane"
+	staleConfigNoteSummary = "Istio config predates the control plane - ${kind} ${name}"
+	staleConfigNoteMsg     = "The ${kind} ${name} in namespace ${namespace} was" +
+		" created at ${created}, well before the Istio control plane ${deployment}" +
+		" created at ${control_plane_created}. It may use fields deprecated by the" +
+		" current control plane. Consider reviewing it against the current" +
+		" Istio configuration reference."
+
+	// staleConfigMargin is how long before the control plane a resource must
+	// have been created to generate a note. It skips config applied while
+	// installing the control plane.
+	staleConfigMargin = 7 * 24 * time.Hour
+)
+
+// configResource is an Istio config resource of the given kind.
+type configResource struct {
+	kind string
+	meta metav1.ObjectMeta
+}
+
+// StaleIstioConfig implements Vetter interface
+type StaleIstioConfig struct {
+	nsLister     v1.NamespaceLister
+	deployLister appsv1listers.DeploymentLister
+	vsLister     netv1alpha3.VirtualServiceLister
+	drLister     netv1alpha3.DestinationRuleLister
+	seLister     netv1alpha3.ServiceEntryLister
+	gwLister     netv1alpha3.GatewayLister
+}
+
+// createStaleConfigNotes generates a note for every resource created more than
+// staleConfigMargin before the control plane Deployment. No notes are
+// generated without a control plane Deployment.
+func createStaleConfigNotes(cp *appsv1.Deployment, resources []configResource) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	if cp == nil {
+		return notes
+	}
+	cutoff := cp.CreationTimestamp.Add(-staleConfigMargin)
+	for _, r := range resources {
+		if !r.meta.CreationTimestamp.Time.Before(cutoff) {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    staleConfigNoteType,
+			Summary: staleConfigNoteSummary,
+			Msg:     staleConfigNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"kind":                  r.kind,
+				"name":                  r.meta.Name,
+				"namespace":             r.meta.Namespace,
+				"created":               r.meta.CreationTimestamp.UTC().Format(time.RFC3339),
+				"deployment":            cp.Name,
+				"control_plane_created": cp.CreationTimestamp.UTC().Format(time.RFC3339),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (s *StaleIstioConfig) Vet() ([]*apiv1.Note, error) {
+	cp, err := util.ControlPlaneDeployment(s.deployLister)
+	if err != nil {
+		return nil, err
+	}
+	if cp == nil {
+		return []*apiv1.Note{}, nil
+	}
+	resources := []configResource{}
+	vsList, err := util.ListVirtualServicesInMesh(s.nsLister, s.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	for _, vs := range vsList {
+		resources = append(resources, configResource{"VirtualService", vs.ObjectMeta})
+	}
+	drList, err := util.ListDestinationRulesInMesh(s.nsLister, s.drLister)
+	if err != nil {
+		return nil, err
+	}
+	for _, dr := range drList {
+		resources = append(resources, configResource{"DestinationRule", dr.ObjectMeta})
+	}
+	seList, err := util.ListServiceEntriesInMesh(s.nsLister, s.seLister)
+	if err != nil {
+		return nil, err
+	}
+	for _, se := range seList {
+		resources = append(resources, configResource{"ServiceEntry", se.ObjectMeta})
+	}
+	gwList, err := s.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	for _, gw := range gwList {
+		resources = append(resources, configResource{"Gateway", gw.ObjectMeta})
+	}
+	return createStaleConfigNotes(cp, resources), nil
+}
+
+// Info returns information about the vetter
+func (s *StaleIstioConfig) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "StaleIstioConfig" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *StaleIstioConfig {
+	return &StaleIstioConfig{
+		nsLister:     factory.K8s().Core().V1().Namespaces().Lister(),
+		deployLister: factory.K8s().Apps().V1().Deployments().Lister(),
+		vsLister:     factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		drLister:     factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+		seLister:     factory.Istio().Networking().V1alpha3().ServiceEntries().Lister(),
+		gwLister:     factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
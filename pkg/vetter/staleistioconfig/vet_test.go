@@ -0,0 +1,84 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package staleistioconfig
+
+import (
+	"time"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var installed = time.Date(2020, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+func controlPlane() *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "istiod",
+			Namespace:         "istio-system",
+			CreationTimestamp: metav1.NewTime(installed),
+		},
+	}
+}
+
+func resource(created time.Time) []configResource {
+	return []configResource{
+		{
+			kind: "VirtualService",
+			meta: metav1.ObjectMeta{
+				Name:              "reviews",
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(created),
+			},
+		},
+	}
+}
+
+var _ = Describe("Stale Istio config", func() {
+	It("creates zero notes for recent config", func() {
+		Expect(createStaleConfigNotes(controlPlane(), resource(installed.Add(time.Hour)))).To(HaveLen(0))
+		Expect(createStaleConfigNotes(controlPlane(), resource(installed.Add(-time.Hour)))).To(HaveLen(0))
+	})
+
+	It("creates a note for config created well before the control plane", func() {
+		notes := createStaleConfigNotes(controlPlane(), resource(installed.AddDate(-1, 0, 0)))
+		expNote := &apiv1.Note{
+			Type:    staleConfigNoteType,
+			Summary: staleConfigNoteSummary,
+			Msg:     staleConfigNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"kind":                  "VirtualService",
+				"name":                  "reviews",
+				"namespace":             "default",
+				"created":               "2019-03-01T12:00:00Z",
+				"deployment":            "istiod",
+				"control_plane_created": "2020-03-01T12:00:00Z",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates zero notes without a control plane deployment", func() {
+		Expect(createStaleConfigNotes(nil, resource(installed.AddDate(-1, 0, 0)))).To(HaveLen(0))
+	})
+})
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package strictmtlsnonmeshsource vets the source workloads of VirtualService
+// routes and generates notes if workloads without a sidecar are routed to
+// destinations which enforce STRICT mTLS.
+package strictmtlsnonmeshsource
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	authv1alpha1 "github.com/aspenmesh/istio-client-go/pkg/client/listers/authentication/v1alpha1"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	"github.com/golang/glog"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "StrictMtlsNonMeshSource"
+	nonMeshSourceNoteType    = "non-mesh-source-strict-mtls"
+	nonMeshSourceNoteSummary = "Non-mesh source routed to STRICT mTLS destination - ${vs_name}"
+	nonMeshSourceNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" routes traffic from pod(s) ${pod_list} which are not in the mesh to" +
+		" ${destination} which enforces STRICT mTLS. Plaintext requests from these" +
+		" pods will be rejected. Consider injecting the sidecar into the source pods" +
+		" or setting the mTLS mode of the destination to PERMISSIVE."
+)
+
+// StrictMtlsNonMeshSource implements Vetter interface
+type StrictMtlsNonMeshSource struct {
+	nsLister  v1.NamespaceLister
+	podLister v1.PodLister
+	vsLister  netv1alpha3.VirtualServiceLister
+	apLister  authv1alpha1.PolicyLister
+	mpLister  authv1alpha1.MeshPolicyLister
+}
+
+// nonMeshSources returns the sorted names of the pods in the namespace which
+// match any of the source labels of the route and have no sidecar.
+func nonMeshSources(route *istiov1alpha3.HTTPRoute, namespace string,
+	pods []*corev1.Pod) []string {
+	selectors := []labels.Selector{}
+	for _, m := range route.GetMatch() {
+		if len(m.GetSourceLabels()) > 0 {
+			selectors = append(selectors, labels.SelectorFromSet(m.GetSourceLabels()))
+		}
+	}
+	names := []string{}
+	if len(selectors) == 0 {
+		return names
+	}
+	for _, p := range pods {
+		if p.Namespace != namespace || util.SidecarInjected(p) {
+			continue
+		}
+		for _, s := range selectors {
+			if s.Matches(labels.Set(p.Labels)) {
+				names = append(names, p.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// createNonMeshSourceNotes generates notes for the HTTP routes of the
+// VirtualServices whose source labels select pods without a sidecar and whose
+// destinations enforce STRICT mTLS.
+func createNonMeshSourceNotes(pods []*corev1.Pod, vsList []*v1alpha3.VirtualService,
+	ap *mtlspolicyutil.AuthPolicies) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for _, route := range vs.Spec.GetHttp() {
+			sources := nonMeshSources(route, vs.Namespace, pods)
+			if len(sources) == 0 {
+				continue
+			}
+			for _, dw := range route.GetRoute() {
+				d := dw.GetDestination()
+				if d == nil || d.GetHost() == "" {
+					continue
+				}
+				fqdn, err := util.ConvertHostnameToFQDN(d.GetHost(), vs.Namespace)
+				if err != nil || !ap.IsStrict(fqdn, d.GetPort().GetNumber()) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    nonMeshSourceNoteType,
+					Summary: nonMeshSourceNoteSummary,
+					Msg:     nonMeshSourceNoteMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"vs_name":     vs.Name,
+						"namespace":   vs.Namespace,
+						"pod_list":    strings.Join(sources, ", "),
+						"destination": d.GetHost(),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (s *StrictMtlsNonMeshSource) Vet() ([]*apiv1.Note, error) {
+	pods, err := s.podLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Pods: %s", err)
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(s.nsLister, s.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	policyList, err := s.apLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Policies: %s", err)
+		return nil, err
+	}
+	meshPolicyList, err := s.mpLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve MeshPolicies: %s", err)
+		return nil, err
+	}
+	authPolicies, err := mtlspolicyutil.LoadAuthPolicies(policyList, meshPolicyList)
+	if err != nil {
+		glog.Errorln("Unable to load auth policies")
+		return nil, err
+	}
+	return createNonMeshSourceNotes(pods, vsList, authPolicies), nil
+}
+
+// Info returns information about the vetter
+func (s *StrictMtlsNonMeshSource) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "StrictMtlsNonMeshSource" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *StrictMtlsNonMeshSource {
+	return &StrictMtlsNonMeshSource{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		apLister:  factory.Istio().Authentication().V1alpha1().Policies().Lister(),
+		mpLister:  factory.Istio().Authentication().V1alpha1().MeshPolicies().Lister(),
+	}
+}
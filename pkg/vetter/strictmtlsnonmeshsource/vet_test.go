@@ -0,0 +1,142 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package strictmtlsnonmeshsource
+
+import (
+	authv1alpha1api "github.com/aspenmesh/istio-client-go/pkg/apis/authentication/v1alpha1"
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	mtlspolicyutil "github.com/aspenmesh/istio-vet/pkg/vetter/util/mtlspolicy"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istioauthv1alpha1 "istio.io/api/authentication/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func namespacePolicy(namespace string, mode istioauthv1alpha1.MutualTls_Mode) *authv1alpha1api.Policy {
+	return &authv1alpha1api.Policy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace},
+		Spec: authv1alpha1api.PolicySpec{
+			Policy: istioauthv1alpha1.Policy{
+				Peers: []*istioauthv1alpha1.PeerAuthenticationMethod{
+					{
+						Params: &istioauthv1alpha1.PeerAuthenticationMethod_Mtls{
+							Mtls: &istioauthv1alpha1.MutualTls{Mode: mode},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func pod(name string, injected bool) *corev1.Pod {
+	p := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{"app": "frontend"},
+		},
+	}
+	if injected {
+		p.Annotations = map[string]string{util.IstioInitializerPodAnnotation: "{}"}
+		p.Spec.Containers = []corev1.Container{{Name: util.IstioProxyContainerName}}
+	}
+	return p
+}
+
+func sourceRoutedVirtualService(host string) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "frontend-routes", Namespace: "default"},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: []string{host},
+				Http: []*istiov1alpha3.HTTPRoute{
+					{
+						Match: []*istiov1alpha3.HTTPMatchRequest{
+							{SourceLabels: map[string]string{"app": "frontend"}},
+						},
+						Route: []*istiov1alpha3.HTTPRouteDestination{
+							{Destination: &istiov1alpha3.Destination{Host: host}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Non-mesh sources routed to STRICT mTLS destinations", func() {
+	var ap *mtlspolicyutil.AuthPolicies
+
+	BeforeEach(func() {
+		var err error
+		ap, err = mtlspolicyutil.LoadAuthPolicies([]*authv1alpha1api.Policy{
+			namespacePolicy("backend", istioauthv1alpha1.MutualTls_STRICT),
+			namespacePolicy("legacy", istioauthv1alpha1.MutualTls_PERMISSIVE),
+		}, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("creates zero notes on empty lists", func() {
+		Expect(createNonMeshSourceNotes(nil, nil, ap)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for meshed sources of a STRICT destination", func() {
+		pods := []*corev1.Pod{pod("frontend-1", true)}
+		vsList := []*v1alpha3.VirtualService{
+			sourceRoutedVirtualService("api.backend.svc.cluster.local"),
+		}
+		Expect(createNonMeshSourceNotes(pods, vsList, ap)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for non-mesh sources of a PERMISSIVE destination", func() {
+		pods := []*corev1.Pod{pod("frontend-1", false)}
+		vsList := []*v1alpha3.VirtualService{
+			sourceRoutedVirtualService("api.legacy.svc.cluster.local"),
+		}
+		Expect(createNonMeshSourceNotes(pods, vsList, ap)).To(HaveLen(0))
+	})
+
+	It("creates a note for non-mesh sources of a STRICT destination", func() {
+		pods := []*corev1.Pod{
+			pod("frontend-2", false),
+			pod("frontend-1", false),
+			pod("frontend-3", true),
+		}
+		vsList := []*v1alpha3.VirtualService{
+			sourceRoutedVirtualService("api.backend.svc.cluster.local"),
+		}
+		expNote := &apiv1.Note{
+			Type:    nonMeshSourceNoteType,
+			Summary: nonMeshSourceNoteSummary,
+			Msg:     nonMeshSourceNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":     "frontend-routes",
+				"namespace":   "default",
+				"pod_list":    "frontend-1, frontend-2",
+				"destination": "api.backend.svc.cluster.local",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createNonMeshSourceNotes(pods, vsList, ap)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
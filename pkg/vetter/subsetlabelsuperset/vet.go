@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subsetlabelsuperset vets the subsets of DestinationRule resources
+// and generates notes if the labels of a subset are a strict superset of the
+// labels of another subset.
+package subsetlabelsuperset
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "SubsetLabelSuperset"
+	subsetSupersetNoteType = "subset-label-superset"
+	subsetSupersetSummary  = "Overlapping subsets - ${dr_name}"
+	subsetSupersetMsg      = "The subset ${subset} of DestinationRule ${dr_name} in" +
+		" namespace ${namespace} selects a subset of the endpoints of subset" +
+		" ${parent_subset}, since its labels are a superset of the labels of" +
+		" ${parent_subset}. Routes weighted across both subsets send traffic to" +
+		" the endpoints of ${subset} twice. Consider using disjoint labels for" +
+		" the subsets."
+)
+
+// SubsetLabelSuperset implements Vetter interface
+type SubsetLabelSuperset struct {
+	nsLister v1.NamespaceLister
+	drLister netv1alpha3.DestinationRuleLister
+}
+
+// strictSuperset returns true if a contains every label of b and more.
+func strictSuperset(a, b map[string]string) bool {
+	if len(a) <= len(b) {
+		return false
+	}
+	for k, v := range b {
+		if av, ok := a[k]; !ok || av != v {
+			return false
+		}
+	}
+	return true
+}
+
+// createSubsetSupersetNotes generates a note for every pair of subsets of a
+// DestinationRule where the labels of one are a strict superset of the labels
+// of the other.
+func createSubsetSupersetNotes(drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, dr := range drList {
+		subsets := dr.Spec.GetSubsets()
+		for _, a := range subsets {
+			for _, b := range subsets {
+				if a == b || !strictSuperset(a.GetLabels(), b.GetLabels()) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    subsetSupersetNoteType,
+					Summary: subsetSupersetSummary,
+					Msg:     subsetSupersetMsg,
+					Level:   apiv1.NoteLevel_INFO,
+					Attr: map[string]string{
+						"dr_name":       dr.Name,
+						"namespace":     dr.Namespace,
+						"subset":        a.GetName(),
+						"parent_subset": b.GetName(),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (s *SubsetLabelSuperset) Vet() ([]*apiv1.Note, error) {
+	drList, err := util.ListDestinationRulesInMesh(s.nsLister, s.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createSubsetSupersetNotes(drList), nil
+}
+
+// Info returns information about the vetter
+func (s *SubsetLabelSuperset) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "SubsetLabelSuperset" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *SubsetLabelSuperset {
+	return &SubsetLabelSuperset{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		drLister: factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subsetlabelsuperset
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func destinationRule(subsets ...*istiov1alpha3.Subset) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host:    "reviews",
+					Subsets: subsets,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Subset label supersets", func() {
+	v1 := &istiov1alpha3.Subset{Name: "v1", Labels: map[string]string{"version": "v1"}}
+
+	It("creates zero notes for disjoint subsets", func() {
+		v2 := &istiov1alpha3.Subset{Name: "v2", Labels: map[string]string{"version": "v2"}}
+		Expect(createSubsetSupersetNotes(destinationRule(v1, v2))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for identical subsets", func() {
+		duplicate := &istiov1alpha3.Subset{Name: "v1-copy", Labels: map[string]string{"version": "v1"}}
+		Expect(createSubsetSupersetNotes(destinationRule(v1, duplicate))).To(HaveLen(0))
+	})
+
+	It("creates a note for a subset/superset pair", func() {
+		canary := &istiov1alpha3.Subset{
+			Name:   "v1-canary",
+			Labels: map[string]string{"version": "v1", "track": "canary"},
+		}
+		expNote := &apiv1.Note{
+			Type:    subsetSupersetNoteType,
+			Summary: subsetSupersetSummary,
+			Msg:     subsetSupersetMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"dr_name":       "reviews",
+				"namespace":     "default",
+				"subset":        "v1-canary",
+				"parent_subset": "v1",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createSubsetSupersetNotes(destinationRule(v1, canary))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,128 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subsetmixedversions vets the subsets of DestinationRule resources
+// and generates notes if the pods a subset selects have different version
+// labels.
+package subsetmixedversions
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "SubsetMixedVersions"
+	mixedVersionsNoteType    = "subset-mixed-versions"
+	mixedVersionsNoteSummary = "Subset with mixed versions - ${dr_name}"
+	mixedVersionsNoteMsg     = "The subset ${subset} of DestinationRule ${dr_name} in" +
+		" namespace ${namespace} selects pods with the versions ${version_list}." +
+		" Traffic routed to the subset reaches all of these versions. Consider" +
+		" adding the version label to the labels of the subset."
+)
+
+// SubsetMixedVersions implements Vetter interface
+type SubsetMixedVersions struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+	drLister  netv1alpha3.DestinationRuleLister
+}
+
+// createMixedVersionsNotes generates a note for every subset of the
+// DestinationRules whose selected pods have more than one version label.
+// Pods without a version label are ignored.
+func createMixedVersionsNotes(svcs []*corev1.Service, pods []*corev1.Pod,
+	drList []*v1alpha3.DestinationRule) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, dr := range drList {
+		s := resolver.Resolve(dr.Spec.GetHost(), dr.Namespace)
+		if s == nil {
+			continue
+		}
+		for _, subset := range dr.Spec.GetSubsets() {
+			versions := util.PodVersions(util.SubsetPods(s, subset.GetLabels(), pods))
+			if len(versions) < 2 {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    mixedVersionsNoteType,
+				Summary: mixedVersionsNoteSummary,
+				Msg:     mixedVersionsNoteMsg,
+				Level:   apiv1.NoteLevel_INFO,
+				Attr: map[string]string{
+					"dr_name":      dr.Name,
+					"namespace":    dr.Namespace,
+					"subset":       subset.GetName(),
+					"version_list": strings.Join(versions, ", "),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *SubsetMixedVersions) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(m.nsLister, m.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			mixedVersionsNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(m.nsLister, m.podLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			mixedVersionsNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	drList, err := util.ListDestinationRulesInMesh(m.nsLister, m.drLister)
+	if err != nil {
+		return nil, err
+	}
+	return createMixedVersionsNotes(svcs, pods, drList), nil
+}
+
+// Info returns information about the vetter
+func (m *SubsetMixedVersions) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "SubsetMixedVersions" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *SubsetMixedVersions {
+	return &SubsetMixedVersions{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+		drLister:  factory.Istio().Networking().V1alpha3().DestinationRules().Lister(),
+	}
+}
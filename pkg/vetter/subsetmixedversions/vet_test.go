@@ -0,0 +1,88 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subsetmixedversions
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func pod(name string, l map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: l}}
+}
+
+func destinationRule(subsetLabels map[string]string) []*v1alpha3.DestinationRule {
+	return []*v1alpha3.DestinationRule{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.DestinationRuleSpec{
+				DestinationRule: istiov1alpha3.DestinationRule{
+					Host:    "reviews",
+					Subsets: []*istiov1alpha3.Subset{{Name: "stable", Labels: subsetLabels}},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Subsets with mixed versions", func() {
+	svcs := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+		},
+	}
+	pods := []*corev1.Pod{
+		pod("reviews-v1", map[string]string{"app": "reviews", "track": "stable", "version": "v1"}),
+		pod("reviews-v2", map[string]string{"app": "reviews", "track": "stable", "version": "v2"}),
+		pod("reviews-legacy", map[string]string{"app": "reviews", "track": "legacy"}),
+	}
+
+	It("creates zero notes for a single-version subset", func() {
+		drList := destinationRule(map[string]string{"version": "v1"})
+		Expect(createMixedVersionsNotes(svcs, pods, drList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a subset of pods without versions", func() {
+		drList := destinationRule(map[string]string{"track": "legacy"})
+		Expect(createMixedVersionsNotes(svcs, pods, drList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a multi-version subset", func() {
+		expNote := &apiv1.Note{
+			Type:    mixedVersionsNoteType,
+			Summary: mixedVersionsNoteSummary,
+			Msg:     mixedVersionsNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"dr_name":      "reviews",
+				"namespace":    "default",
+				"subset":       "stable",
+				"version_list": "v1, v2",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		drList := destinationRule(map[string]string{"track": "stable"})
+		Expect(createMixedVersionsNotes(svcs, pods, drList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package targetportprotocol vets the ports of the services in the mesh and
+// generates notes if the protocol of a service port disagrees with the
+// protocol of the container port it targets.
+package targetportprotocol
+
+import (
+	"strconv"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                   = "TargetPortProtocol"
+	targetPortProtocolNoteType = "target-port-protocol-mismatch"
+	targetPortProtocolSummary  = "Service and container port protocol mismatch - ${service_name}"
+	targetPortProtocolMsg      = "The port ${port_name} (protocol ${service_protocol}) of" +
+		" service ${service_name} in namespace ${namespace} targets the container" +
+		" port ${container_port_name} (protocol ${container_protocol}) of pod" +
+		" ${pod_name}. Consider naming both ports with the prefix of the protocol" +
+		" the application serves."
+)
+
+// TargetPortProtocol implements Vetter interface
+type TargetPortProtocol struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	podLister v1.PodLister
+}
+
+// targetContainerPort returns the container port of the pod targeted by the
+// service port, or nil if the pod doesn't expose it.
+func targetContainerPort(sp corev1.ServicePort, p *corev1.Pod) *corev1.ContainerPort {
+	target := sp.TargetPort
+	if target.Type == intstr.Int && target.IntVal == 0 {
+		target = intstr.FromInt(int(sp.Port))
+	}
+	for _, c := range p.Spec.Containers {
+		for i := range c.Ports {
+			cp := &c.Ports[i]
+			if (target.Type == intstr.String && cp.Name == target.StrVal) ||
+				(target.Type == intstr.Int && cp.ContainerPort == target.IntVal) {
+				return cp
+			}
+		}
+	}
+	return nil
+}
+
+// createTargetPortProtocolNotes generates notes for the prefixed service ports
+// whose protocol disagrees with the protocol inferred from the name of the
+// targeted container port. Unnamed container ports are skipped. A note is
+// generated for the first selected pod with a disagreeing port only.
+func createTargetPortProtocolNotes(services []*corev1.Service,
+	pods []*corev1.Pod) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, s := range services {
+		if len(s.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(s.Spec.Selector)
+		for _, sp := range s.Spec.Ports {
+			if !util.ServicePortPrefixed(sp.Name) {
+				continue
+			}
+			svcProtocol := util.ServicePortProtocol(sp)
+			for _, p := range pods {
+				if p.Namespace != s.Namespace || !selector.Matches(labels.Set(p.Labels)) {
+					continue
+				}
+				cp := targetContainerPort(sp, p)
+				if cp == nil || cp.Name == "" {
+					continue
+				}
+				cProtocol := util.ServicePortProtocol(
+					corev1.ServicePort{Name: cp.Name, Protocol: cp.Protocol})
+				if !util.ServicePortPrefixed(cp.Name) || cProtocol == svcProtocol {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    targetPortProtocolNoteType,
+					Summary: targetPortProtocolSummary,
+					Msg:     targetPortProtocolMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"service_name":        s.Name,
+						"namespace":           s.Namespace,
+						"port_name":           sp.Name,
+						"port":                strconv.Itoa(int(sp.Port)),
+						"service_protocol":    svcProtocol,
+						"pod_name":            p.Name,
+						"container_port_name": cp.Name,
+						"container_protocol":  cProtocol,
+					},
+				})
+				break
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (t *TargetPortProtocol) Vet() ([]*apiv1.Note, error) {
+	services, err := util.ListServicesInMesh(t.nsLister, t.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			targetPortProtocolNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	pods, err := util.ListPodsInMesh(t.nsLister, t.podLister)
+	if err != nil {
+		return nil, err
+	}
+	return createTargetPortProtocolNotes(services, pods), nil
+}
+
+// Info returns information about the vetter
+func (t *TargetPortProtocol) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "TargetPortProtocol" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *TargetPortProtocol {
+	return &TargetPortProtocol{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		podLister: factory.K8s().Core().V1().Pods().Lister(),
+	}
+}
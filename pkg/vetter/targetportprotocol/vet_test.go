@@ -0,0 +1,91 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package targetportprotocol
+
+import (
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func podWithPort(name string) []*corev1.Pod {
+	return []*corev1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "api-1",
+				Namespace: "default",
+				Labels:    map[string]string{"app": "api"},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name:  "api",
+						Ports: []corev1.ContainerPort{{Name: name, ContainerPort: 8080}},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Service and container port protocols", func() {
+	services := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "api"},
+				Ports: []corev1.ServicePort{
+					{Name: "grpc", Port: 9000, TargetPort: intstr.FromInt(8080)},
+				},
+			},
+		},
+	}
+
+	It("creates zero notes for agreeing protocols", func() {
+		Expect(createTargetPortProtocolNotes(services, podWithPort("grpc-api"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for an unnamed container port", func() {
+		Expect(createTargetPortProtocolNotes(services, podWithPort(""))).To(HaveLen(0))
+	})
+
+	It("creates a note for a grpc/http disagreement", func() {
+		expNote := &apiv1.Note{
+			Type:    targetPortProtocolNoteType,
+			Summary: targetPortProtocolSummary,
+			Msg:     targetPortProtocolMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"service_name":        "api",
+				"namespace":           "default",
+				"port_name":           "grpc",
+				"port":                "9000",
+				"service_protocol":    "GRPC",
+				"pod_name":            "api-1",
+				"container_port_name": "http",
+				"container_protocol":  "HTTP",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes := createTargetPortProtocolNotes(services, podWithPort("http"))
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tcproutematchport vets the TCP routes of VirtualService resources
+// and generates notes if they match ports which the destination Service
+// doesn't expose.
+package tcproutematchport
+
+import (
+	"strconv"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "TCPRouteMatchPort"
+	danglingPortNoteType    = "tcp-route-match-port-not-found"
+	danglingPortNoteSummary = "TCP route matches missing port ${port} - ${vs_name}"
+	danglingPortNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" has a TCP route matching port ${port}, which the destination service" +
+		" ${destination_host} doesn't expose. The route never matches. Consider" +
+		" matching one of the ports of the service."
+)
+
+// TCPRouteMatchPort implements Vetter interface
+type TCPRouteMatchPort struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	vsLister  netv1alpha3.VirtualServiceLister
+}
+
+// servicePortExists returns true if the service exposes the port number.
+func servicePortExists(s *corev1.Service, port uint32) bool {
+	for _, sp := range s.Spec.Ports {
+		if uint32(sp.Port) == port {
+			return true
+		}
+	}
+	return false
+}
+
+// createDanglingPortNotes generates a note for every port matched by a TCP
+// route which a destination Service of the route doesn't expose. Matches
+// without a port match all ports and destinations which aren't Services in
+// the mesh are skipped.
+func createDanglingPortNotes(svcs []*corev1.Service,
+	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, vs := range vsList {
+		for _, r := range vs.Spec.GetTcp() {
+			for _, m := range r.GetMatch() {
+				port := m.GetPort()
+				if port == 0 {
+					continue
+				}
+				for _, rd := range r.GetRoute() {
+					host := rd.GetDestination().GetHost()
+					s := resolver.Resolve(host, vs.Namespace)
+					if s == nil || servicePortExists(s, port) {
+						continue
+					}
+					notes = append(notes, &apiv1.Note{
+						Type:    danglingPortNoteType,
+						Summary: danglingPortNoteSummary,
+						Msg:     danglingPortNoteMsg,
+						Level:   apiv1.NoteLevel_WARNING,
+						Attr: map[string]string{
+							"vs_name":          vs.Name,
+							"namespace":        vs.Namespace,
+							"port":             strconv.Itoa(int(port)),
+							"destination_host": host,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (t *TCPRouteMatchPort) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(t.nsLister, t.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			danglingPortNoteType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(t.nsLister, t.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createDanglingPortNotes(svcs, vsList), nil
+}
+
+// Info returns information about the vetter
+func (t *TCPRouteMatchPort) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "TCPRouteMatchPort" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *TCPRouteMatchPort {
+	return &TCPRouteMatchPort{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tcproutematchport
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(port uint32) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mongo", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: []string{"mongo"},
+					Tcp: []*istiov1alpha3.TCPRoute{
+						{
+							Match: []*istiov1alpha3.L4MatchAttributes{{Port: port}},
+							Route: []*istiov1alpha3.RouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "mongo"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("TCP route match ports", func() {
+	svcs := []*corev1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "mongo", Namespace: "default"},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{{Name: "mongo", Port: 27017}},
+			},
+		},
+	}
+
+	It("creates zero notes for a port the service exposes", func() {
+		Expect(createDanglingPortNotes(svcs, virtualService(27017))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a match without a port", func() {
+		Expect(createDanglingPortNotes(svcs, virtualService(0))).To(HaveLen(0))
+	})
+
+	It("creates a note for a port the service doesn't expose", func() {
+		expNote := &apiv1.Note{
+			Type:    danglingPortNoteType,
+			Summary: danglingPortNoteSummary,
+			Msg:     danglingPortNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":          "mongo",
+				"namespace":        "default",
+				"port":             "27018",
+				"destination_host": "mongo",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createDanglingPortNotes(svcs, virtualService(27018))).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
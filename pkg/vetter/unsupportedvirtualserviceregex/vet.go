@@ -0,0 +1,232 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package unsupportedvirtualserviceregex vets the regular expressions used in
+// VirtualService HTTP match rules and generates notes if they use features
+// which are not supported by the RE2 engine used by Envoy.
+package unsupportedvirtualserviceregex
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                    = "UnsupportedVirtualServiceRegex"
+	unsupportedRegexNoteType    = "unsupported-regex-feature"
+	unsupportedRegexNoteSummary = "Regex not supported by RE2 - ${vs_name}"
+	unsupportedRegexNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" matches ${match_field} with the regex \"${regex}\" which uses ${features}." +
+		" Envoy evaluates regexes with the RE2 engine, which does not support these" +
+		" features, so the match rule will be rejected. Consider rewriting the regex" +
+		" using only RE2 syntax."
+)
+
+const (
+	featureBackreference      = "backreferences"
+	featureLookahead          = "lookahead assertions"
+	featureLookbehind         = "lookbehind assertions"
+	featureAtomicGroup        = "atomic groups"
+	featurePossessive         = "possessive quantifiers"
+	featureConditional        = "conditional groups"
+	featureRecursion          = "recursive patterns"
+	featureNamedBackreference = "named backreferences"
+)
+
+// UnsupportedRegex implements Vetter interface
+type UnsupportedRegex struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// groupFeatures maps the prefixes which can follow "(?" to the PCRE feature
+// they introduce. RE2 accepts none of these.
+var groupFeatures = []struct {
+	prefix  string
+	feature string
+}{
+	{"<=", featureLookbehind},
+	{"<!", featureLookbehind},
+	{"=", featureLookahead},
+	{"!", featureLookahead},
+	{">", featureAtomicGroup},
+	{"(", featureConditional},
+	{"R)", featureRecursion},
+	{"P=", featureNamedBackreference},
+	{"P>", featureRecursion},
+	{"&", featureRecursion},
+}
+
+// unsupportedFeatures returns the sorted list of PCRE features used by the
+// regex which RE2 does not support. Escaped characters and character classes
+// are skipped so that literal parentheses, question marks etc. are not
+// reported.
+func unsupportedFeatures(regex string) []string {
+	found := map[string]bool{}
+	inClass := false
+	for i := 0; i < len(regex); i++ {
+		c := regex[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(regex) {
+				continue
+			}
+			next := regex[i+1]
+			if !inClass && next >= '1' && next <= '9' {
+				found[featureBackreference] = true
+			} else if !inClass && (next == 'k' || next == 'g') && i+2 < len(regex) &&
+				strings.ContainsRune("<{'", rune(regex[i+2])) {
+				found[featureNamedBackreference] = true
+			}
+			// Skip the escaped character
+			i++
+		case inClass:
+			if c == ']' {
+				inClass = false
+			}
+		case c == '[':
+			inClass = true
+			// A ']' directly after '[' or '[^' is a literal
+			if i+1 < len(regex) && regex[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(regex) && regex[i+1] == ']' {
+				i++
+			}
+		case c == '(' && strings.HasPrefix(regex[i+1:], "?"):
+			rest := regex[i+2:]
+			for _, g := range groupFeatures {
+				if strings.HasPrefix(rest, g.prefix) {
+					found[g.feature] = true
+					break
+				}
+			}
+		case (c == '*' || c == '+' || c == '?' || c == '}') &&
+			i+1 < len(regex) && regex[i+1] == '+':
+			found[featurePossessive] = true
+			i++
+		}
+	}
+	features := []string{}
+	for f := range found {
+		features = append(features, f)
+	}
+	sort.Strings(features)
+	return features
+}
+
+type regexMatch struct {
+	field string
+	regex string
+}
+
+func addRegex(matches []regexMatch, field string, sm *istiov1alpha3.StringMatch) []regexMatch {
+	if r := sm.GetRegex(); r != "" {
+		matches = append(matches, regexMatch{field: field, regex: r})
+	}
+	return matches
+}
+
+func addRegexMap(matches []regexMatch, field string, sms map[string]*istiov1alpha3.StringMatch) []regexMatch {
+	keys := []string{}
+	for k := range sms {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		matches = addRegex(matches, field+" \""+k+"\"", sms[k])
+	}
+	return matches
+}
+
+// matchRegexes returns all the regexes used by the HTTP match request along
+// with the name of the field they match.
+func matchRegexes(m *istiov1alpha3.HTTPMatchRequest) []regexMatch {
+	matches := []regexMatch{}
+	matches = addRegex(matches, "uri", m.GetUri())
+	matches = addRegex(matches, "scheme", m.GetScheme())
+	matches = addRegex(matches, "method", m.GetMethod())
+	matches = addRegex(matches, "authority", m.GetAuthority())
+	matches = addRegexMap(matches, "header", m.GetHeaders())
+	matches = addRegexMap(matches, "query parameter", m.GetQueryParams())
+	return matches
+}
+
+// createUnsupportedRegexNotes generates notes for every regex in the
+// VirtualServices' HTTP match rules that uses a feature RE2 rejects.
+func createUnsupportedRegexNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for _, route := range vs.Spec.GetHttp() {
+			for _, match := range route.GetMatch() {
+				for _, rm := range matchRegexes(match) {
+					features := unsupportedFeatures(rm.regex)
+					if len(features) == 0 {
+						continue
+					}
+					notes = append(notes, &apiv1.Note{
+						Type:    unsupportedRegexNoteType,
+						Summary: unsupportedRegexNoteSummary,
+						Msg:     unsupportedRegexNoteMsg,
+						Level:   apiv1.NoteLevel_WARNING,
+						Attr: map[string]string{
+							"vs_name":     vs.Name,
+							"namespace":   vs.Namespace,
+							"match_field": rm.field,
+							"regex":       rm.regex,
+							"features":    strings.Join(features, ", "),
+						},
+					})
+				}
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (r *UnsupportedRegex) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(r.nsLister, r.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createUnsupportedRegexNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (r *UnsupportedRegex) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "UnsupportedRegex" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *UnsupportedRegex {
+	return &UnsupportedRegex{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
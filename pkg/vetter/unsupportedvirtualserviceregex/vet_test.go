@@ -0,0 +1,113 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package unsupportedvirtualserviceregex
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func regexVirtualService(name string, match *istiov1alpha3.HTTPMatchRequest) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: []string{"reviews"},
+				Http: []*istiov1alpha3.HTTPRoute{
+					{
+						Match: []*istiov1alpha3.HTTPMatchRequest{match},
+					},
+				},
+			},
+		},
+	}
+}
+
+func uriRegex(r string) *istiov1alpha3.HTTPMatchRequest {
+	return &istiov1alpha3.HTTPMatchRequest{
+		Uri: &istiov1alpha3.StringMatch{
+			MatchType: &istiov1alpha3.StringMatch_Regex{Regex: r},
+		},
+	}
+}
+
+var _ = Describe("Unsupported VirtualService Regex", func() {
+	It("creates zero notes on empty lists", func() {
+		Expect(createUnsupportedRegexNotes(nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for simple regexes", func() {
+		vsList := []*v1alpha3.VirtualService{
+			regexVirtualService("simple", uriRegex(`^/api/v[0-9]+/(users|groups)/.*$`)),
+			regexVirtualService("escaped", uriRegex(`^/literal\(\?=x\)/[(?!)]\\1$`)),
+			regexVirtualService("named", uriRegex(`^/(?P<version>v[12])/.*`)),
+		}
+		Expect(createUnsupportedRegexNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for a backreference", func() {
+		vsList := []*v1alpha3.VirtualService{
+			regexVirtualService("backref", uriRegex(`^/(\w+)/\1$`)),
+		}
+		expNote := &apiv1.Note{
+			Type:    unsupportedRegexNoteType,
+			Summary: unsupportedRegexNoteSummary,
+			Msg:     unsupportedRegexNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":     "backref",
+				"namespace":   "default",
+				"match_field": "uri",
+				"regex":       `^/(\w+)/\1$`,
+				"features":    "backreferences",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(createUnsupportedRegexNotes(vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for lookahead in a header match", func() {
+		match := &istiov1alpha3.HTTPMatchRequest{
+			Headers: map[string]*istiov1alpha3.StringMatch{
+				"x-user": {
+					MatchType: &istiov1alpha3.StringMatch_Regex{Regex: `^(?!admin).*(?=-test)$`},
+				},
+			},
+		}
+		vsList := []*v1alpha3.VirtualService{
+			regexVirtualService("lookahead", match),
+		}
+		notes := createUnsupportedRegexNotes(vsList)
+		Expect(notes).To(HaveLen(1))
+		Expect(notes[0].Attr["match_field"]).To(Equal("header \"x-user\""))
+		Expect(notes[0].Attr["features"]).To(Equal("lookahead assertions"))
+		Expect(notes[0].Level).To(Equal(apiv1.NoteLevel_WARNING))
+	})
+
+	It("names every unsupported feature used by a regex", func() {
+		Expect(unsupportedFeatures(`(?<=a)b(?>c)d++\k<name>`)).To(Equal([]string{
+			"atomic groups", "lookbehind assertions", "named backreferences", "possessive quantifiers"}))
+	})
+})
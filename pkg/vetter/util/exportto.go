@@ -0,0 +1,94 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+
+	"github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Constants related to the exportTo visibility of Istio resources
+const (
+	// IstioExportToAnnotation sets the exportTo visibility of a Service.
+	IstioExportToAnnotation = "networking.istio.io/exportTo"
+	// ExportToAll exports a resource to all Namespaces.
+	ExportToAll = "*"
+	// ExportToNamespace exports a resource to its own Namespace.
+	ExportToNamespace = "."
+)
+
+// exportToOrDefault returns exportTo, or the mesh default if it is empty.
+// Resources are exported to all Namespaces if neither is set.
+func exportToOrDefault(exportTo, meshDefault []string) []string {
+	if len(exportTo) > 0 {
+		return exportTo
+	}
+	if len(meshDefault) > 0 {
+		return meshDefault
+	}
+	return []string{ExportToAll}
+}
+
+// ServiceExportTo returns the exportTo visibility of the Service from its
+// "networking.istio.io/exportTo" annotation, falling back to the
+// defaultServiceExportTo of the mesh config.
+func ServiceExportTo(s *corev1.Service, mc *meshv1alpha1.MeshConfig) []string {
+	exportTo := []string{}
+	for _, e := range strings.Split(s.Annotations[IstioExportToAnnotation], ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			exportTo = append(exportTo, e)
+		}
+	}
+	return exportToOrDefault(exportTo, mc.GetDefaultServiceExportTo())
+}
+
+// DestinationRuleExportTo returns the exportTo visibility of the
+// DestinationRule, falling back to the defaultDestinationRuleExportTo of the
+// mesh config.
+func DestinationRuleExportTo(dr *v1alpha3.DestinationRule, mc *meshv1alpha1.MeshConfig) []string {
+	return exportToOrDefault(dr.Spec.GetExportTo(), mc.GetDefaultDestinationRuleExportTo())
+}
+
+// ServiceEntryExportTo returns the exportTo visibility of the ServiceEntry.
+// The mesh config has no default for ServiceEntries, so Istio falls back to
+// its defaultServiceExportTo.
+func ServiceEntryExportTo(se *v1alpha3.ServiceEntry, mc *meshv1alpha1.MeshConfig) []string {
+	return exportToOrDefault(se.Spec.GetExportTo(), mc.GetDefaultServiceExportTo())
+}
+
+// ExportedTo returns true if a resource in namespace with the exportTo
+// visibility is visible in the target Namespace.
+func ExportedTo(exportTo []string, namespace, target string) bool {
+	for _, e := range exportTo {
+		switch e {
+		case ExportToAll:
+			return true
+		case ExportToNamespace:
+			if namespace == target {
+				return true
+			}
+		default:
+			if e == target {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+)
+
+// attrPlaceholder matches the "${key}" placeholders used by the Summary and
+// Msg of notes.
+var attrPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// missingAttr returns the token rendered for an attribute missing from a note.
+func missingAttr(key string) string {
+	return "<missing:" + key + ">"
+}
+
+// templateFields adds the names of the fields referenced by the nodes of a
+// template to fields.
+func templateFields(n parse.Node, fields map[string]bool) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			templateFields(c, fields)
+		}
+	case *parse.ActionNode:
+		templateFields(n.Pipe, fields)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			templateFields(c, fields)
+		}
+	case *parse.CommandNode:
+		for _, a := range n.Args {
+			templateFields(a, fields)
+		}
+	case *parse.FieldNode:
+		fields[n.Ident[0]] = true
+	case *parse.IfNode:
+		templateFields(&n.BranchNode, fields)
+	case *parse.RangeNode:
+		templateFields(&n.BranchNode, fields)
+	case *parse.WithNode:
+		templateFields(&n.BranchNode, fields)
+	case *parse.BranchNode:
+		templateFields(n.Pipe, fields)
+		templateFields(n.List, fields)
+		templateFields(n.ElseList, fields)
+	}
+}
+
+// formatAttr renders the text/template s with the attributes. The "${key}"
+// placeholders of notes are accepted as well as "{{.key}}". Keys missing from
+// attr are rendered as "<missing:key>".
+func formatAttr(s string, attr map[string]string) (string, error) {
+	t, err := template.New("note").Parse(attrPlaceholder.ReplaceAllString(s, "{{.$1}}"))
+	if err != nil {
+		return "", err
+	}
+	fields := map[string]bool{}
+	templateFields(t.Tree.Root, fields)
+	data := make(map[string]string, len(attr)+len(fields))
+	for k := range fields {
+		data[k] = missingAttr(k)
+	}
+	for k, v := range attr {
+		data[k] = v
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// FormatNote returns the Msg of the note with its placeholders replaced by
+// the Attr of the note. Placeholders are "${key}" or "{{.key}}", attributes
+// missing from the note are rendered as "<missing:key>".
+func FormatNote(n *apiv1.Note) (string, error) {
+	return formatAttr(n.GetMsg(), n.GetAttr())
+}
+
+// FormatNoteSummary returns the Summary of the note rendered as FormatNote
+// renders its Msg.
+func FormatNoteSummary(n *apiv1.Note) (string, error) {
+	return formatAttr(n.GetSummary(), n.GetAttr())
+}
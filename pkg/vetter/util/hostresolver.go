@@ -0,0 +1,111 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HostResolver resolves the hostnames used in Istio resources to the
+// Kubernetes Services they refer to.
+type HostResolver struct {
+	byFQDN      map[string]*corev1.Service
+	byShortName map[string][]*corev1.Service
+}
+
+// NewHostResolver returns a HostResolver for the given list of Services.
+func NewHostResolver(services []*corev1.Service) *HostResolver {
+	r := &HostResolver{
+		byFQDN:      map[string]*corev1.Service{},
+		byShortName: map[string][]*corev1.Service{},
+	}
+	for _, s := range services {
+		r.byFQDN[s.Name+"."+s.Namespace+KubernetesDomainSuffix] = s
+		r.byShortName[s.Name] = append(r.byShortName[s.Name], s)
+	}
+	return r
+}
+
+// IsShortName returns true if the host is a bare Service name which is
+// qualified with the namespace of the resource referring to it.
+func IsShortName(host string) bool {
+	return host != "" && !strings.HasPrefix(host, "*") && !strings.Contains(host, ".")
+}
+
+// SplitServiceFQDN returns the Service name and namespace of a Kubernetes
+// Service FQDN such as "reviews.default.svc.cluster.local". It returns false
+// if the host is not a Service FQDN.
+func SplitServiceFQDN(host string) (string, string, bool) {
+	if !strings.HasSuffix(host, KubernetesDomainSuffix) {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(host, KubernetesDomainSuffix), ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// Resolve returns the Service the host refers to when used in a resource in
+// the given namespace, or nil if there is no such Service in the mesh.
+func (r *HostResolver) Resolve(host, namespace string) *corev1.Service {
+	fqdn, err := ConvertHostnameToFQDN(host, namespace)
+	if err != nil {
+		return nil
+	}
+	return r.byFQDN[fqdn]
+}
+
+// Normalize returns the lower-cased FQDN of the host used in a resource in
+// the given namespace, so that hosts referring to the same Service compare
+// equal. Hosts ending in ".svc" are completed with the cluster domain. Hosts
+// which can't be qualified are returned lower-cased.
+func (r *HostResolver) Normalize(host, namespace string) string {
+	if strings.HasSuffix(strings.ToLower(host), ".svc") {
+		host += strings.TrimPrefix(KubernetesDomainSuffix, ".svc")
+	}
+	if s := r.Resolve(host, namespace); s != nil {
+		return strings.ToLower(s.Name + "." + s.Namespace + KubernetesDomainSuffix)
+	}
+	fqdn, err := ConvertHostnameToFQDN(host, namespace)
+	if err != nil {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(fqdn)
+}
+
+// AmbiguousNamespaces returns the sorted namespaces containing a Service
+// named host if host is a short name matching Services in more than one
+// namespace. It returns nil if the host is unambiguous.
+func (r *HostResolver) AmbiguousNamespaces(host string) []string {
+	if !IsShortName(host) {
+		return nil
+	}
+	svcs := r.byShortName[host]
+	if len(svcs) < 2 {
+		return nil
+	}
+	namespaces := make([]string, 0, len(svcs))
+	for _, s := range svcs {
+		namespaces = append(namespaces, s.Namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultImageRegistry = "docker.io"
+	defaultImageTag      = "latest"
+)
+
+// ImageRef is a container image reference split into its components, e.g.
+// docker.io/istio/proxyv2:1.2.3.
+type ImageRef struct {
+	// Registry is the registry host, including its port if any. Defaults to
+	// "docker.io".
+	Registry string
+	// Repository is the path of the image in the registry. Official images of
+	// docker.io are prefixed by "library/".
+	Repository string
+	// Tag is the tag of the image. Defaults to "latest" unless the image is
+	// specified by digest.
+	Tag string
+	// Digest is the content digest of the image, e.g. "sha256:...", if the
+	// image is specified by digest.
+	Digest string
+}
+
+// String returns the fully qualified image reference.
+func (r ImageRef) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// ParseImage parses a container image reference. A registry is only
+// recognized in the first path component if it contains a "." or ":", or is
+// "localhost". Images without a tag or digest default to the "latest" tag.
+func ParseImage(image string) (ImageRef, error) {
+	ref := ImageRef{}
+	name := image
+	if i := strings.Index(name, "@"); i != -1 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !strings.Contains(ref.Digest, ":") {
+			return ImageRef{}, fmt.Errorf("Invalid digest in image %s", image)
+		}
+	}
+	// A colon before the last slash separates the port of the registry.
+	if i := strings.LastIndex(name, ":"); i != -1 && i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+		if ref.Tag == "" {
+			return ImageRef{}, fmt.Errorf("Empty tag in image %s", image)
+		}
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultImageTag
+	}
+	ref.Registry = defaultImageRegistry
+	if i := strings.Index(name, "/"); i != -1 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			ref.Registry, name = host, name[i+1:]
+		}
+	}
+	if name == "" {
+		return ImageRef{}, fmt.Errorf("Missing repository in image %s", image)
+	}
+	if ref.Registry == defaultImageRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// ParsedImage returns the parsed image for the container named n if present
+// in the pod spec, or an error otherwise.
+func ParsedImage(n string, s corev1.PodSpec) (ImageRef, error) {
+	image, err := Image(n, s)
+	if err != nil {
+		return ImageRef{}, err
+	}
+	return ParseImage(image)
+}
+
+// ParsedInitImage returns the parsed image for the init container named n if
+// present in the pod spec, or an error otherwise.
+func ParsedInitImage(n string, s corev1.PodSpec) (ImageRef, error) {
+	image, err := InitImage(n, s)
+	if err != nil {
+		return ImageRef{}, err
+	}
+	return ParseImage(image)
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	admissionv1beta1 "k8s.io/client-go/listers/admissionregistration/v1beta1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+// IstioSidecarInjectorWebhook is the name of the MutatingWebhookConfiguration
+// of the Istio sidecar injector.
+const IstioSidecarInjectorWebhook = "istio-sidecar-injector"
+
+// SidecarInjectorConfig describes the automatic sidecar injection performed
+// by the sidecar injector webhook.
+type SidecarInjectorConfig struct {
+	// Policy is the injection policy of the "istio-sidecar-injector"
+	// configmap. With the disabled policy only pods annotated for injection
+	// are injected.
+	Policy InjectionPolicy
+	// NamespaceSelector selects the Namespaces the webhook injects into.
+	NamespaceSelector labels.Selector
+}
+
+// InjectionEnabled returns true if the sidecar injector webhook injects into
+// pods of the Namespace.
+func (c *SidecarInjectorConfig) InjectionEnabled(ns *corev1.Namespace) bool {
+	return c.NamespaceSelector.Matches(labels.Set(ns.Labels))
+}
+
+// GetSidecarInjectorConfig retrieves the sidecar injection config from the
+// "istio-sidecar-injector" configmap and MutatingWebhookConfiguration. If
+// either is missing its defaults are used: the enabled policy, and Namespaces
+// labeled "istio-injection=enabled".
+func GetSidecarInjectorConfig(cmLister v1.ConfigMapLister,
+	mwcLister admissionv1beta1.MutatingWebhookConfigurationLister) (*SidecarInjectorConfig, error) {
+	cfg := &SidecarInjectorConfig{
+		Policy:            InjectionPolicyEnabled,
+		NamespaceSelector: labels.SelectorFromSet(istioInjectNamespaceLabel),
+	}
+	cm, err := GetInitializerConfigMap(cmLister)
+	if err == nil {
+		ic, err := GetIstioInjectConfig(cm)
+		if err != nil {
+			return nil, err
+		}
+		if ic.Policy != "" {
+			cfg.Policy = ic.Policy
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	mwc, err := mwcLister.Get(IstioSidecarInjectorWebhook)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return cfg, nil
+		}
+		glog.Errorf("Failed to retrieve MutatingWebhookConfiguration: %s error: %s",
+			IstioSidecarInjectorWebhook, err)
+		return nil, err
+	}
+	for _, w := range mwc.Webhooks {
+		// A webhook without a namespaceSelector applies to every Namespace.
+		if w.NamespaceSelector == nil {
+			cfg.NamespaceSelector = labels.Everything()
+			break
+		}
+		selector, err := metav1.LabelSelectorAsSelector(w.NamespaceSelector)
+		if err != nil {
+			glog.Errorf("Failed to parse namespaceSelector of webhook: %s error: %s", w.Name, err)
+			return nil, err
+		}
+		cfg.NamespaceSelector = selector
+		break
+	}
+	return cfg, nil
+}
@@ -35,6 +35,8 @@ const (
 	MTLSSetting_DISABLED MTLSSetting = 2
 	// Mixed if mTLS is partially enabled or disabled
 	MTLSSetting_MIXED MTLSSetting = 3
+	// Permissive if mTLS is accepted but not required
+	MTLSSetting_PERMISSIVE MTLSSetting = 4
 )
 
 type policiesByNamespaceMap map[string][]*authv1alpha1.Policy
@@ -216,9 +218,9 @@ func getModeFromPeers(peerAuthMethods []*istioauthv1alpha1.PeerAuthenticationMet
 	// peerAuthMethods is checked for being Empty in the calling function.
 
 	// Per peerAuthMethod, check if it lists mtls in any way, then check for
-	// mtls Mode. Count the number of enabled or disabled methods to determine the
-	// final mtls state for this policy.
-	var enabled, disabled int
+	// mtls Mode. Count the number of enabled or permissive methods to determine
+	// the final mtls state for this policy.
+	var enabled, permissive int
 	for _, pam := range peerAuthMethods {
 		// PeerAuthenticationMethod could be JWT or multiple mtls settings.
 		if pam.GetMtls() != nil {
@@ -228,7 +230,7 @@ func getModeFromPeers(peerAuthMethods []*istioauthv1alpha1.PeerAuthenticationMet
 			if peerMode == istioauthv1alpha1.MutualTls_STRICT {
 				enabled++
 			} else {
-				disabled++
+				permissive++
 			}
 		} else {
 			// A peer section exists, but the peer authentication methods are
@@ -240,12 +242,14 @@ func getModeFromPeers(peerAuthMethods []*istioauthv1alpha1.PeerAuthenticationMet
 			}
 		}
 	}
-	// If there are occurrences of of disabled and enabled it is mixed. Otherwise,
-	// it will be enabled or disabled.
-	if disabled != 0 && enabled != 0 {
+	// If there are occurrences of of permissive and enabled it is mixed.
+	// Otherwise, it will be enabled, permissive or disabled.
+	if permissive != 0 && enabled != 0 {
 		mtlsState = MTLSSetting_MIXED
 	} else if enabled != 0 {
 		mtlsState = MTLSSetting_ENABLED
+	} else if permissive != 0 {
+		mtlsState = MTLSSetting_PERMISSIVE
 	} else {
 		mtlsState = MTLSSetting_DISABLED
 	}
@@ -349,6 +353,23 @@ func (ap *AuthPolicies) TLSByPort(s Service, port uint32) (bool, *authv1alpha1.P
 	return getMTLSBool(mtlsState), policy, err
 }
 
+// IsStrict returns true if the Auth Policies enforce STRICT mTLS for the
+// service with the given FQDN. The port level policy is used when port is
+// non-zero.
+func (ap *AuthPolicies) IsStrict(fqdn string, port uint32) bool {
+	s, err := ServiceFromFqdn(fqdn)
+	if err != nil {
+		return false
+	}
+	var mtls MTLSSetting
+	if port != 0 {
+		mtls, _, err = ap.TLSDetailsByPort(s, port)
+	} else {
+		mtls, _, err = ap.TLSDetailsByName(s)
+	}
+	return err == nil && mtls == MTLSSetting_ENABLED
+}
+
 // TLSDetailsByName walks through Auth Policies at the port and name level, and
 // returns the mtlsState for the requested resource. It returns the mTls state
 // for the parent resource if there is no policy for the requested resource.
@@ -320,7 +320,7 @@ var _ = Describe("Check TLS Details from AuthPolicies", func() {
 		ns2svc1, pol, err := loaded.TLSDetailsByName(Service{Namespace: "ns2", Name: "ns2-svc1"})
 		Expect(err).To(BeNil())
 		Expect(pol).ToNot(BeNil())
-		Expect(ns2svc1).To(Equal(MTLSSetting_DISABLED))
+		Expect(ns2svc1).To(Equal(MTLSSetting_PERMISSIVE))
 
 		ns3svc1, pol, err := loaded.TLSDetailsByName(Service{Namespace: "ns3", Name: "ns3-svc1"})
 		Expect(err).Should(MatchError("Conflicting policies for service by name"))
@@ -335,9 +335,34 @@ var _ = Describe("Check TLS Details from AuthPolicies", func() {
 		ns4svc2, pol, err := loaded.TLSDetailsByPort(Service{Namespace: "ns4", Name: "ns4-svc2"}, uint32(8456))
 		Expect(err).To(BeNil())
 		Expect(pol).ToNot(BeNil())
-		Expect(ns4svc2).To(Equal(MTLSSetting_DISABLED))
+		Expect(ns4svc2).To(Equal(MTLSSetting_PERMISSIVE))
 
 	})
+
+	It("tells PERMISSIVE policies apart from no policy", func() {
+		loadedPermissive, err := LoadAuthPolicies([]*authv1alpha1.Policy{
+			diyPolicy("ns5", "default", peersPermissive, noTargets),
+		}, nil)
+		Expect(err).To(BeNil())
+		permissive, _, err := loadedPermissive.TLSDetailsByNamespace(Service{Namespace: "ns5"})
+		Expect(err).To(BeNil())
+		Expect(permissive).To(Equal(MTLSSetting_PERMISSIVE))
+
+		loadedNone, err := LoadAuthPolicies(nil, nil)
+		Expect(err).To(BeNil())
+		none, _, err := loadedNone.TLSDetailsByNamespace(Service{Namespace: "ns5"})
+		Expect(err).To(BeNil())
+		Expect(none).To(Equal(MTLSSetting_DISABLED))
+	})
+
+	It("reports STRICT mTLS by FQDN", func() {
+		Expect(loadErr).To(BeNil())
+		Expect(loaded.IsStrict("ns4-svc1.ns4.svc.cluster.local", uint32(8123))).To(BeTrue())
+		Expect(loaded.IsStrict("ns4-svc2.ns4.svc.cluster.local", uint32(8456))).To(BeFalse())
+		Expect(loaded.IsStrict("ns2-svc1.ns2.svc.cluster.local", 0)).To(BeFalse())
+		Expect(loaded.IsStrict("ns3-svc1.ns3.svc.cluster.local", 0)).To(BeFalse())
+		Expect(loaded.IsStrict("www.example.com", 0)).To(BeFalse())
+	})
 })
 
 var _ = Describe("LoadAuthPolicies and LoadMeshPolicy", func() {
@@ -475,9 +500,9 @@ var _ = Describe("TLS Details", func() {
 
 var _ = Describe("ForEachPolByPort()", func() {
 	Context("tallies the right mtlsState when an AuthPolicies struct is checked for port policies", func() {
-		var enabled, disabled, mixed, unknown int
+		var enabled, disabled, permissive, mixed, unknown int
 		BeforeEach(func() {
-			enabled, disabled, mixed, unknown = 0, 0, 0, 0
+			enabled, disabled, permissive, mixed, unknown = 0, 0, 0, 0, 0
 		})
 
 		cb := func(policies []*authv1alpha1.Policy) {
@@ -490,6 +515,8 @@ var _ = Describe("ForEachPolByPort()", func() {
 					enabled++
 				case state == MTLSSetting_DISABLED:
 					disabled++
+				case state == MTLSSetting_PERMISSIVE:
+					permissive++
 				}
 			} else {
 				unknown++
@@ -511,7 +538,8 @@ var _ = Describe("ForEachPolByPort()", func() {
 			loadedOn.ForEachPolByPort(s, cb)
 
 			Expect(enabled).To(Equal(2))
-			Expect(disabled).To(Equal(2))
+			Expect(disabled).To(Equal(1))
+			Expect(permissive).To(Equal(1))
 			Expect(mixed).To(Equal(0))
 			Expect(unknown).To(Equal(1))
 		})
@@ -525,7 +553,8 @@ var _ = Describe("ForEachPolByPort()", func() {
 			s := Service{Namespace: "default", Name: "foo"}
 			loadedOn.ForEachPolByPort(s, cb)
 
-			Expect(disabled).To(Equal(1))
+			Expect(disabled).To(Equal(0))
+			Expect(permissive).To(Equal(1))
 			Expect(mixed).To(Equal(0))
 		})
 		It("when passed valid policies with NO target port", func() {
@@ -548,9 +577,9 @@ var _ = Describe("ForEachPolByPort()", func() {
 var _ = Describe("getModeFromPeers()", func() {
 	Context("getModeFromPeers() takes a set of PeerAuthenticationMethods and returns a single mTls Mode", func() {
 
-		It("returns MIXED when len() == 1 && Mode is set to permissive", func() {
+		It("returns PERMISSIVE when len() == 1 && Mode is set to permissive", func() {
 			mtlsState := getModeFromPeers(peersPermissive)
-			Expect(mtlsState).To(Equal(MTLSSetting_DISABLED))
+			Expect(mtlsState).To(Equal(MTLSSetting_PERMISSIVE))
 		})
 		It("returns ENABLED when len() == 1 && the Mode is STRICT", func() {
 			mtlsState := getModeFromPeers(peersStrict)
@@ -0,0 +1,194 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+// DefaultListWorkers is the number of Namespaces listed concurrently by the
+// parallel variants of the list functions.
+const DefaultListWorkers = 8
+
+// listInNamespaces calls list for every Namespace from up to workers
+// goroutines. Once list returns an error no further Namespaces are listed,
+// and the first error is returned after the running calls finished.
+func listInNamespaces(namespaces []*corev1.Namespace, workers int,
+	list func(namespace string) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	done := make(chan struct{})
+	work := make(chan string)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range work {
+				select {
+				case <-done:
+					continue
+				default:
+				}
+				if err := list(n); err != nil {
+					once.Do(func() {
+						firstErr = err
+						close(done)
+					})
+				}
+			}
+		}()
+	}
+feed:
+	for _, n := range namespaces {
+		select {
+		case <-done:
+			break feed
+		default:
+		}
+		select {
+		case work <- n.Name:
+		case <-done:
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	return firstErr
+}
+
+// lessByNamespaceName orders objects by Namespace, then by name.
+func lessByNamespaceName(a, b interface {
+	GetNamespace() string
+	GetName() string
+}) bool {
+	if a.GetNamespace() != b.GetNamespace() {
+		return a.GetNamespace() < b.GetNamespace()
+	}
+	return a.GetName() < b.GetName()
+}
+
+// ListPodsInMeshParallel returns the same Pods as ListPodsInMesh, listing the
+// Namespaces concurrently with DefaultListWorkers goroutines. The Pods are
+// sorted by Namespace, then by name.
+func ListPodsInMeshParallel(nsLister v1.NamespaceLister, podLister v1.PodLister) ([]*corev1.Pod, error) {
+	ns, err := ListNamespacesInMesh(nsLister)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	pods := []*corev1.Pod{}
+	err = listInNamespaces(ns, DefaultListWorkers, func(n string) error {
+		podList, err := podLister.Pods(n).List(labels.Everything())
+		if err != nil {
+			glog.Errorf("Failed to retrieve pods for namespace: %s error: %s", n, err)
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, p := range podList {
+			if SidecarInjected(p) {
+				RecordSuppressedNotes(p)
+				pods = append(pods, p)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(pods, func(i, j int) bool { return lessByNamespaceName(pods[i], pods[j]) })
+	return pods, nil
+}
+
+// ListServicesInMeshParallel returns the same Services as ListServicesInMesh,
+// listing the Namespaces concurrently with DefaultListWorkers goroutines. The
+// Services are sorted by Namespace, then by name.
+func ListServicesInMeshParallel(nsLister v1.NamespaceLister, svcLister v1.ServiceLister) ([]*corev1.Service, error) {
+	ns, err := ListNamespacesInMesh(nsLister)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	services := []*corev1.Service{}
+	err = listInNamespaces(ns, DefaultListWorkers, func(n string) error {
+		serviceList, err := svcLister.Services(n).List(labels.Everything())
+		if err != nil {
+			glog.Errorf("Failed to retrieve services for namespace: %s error: %s", n, err)
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, s := range serviceList {
+			if !IsKubernetesService(s) {
+				RecordSuppressedNotes(s)
+				services = append(services, s)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(services, func(i, j int) bool { return lessByNamespaceName(services[i], services[j]) })
+	return services, nil
+}
+
+// ListEndpointsInMeshParallel returns the same Endpoints as
+// ListEndpointsInMesh, listing the Namespaces concurrently with
+// DefaultListWorkers goroutines. The Endpoints are sorted by Namespace, then
+// by name.
+func ListEndpointsInMeshParallel(nsLister v1.NamespaceLister, epLister v1.EndpointsLister) ([]*corev1.Endpoints, error) {
+	ns, err := ListNamespacesInMesh(nsLister)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	endpoints := []*corev1.Endpoints{}
+	err = listInNamespaces(ns, DefaultListWorkers, func(n string) error {
+		endpointList, err := epLister.Endpoints(n).List(labels.Everything())
+		if err != nil {
+			glog.Errorf("Failed to retrieve endpoints for namespace: %s error: %s", n, err)
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range endpointList {
+			if e.Namespace != kubernetesServiceNamespace || e.Name != KubernetesServiceName {
+				RecordSuppressedNotes(e)
+				endpoints = append(endpoints, e)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return lessByNamespaceName(endpoints[i], endpoints[j]) })
+	return endpoints, nil
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SubsetPods returns the pods of the Service selected by the labels of a
+// DestinationRule subset. The Service must have a selector.
+func SubsetPods(s *corev1.Service, subsetLabels map[string]string,
+	pods []*corev1.Pod) []*corev1.Pod {
+	selected := []*corev1.Pod{}
+	if len(s.Spec.Selector) == 0 {
+		return selected
+	}
+	svcSelector := labels.SelectorFromSet(s.Spec.Selector)
+	subsetSelector := labels.SelectorFromSet(subsetLabels)
+	for _, p := range pods {
+		if p.Namespace == s.Namespace && svcSelector.Matches(labels.Set(p.Labels)) &&
+			subsetSelector.Matches(labels.Set(p.Labels)) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// PodVersions returns the sorted distinct values of the version label of the
+// pods. Pods without a version label are skipped.
+func PodVersions(pods []*corev1.Pod) []string {
+	seen := map[string]bool{}
+	versions := []string{}
+	for _, p := range pods {
+		v, ok := p.Labels[IstioVersionLabel]
+		if !ok || seen[v] {
+			continue
+		}
+		seen[v] = true
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"strings"
+	"sync"
+
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SuppressAnnotation lists the comma separated IDs of the notes suppressed by
+// a Namespace or an object.
+const SuppressAnnotation = "vet.istio.io/suppress"
+
+// suppressedNotes is the set of note IDs collected from SuppressAnnotation. It
+// is guarded by suppressedNotesMu since vetters may run concurrently.
+var (
+	suppressedNotesMu sync.RWMutex
+	suppressedNotes   = map[string]bool{}
+)
+
+// SuppressedNoteIDs returns the note IDs listed by the SuppressAnnotation of
+// the object.
+func SuppressedNoteIDs(obj metav1.Object) []string {
+	ids := []string{}
+	for _, id := range strings.Split(obj.GetAnnotations()[SuppressAnnotation], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// RecordSuppressedNotes adds the note IDs listed by the SuppressAnnotation of
+// the object to the set returned by SuppressedNotes. The list functions of
+// this package record the annotations of the Namespaces and objects they
+// return. Objects a vetter lists directly from a lister, e.g. Gateways or the
+// objects of all namespaces, are not recorded, so their annotations only
+// suppress notes if the vetter calls RecordSuppressedNotes itself.
+func RecordSuppressedNotes(obj metav1.Object) {
+	ids := SuppressedNoteIDs(obj)
+	if len(ids) == 0 {
+		return
+	}
+	suppressedNotesMu.Lock()
+	defer suppressedNotesMu.Unlock()
+	for _, id := range ids {
+		suppressedNotes[id] = true
+	}
+}
+
+// SetSuppressedNotes replaces the set of suppressed note IDs.
+func SetSuppressedNotes(ids []string) {
+	m := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		m[id] = true
+	}
+	suppressedNotesMu.Lock()
+	defer suppressedNotesMu.Unlock()
+	suppressedNotes = m
+}
+
+// SuppressedNotes returns a copy of the set of suppressed note IDs recorded so
+// far.
+func SuppressedNotes() map[string]bool {
+	suppressedNotesMu.RLock()
+	defer suppressedNotesMu.RUnlock()
+	return copyNamespaceSet(suppressedNotes)
+}
+
+// FilterSuppressed returns the notes whose ID isn't suppressed.
+func FilterSuppressed(notes []*apiv1.Note, suppressed map[string]bool) []*apiv1.Note {
+	filtered := []*apiv1.Note{}
+	for _, n := range notes {
+		if !suppressed[n.GetId()] {
+			filtered = append(filtered, n)
+		}
+	}
+	return filtered
+}
+
+// DowngradeSuppressed sets the level of the suppressed notes to INFO, in
+// place, and returns the notes. Notes below INFO are left unchanged.
+func DowngradeSuppressed(notes []*apiv1.Note, suppressed map[string]bool) []*apiv1.Note {
+	for _, n := range notes {
+		if suppressed[n.GetId()] && n.GetLevel() > apiv1.NoteLevel_INFO {
+			n.Level = apiv1.NoteLevel_INFO
+		}
+	}
+	return notes
+}
@@ -19,10 +19,13 @@ limitations under the License.
 package util
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
 	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
@@ -31,6 +34,7 @@ import (
 	"github.com/ghodss/yaml"
 	"github.com/golang/glog"
 	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -42,25 +46,33 @@ const (
 	IstioNamespace                = "istio-system"
 	IstioProxyContainerName       = "istio-proxy"
 	IstioInitContainerName        = "istio-init"
+	IstioDiscoveryContainerName   = "discovery"
+	IstioPilotDeploymentName      = "istio-pilot"
+	IstiodDeploymentName          = "istiod"
 	IstioConfigMap                = "istio"
 	IstioConfigMapKey             = "mesh"
 	IstioInitializerPodAnnotation = "sidecar.istio.io/status"
 	IstioInitializerConfigMap     = "istio-sidecar-injector"
 	IstioInitializerConfigMapKey  = "config"
 	IstioAppLabel                 = "app"
+	IstioVersionLabel             = "version"
+	IstioInjectionLabel           = "istio-injection"
+	IstioRevisionLabel            = "istio.io/rev"
+	IstioDefaultRevision          = "default"
 	KubernetesDomainSuffix        = ".svc.cluster.local"
+	KubernetesServiceName         = "kubernetes"
 	ServiceProtocolUDP            = "UDP"
 	initializerDisabled           = "configmaps \"" +
 		IstioInitializerConfigMap + "\" not found"
 	initializerDisabledSummary = "Istio initializer is not configured." +
 		" Enable initializer and automatic sidecar injection to use "
-	kubernetesServiceName            = "kubernetes"
+	kubernetesServiceNamespace       = "default"
 	kubernetesProxyStatusPort        = "--statusPort"
 	kubernetesProxyStatusPortDefault = 15020
 )
 
 var istioInjectNamespaceLabel = map[string]string{
-	"istio-injection": "enabled"}
+	IstioInjectionLabel: "enabled"}
 
 // Config specifies the sidecar injection configuration This includes
 // the sidear template and cluster-side injection policy. It is used
@@ -79,33 +91,86 @@ var istioSupportedServicePrefix = []string{
 	"https", "https-",
 	"grpc", "grpc-",
 	"mongo", "mongo-",
+	"mysql", "mysql-",
 	"redis", "redis-",
 	"tcp", "tcp-",
 	"tls", "tls-",
 	"udp", "udp-"}
 
+// IstioProxyPorts are the ports the sidecar proxy listens on in the pod:
+// the admin port, the outbound and inbound capture ports, the status port and
+// the Prometheus telemetry port.
+var IstioProxyPorts = map[int32]string{
+	15000: "admin",
+	15001: "outbound capture",
+	15006: "inbound capture",
+	15020: "status",
+	15090: "telemetry",
+}
+
 var defaultExemptedNamespaces = map[string]bool{
 	"kube-system":  true,
 	"kube-public":  true,
 	"istio-system": true}
 
-// DefaultExemptedNamespaces returns list of default Namsepaces which are
+// exemptedNamespaces is the set of exempted Namespaces in effect. It starts
+// as defaultExemptedNamespaces and is guarded by exemptedNamespacesMu since
+// vetters may run concurrently.
+var (
+	exemptedNamespacesMu sync.RWMutex
+	exemptedNamespaces   = copyNamespaceSet(defaultExemptedNamespaces)
+)
+
+func copyNamespaceSet(m map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// SetExemptedNamespaces replaces the set of Namespaces which are exempted
+// from automatic sidecar injection, e.g. for a control plane running in a
+// custom Namespace.
+func SetExemptedNamespaces(ns []string) {
+	m := make(map[string]bool, len(ns))
+	for _, n := range ns {
+		m[n] = true
+	}
+	exemptedNamespacesMu.Lock()
+	defer exemptedNamespacesMu.Unlock()
+	exemptedNamespaces = m
+}
+
+// AddExemptedNamespace adds a Namespace to the set of Namespaces which are
 // exempted from automatic sidecar injection.
-// List includes "kube-system", "kube-public" and "istio-system"
+func AddExemptedNamespace(ns string) {
+	exemptedNamespacesMu.Lock()
+	defer exemptedNamespacesMu.Unlock()
+	exemptedNamespaces[ns] = true
+}
+
+// DefaultExemptedNamespaces returns the sorted list of Namespaces which are
+// exempted from automatic sidecar injection.
+// List includes "kube-system", "kube-public" and "istio-system" unless
+// overridden by SetExemptedNamespaces.
 func DefaultExemptedNamespaces() []string {
-	s := make([]string, len(defaultExemptedNamespaces))
-	i := 0
-	for k := range defaultExemptedNamespaces {
-		s[i] = k
-		i++
+	exemptedNamespacesMu.RLock()
+	defer exemptedNamespacesMu.RUnlock()
+	s := make([]string, 0, len(exemptedNamespaces))
+	for k := range exemptedNamespaces {
+		s = append(s, k)
 	}
+	sort.Strings(s)
 	return s
 }
 
-// ExemptedNamespace checks if a Namespace is by default exempted from automatic
-// sidecar injection.
+// ExemptedNamespace checks if a Namespace is exempted from automatic sidecar
+// injection.
 func ExemptedNamespace(ns string) bool {
-	return defaultExemptedNamespaces[ns]
+	exemptedNamespacesMu.RLock()
+	defer exemptedNamespacesMu.RUnlock()
+	return exemptedNamespaces[ns]
 }
 
 // GetInitializerConfig retrieves the Istio Initializer config.
@@ -208,28 +273,48 @@ func IstioInitializerDisabledNote(e, vetterID, vetterType string) *apiv1.Note {
 	return nil
 }
 
-// ServicePortPrefixed checks if the Service port name is prefixed with Istio
-// supported protocols.
-func ServicePortPrefixed(n string) bool {
+// DetectServiceProtocol returns the Istio supported protocol the Service port
+// name is prefixed with, e.g. "http" for a port named "HTTP-web", and false if
+// the name has no supported prefix. The port name is matched
+// case-insensitively.
+func DetectServiceProtocol(n string) (string, bool) {
+	n = strings.ToLower(n)
 	i := 0
 	for i < len(istioSupportedServicePrefix) {
 		if n == istioSupportedServicePrefix[i] || strings.HasPrefix(n, istioSupportedServicePrefix[i+1]) {
-			return true
+			return istioSupportedServicePrefix[i], true
 		}
 		i += 2
 	}
-	return false
+	return "", false
+}
+
+// ServicePortPrefixed checks if the Service port name is prefixed with Istio
+// supported protocols. The port name is matched case-insensitively.
+func ServicePortPrefixed(n string) bool {
+	_, ok := DetectServiceProtocol(n)
+	return ok
+}
+
+// ServicePortProtocol returns the protocol Istio infers for the service port
+// from its name prefix, e.g. "HTTP" for a port named "http-web". Ports without
+// a recognized prefix are treated as TCP unless their protocol is UDP.
+func ServicePortProtocol(p corev1.ServicePort) string {
+	if p.Protocol == ServiceProtocolUDP {
+		return ServiceProtocolUDP
+	}
+	if protocol, ok := DetectServiceProtocol(p.Name); ok {
+		return strings.ToUpper(protocol)
+	}
+	return "TCP"
 }
 
 // SidecarInjected checks if sidecar is injected in a Pod.
-// Sidecar is considered injected if initializer annotation and proxy container
-// are both present in the Pod Spec.
+// Sidecar is considered injected if the proxy container is present in the Pod
+// Spec, which also covers manually injected pods without the initializer
+// annotation.
 func SidecarInjected(p *corev1.Pod) bool {
-	if _, ok := p.Annotations[IstioInitializerPodAnnotation]; !ok {
-		return false
-	}
-	cList := p.Spec.Containers
-	for _, c := range cList {
+	for _, c := range p.Spec.Containers {
 		if c.Name == IstioProxyContainerName {
 			return true
 		}
@@ -237,6 +322,16 @@ func SidecarInjected(p *corev1.Pod) bool {
 	return false
 }
 
+// SidecarInjectedWithStatus checks if sidecar is injected in a Pod and the
+// injection was recorded by Istio. Sidecar is considered injected if
+// initializer annotation and proxy container are both present in the Pod Spec.
+func SidecarInjectedWithStatus(p *corev1.Pod) bool {
+	if _, ok := p.Annotations[IstioInitializerPodAnnotation]; !ok {
+		return false
+	}
+	return SidecarInjected(p)
+}
+
 func imageFromContainers(n string, cList []corev1.Container) (string, error) {
 	for _, c := range cList {
 		if c.Name == n {
@@ -260,18 +355,110 @@ func InitImage(n string, s corev1.PodSpec) (string, error) {
 	return imageFromContainers(n, s.InitContainers)
 }
 
+// ProxyVersion returns the image tag of the sidecar proxy container of the
+// pod, e.g. "1.2.3" for docker.io/istio/proxyv2:1.2.3. Images without a tag
+// default to "latest". An error is returned if there is no proxy container or
+// its image is specified by digest.
+func ProxyVersion(p *corev1.Pod) (string, error) {
+	ref, err := ParsedImage(IstioProxyContainerName, p.Spec)
+	if err != nil {
+		return "", err
+	}
+	if ref.Digest != "" {
+		return "", fmt.Errorf("Proxy image %s is specified by digest", ref)
+	}
+	return ref.Tag, nil
+}
+
 // ListNamespacesInMesh returns the list of Namespaces in the mesh.
-// Namespaces with label "istio-inject=enabled" are considered in
-// the mesh.
+// Namespaces with label "istio-injection=enabled" are considered in
+// the mesh. Any other value of the label, e.g. "disabled", opts the
+// Namespace out, regardless of the injector configuration. The notes
+// suppressed by the Namespaces are recorded, see RecordSuppressedNotes.
 func ListNamespacesInMesh(nsLister v1.NamespaceLister) ([]*corev1.Namespace, error) {
 	ns, err := nsLister.List(labels.Set(istioInjectNamespaceLabel).AsSelector())
 	if err != nil {
 		glog.Error("Failed to retrieve namespaces: ", err)
 		return nil, err
 	}
+	for _, n := range ns {
+		RecordSuppressedNotes(n)
+	}
 	return ns, nil
 }
 
+// NamespaceReason describes why a Namespace is or isn't in the mesh.
+type NamespaceReason string
+
+// Reasons reported by ClassifyNamespaces
+const (
+	// NamespaceLabelEnabled is reported for Namespaces labeled
+	// "istio-injection=enabled".
+	NamespaceLabelEnabled NamespaceReason = "label-enabled"
+	// NamespaceLabelDisabled is reported for Namespaces with any other value
+	// of the "istio-injection" label.
+	NamespaceLabelDisabled NamespaceReason = "label-disabled"
+	// NamespaceDefaultExcluded is reported for Namespaces without the
+	// "istio-injection" label.
+	NamespaceDefaultExcluded NamespaceReason = "default-excluded"
+)
+
+// NamespaceClassification records whether a Namespace is in the mesh and why.
+// Exempted is true for Namespaces exempted from automatic sidecar injection,
+// see ExemptedNamespace.
+type NamespaceClassification struct {
+	Name     string
+	InMesh   bool
+	Reason   NamespaceReason
+	Exempted bool
+}
+
+// ClassifyNamespaces returns the classification of all Namespaces, sorted by
+// name. InMesh matches the result of ListNamespacesInMesh and Reason names
+// the "istio-injection" label value deciding it, whether or not the Namespace
+// is exempted.
+func ClassifyNamespaces(nsLister v1.NamespaceLister) ([]NamespaceClassification, error) {
+	nsList, err := nsLister.List(labels.Everything())
+	if err != nil {
+		glog.Error("Failed to retrieve namespaces: ", err)
+		return nil, err
+	}
+	c := make([]NamespaceClassification, 0, len(nsList))
+	for _, ns := range nsList {
+		v, labeled := ns.Labels[IstioInjectionLabel]
+		nc := NamespaceClassification{
+			Name:     ns.Name,
+			InMesh:   labeled && v == istioInjectNamespaceLabel[IstioInjectionLabel],
+			Exempted: ExemptedNamespace(ns.Name),
+		}
+		switch {
+		case nc.InMesh:
+			nc.Reason = NamespaceLabelEnabled
+		case labeled:
+			nc.Reason = NamespaceLabelDisabled
+		default:
+			nc.Reason = NamespaceDefaultExcluded
+		}
+		c = append(c, nc)
+	}
+	sort.Slice(c, func(i, j int) bool { return c[i].Name < c[j].Name })
+	return c, nil
+}
+
+// InjectionRevision returns the control plane revision which injects the
+// sidecar into pods of the Namespace, or false if injection isn't enabled.
+// The "istio-injection" label takes precedence over the "istio.io/rev" label,
+// and enables injection by the default revision.
+func InjectionRevision(ns *corev1.Namespace) (string, bool) {
+	if v, ok := ns.Labels[IstioInjectionLabel]; ok {
+		return IstioDefaultRevision, v == istioInjectNamespaceLabel[IstioInjectionLabel]
+	}
+	if rev := ns.Labels[IstioRevisionLabel]; rev != "" {
+		return rev, true
+	}
+	return "", false
+}
+
 // ListPodsInMesh returns the list of Pods in the mesh.
 // Pods in Namespaces returned by ListNamespacesInMesh with sidecar
 // injected as determined by SidecarInjected are considered in the mesh.
@@ -289,6 +476,7 @@ func ListPodsInMesh(nsLister v1.NamespaceLister, podLister v1.PodLister) ([]*cor
 		}
 		for _, p := range podList {
 			if SidecarInjected(p) == true {
+				RecordSuppressedNotes(p)
 				pods = append(pods, p)
 			}
 		}
@@ -296,9 +484,43 @@ func ListPodsInMesh(nsLister v1.NamespaceLister, podLister v1.PodLister) ([]*cor
 	return pods, nil
 }
 
+// ServiceFilterOpts selects the Services excluded by
+// ListServicesInMeshFiltered. The zero value includes all Services.
+type ServiceFilterOpts struct {
+	// ExcludeHeadless excludes Services without a cluster IP.
+	ExcludeHeadless bool
+	// ExcludeExternalName excludes Services of type ExternalName.
+	ExcludeExternalName bool
+	// ExcludeNamespaces excludes the Services in the listed Namespaces.
+	ExcludeNamespaces []string
+}
+
+// excluded returns true if the options exclude the Service.
+func (o ServiceFilterOpts) excluded(s *corev1.Service) bool {
+	if o.ExcludeHeadless && s.Spec.ClusterIP == corev1.ClusterIPNone {
+		return true
+	}
+	if o.ExcludeExternalName && s.Spec.Type == corev1.ServiceTypeExternalName {
+		return true
+	}
+	for _, n := range o.ExcludeNamespaces {
+		if s.Namespace == n {
+			return true
+		}
+	}
+	return false
+}
+
 // ListServicesInMesh returns the list of Services in the mesh.
 // Services in Namespaces returned by ListNamespacesInMesh are considered in the mesh.
 func ListServicesInMesh(nsLister v1.NamespaceLister, svcLister v1.ServiceLister) ([]*corev1.Service, error) {
+	return ListServicesInMeshFiltered(nsLister, svcLister, ServiceFilterOpts{})
+}
+
+// ListServicesInMeshFiltered returns the list of Services in the mesh as
+// ListServicesInMesh does, without the Services excluded by opts.
+func ListServicesInMeshFiltered(nsLister v1.NamespaceLister, svcLister v1.ServiceLister,
+	opts ServiceFilterOpts) ([]*corev1.Service, error) {
 	services := []*corev1.Service{}
 	ns, err := ListNamespacesInMesh(nsLister)
 	if err != nil {
@@ -311,7 +533,8 @@ func ListServicesInMesh(nsLister v1.NamespaceLister, svcLister v1.ServiceLister)
 			return nil, err
 		}
 		for _, s := range serviceList {
-			if s.Name != "kubernetes" {
+			if !IsKubernetesService(s) && !opts.excluded(s) {
+				RecordSuppressedNotes(s)
 				services = append(services, s)
 			}
 		}
@@ -319,6 +542,29 @@ func ListServicesInMesh(nsLister v1.NamespaceLister, svcLister v1.ServiceLister)
 	return services, nil
 }
 
+// IsKubernetesService returns true if the Service is the kubernetes Service
+// fronting the API server.
+func IsKubernetesService(s *corev1.Service) bool {
+	return s.Namespace == kubernetesServiceNamespace && s.Name == KubernetesServiceName
+}
+
+// IsKubernetesServiceHost returns true if the host of an Istio resource in
+// the namespace resolves to the kubernetes Service fronting the API server.
+func IsKubernetesServiceHost(host, namespace string) bool {
+	fqdn, err := ConvertHostnameToFQDN(host, namespace)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(fqdn,
+		KubernetesServiceName+"."+kubernetesServiceNamespace+KubernetesDomainSuffix)
+}
+
+// KubernetesServiceEndpoints returns the Endpoints of the kubernetes Service,
+// i.e. the addresses of the API server.
+func KubernetesServiceEndpoints(epLister v1.EndpointsLister) (*corev1.Endpoints, error) {
+	return epLister.Endpoints(kubernetesServiceNamespace).Get(KubernetesServiceName)
+}
+
 func IsEndpointInMesh(ea *corev1.EndpointAddress, podLister v1.PodLister) bool {
 	if ea != nil && ea.TargetRef != nil {
 		if ea.TargetRef.Kind == "Pod" {
@@ -352,7 +598,8 @@ func ListEndpointsInMesh(nsLister v1.NamespaceLister, epLister v1.EndpointsListe
 			return nil, err
 		}
 		for _, s := range endpointList {
-			if s.Name != kubernetesServiceName {
+			if s.Namespace != kubernetesServiceNamespace || s.Name != KubernetesServiceName {
+				RecordSuppressedNotes(s)
 				endpoints = append(endpoints, s)
 			}
 		}
@@ -362,10 +609,43 @@ func ListEndpointsInMesh(nsLister v1.NamespaceLister, epLister v1.EndpointsListe
 
 // ComputeID returns MD5 checksum of the Note struct which can be used as
 // ID for the note.
+//
+// Deprecated: the checksum covers every field of the Note, so the IDs change
+// whenever the struct or the message changes. Use ComputeIDStable instead.
 func ComputeID(n *apiv1.Note) string {
 	return fmt.Sprintf("%x", structhash.Md5(n, 1))
 }
 
+// ComputeIDStable returns the SHA256 checksum of the Type, Summary and Attr
+// fields of the Note which can be used as ID for the note. Other fields, e.g.
+// Msg, Level or Id, don't participate in the checksum, so the ID stays the
+// same as the Note struct evolves. Attr is hashed in the order of its keys.
+func ComputeIDStable(n *apiv1.Note) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%q\n%q\n", n.GetType(), n.GetSummary())
+	keys := make([]string, 0, len(n.GetAttr()))
+	for k := range n.GetAttr() {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%q=%q\n", k, n.GetAttr()[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// MaxLevel returns the highest level of the notes, or NoteLevel_UNUSED if
+// there are no notes.
+func MaxLevel(notes []*apiv1.Note) apiv1.NoteLevel {
+	max := apiv1.NoteLevel_UNUSED
+	for _, n := range notes {
+		if n.GetLevel() > max {
+			max = n.GetLevel()
+		}
+	}
+	return max
+}
+
 // ListVirtualServices returns a list of VirtualService resources in the mesh.
 func ListVirtualServicesInMesh(nsLister v1.NamespaceLister,
 	vsLister netv1alpha3.VirtualServiceLister) ([]*v1alpha3.VirtualService, error) {
@@ -380,11 +660,67 @@ func ListVirtualServicesInMesh(nsLister v1.NamespaceLister,
 			glog.Errorf("Failed to retrieve VirtualServices for namespace: %s error: %s", n.Name, err)
 			return nil, err
 		}
+		for _, r := range virtServiceList {
+			RecordSuppressedNotes(r)
+		}
 		virtualServices = append(virtualServices, virtServiceList...)
 	}
 	return virtualServices, nil
 }
 
+// HTTPRouteName returns the name of the http route, or its index within the
+// VirtualService if it is unnamed.
+func HTTPRouteName(r *istiov1alpha3.HTTPRoute, i int) string {
+	if r.GetName() != "" {
+		return r.GetName()
+	}
+	return strconv.Itoa(i)
+}
+
+// ListDestinationRulesInMesh returns a list of DestinationRule resources in the mesh.
+func ListDestinationRulesInMesh(nsLister v1.NamespaceLister,
+	drLister netv1alpha3.DestinationRuleLister) ([]*v1alpha3.DestinationRule, error) {
+	destinationRules := []*v1alpha3.DestinationRule{}
+	ns, err := ListNamespacesInMesh(nsLister)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range ns {
+		destRuleList, err := drLister.DestinationRules(n.Name).List(labels.Everything())
+		if err != nil {
+			glog.Errorf("Failed to retrieve DestinationRules for namespace: %s error: %s", n.Name, err)
+			return nil, err
+		}
+		for _, r := range destRuleList {
+			RecordSuppressedNotes(r)
+		}
+		destinationRules = append(destinationRules, destRuleList...)
+	}
+	return destinationRules, nil
+}
+
+// ListServiceEntriesInMesh returns a list of ServiceEntry resources in the mesh.
+func ListServiceEntriesInMesh(nsLister v1.NamespaceLister,
+	seLister netv1alpha3.ServiceEntryLister) ([]*v1alpha3.ServiceEntry, error) {
+	serviceEntries := []*v1alpha3.ServiceEntry{}
+	ns, err := ListNamespacesInMesh(nsLister)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range ns {
+		seList, err := seLister.ServiceEntries(n.Name).List(labels.Everything())
+		if err != nil {
+			glog.Errorf("Failed to retrieve ServiceEntries for namespace: %s error: %s", n.Name, err)
+			return nil, err
+		}
+		for _, r := range seList {
+			RecordSuppressedNotes(r)
+		}
+		serviceEntries = append(serviceEntries, seList...)
+	}
+	return serviceEntries, nil
+}
+
 // ConvertHostnameToFQDN returns the FQDN if a short name is passed
 func ConvertHostnameToFQDN(hostname string, namespace string) (string, error) {
 	if (hostname == "") || (namespace == "") {
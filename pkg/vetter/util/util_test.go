@@ -17,13 +17,28 @@ limitations under the License.
 package util
 
 import (
+	"errors"
 	"io/ioutil"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
 	"github.com/ghodss/yaml"
+	meshv1alpha1 "istio.io/api/mesh/v1alpha1"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	admissionv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	admissionv1beta1listers "k8s.io/client-go/listers/admissionregistration/v1beta1"
+	appsv1listers "k8s.io/client-go/listers/apps/v1"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 var _ = Describe("Converting short hostnames to FQDN", func() {
@@ -204,4 +219,932 @@ var _ = Describe("Test ProxyStatusPort", func() {
 		Expect(port == kubernetesProxyStatusPortDefault)
 		Expect(err != nil)
 	})
-})
\ No newline at end of file
+})
+var _ = Describe("HostResolver", func() {
+	newService := func(name, namespace string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		}
+	}
+	resolver := NewHostResolver([]*corev1.Service{
+		newService("reviews", "foo"),
+		newService("ratings", "foo"),
+		newService("ratings", "bar"),
+	})
+
+	It("Resolves short names in the namespace of the resource", func() {
+		Expect(resolver.Resolve("reviews", "foo")).To(Equal(newService("reviews", "foo")))
+		Expect(resolver.Resolve("reviews", "bar")).To(BeNil())
+	})
+
+	It("Resolves FQDNs regardless of the namespace of the resource", func() {
+		Expect(resolver.Resolve("ratings.bar.svc.cluster.local", "foo")).To(Equal(newService("ratings", "bar")))
+	})
+
+	It("Reports short names matching Services in several namespaces", func() {
+		Expect(resolver.AmbiguousNamespaces("reviews")).To(BeNil())
+		Expect(resolver.AmbiguousNamespaces("ratings")).To(Equal([]string{"bar", "foo"}))
+		Expect(resolver.AmbiguousNamespaces("ratings.foo.svc.cluster.local")).To(BeNil())
+	})
+
+	It("Normalizes hosts to lower-cased FQDNs", func() {
+		Expect(resolver.Normalize("reviews", "foo")).To(Equal("reviews.foo.svc.cluster.local"))
+		Expect(resolver.Normalize("Reviews.Foo.svc.cluster.local", "bar")).To(Equal("reviews.foo.svc.cluster.local"))
+		Expect(resolver.Normalize("reviews.foo.SVC", "bar")).To(Equal("reviews.foo.svc.cluster.local"))
+		Expect(resolver.Normalize("*.example.com", "foo")).To(Equal("*.example.com"))
+	})
+
+	It("Splits Service FQDNs into name and namespace", func() {
+		name, namespace, ok := SplitServiceFQDN("ratings.bar.svc.cluster.local")
+		Expect(ok).To(BeTrue())
+		Expect(name).To(Equal("ratings"))
+		Expect(namespace).To(Equal("bar"))
+		_, _, ok = SplitServiceFQDN("ratings")
+		Expect(ok).To(BeFalse())
+		_, _, ok = SplitServiceFQDN("v1.ratings.bar.svc.cluster.local")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Test ServicePortPrefixed", func() {
+	It("Recognizes the Istio supported protocol prefixes", func() {
+		cases := []struct {
+			name     string
+			prefixed bool
+		}{
+			{"https", true},
+			{"https-web", true},
+			{"tls", true},
+			{"mysql-primary", true},
+			{"mysql", true},
+			{"ftp", false},
+			{"httpsweb", false},
+		}
+		for _, c := range cases {
+			Expect(ServicePortPrefixed(c.name)).To(Equal(c.prefixed), c.name)
+		}
+	})
+
+	It("Matches the port name case-insensitively", func() {
+		Expect(ServicePortPrefixed("HTTP")).To(BeTrue())
+		Expect(ServicePortPrefixed("Http2-Web")).To(BeTrue())
+		Expect(ServicePortPrefixed("GRPC")).To(BeTrue())
+		Expect(ServicePortPrefixed("HTTP-API")).To(BeTrue())
+		Expect(ServicePortPrefixed("Kafka")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Test DetectServiceProtocol", func() {
+	It("Returns the protocol of the port name prefix", func() {
+		protocol, ok := DetectServiceProtocol("http")
+		Expect(ok).To(BeTrue())
+		Expect(protocol).To(Equal("http"))
+		protocol, ok = DetectServiceProtocol("Grpc-Web")
+		Expect(ok).To(BeTrue())
+		Expect(protocol).To(Equal("grpc"))
+		protocol, ok = DetectServiceProtocol("http2-api")
+		Expect(ok).To(BeTrue())
+		Expect(protocol).To(Equal("http2"))
+	})
+
+	It("Returns false without a supported prefix", func() {
+		protocol, ok := DetectServiceProtocol("kafka")
+		Expect(ok).To(BeFalse())
+		Expect(protocol).To(Equal(""))
+	})
+})
+
+var _ = Describe("Test ServicePortProtocol", func() {
+	It("Infers the protocol from the port name prefix", func() {
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "http"})).To(Equal("HTTP"))
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "grpc-web"})).To(Equal("GRPC"))
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "https-admin"})).To(Equal("HTTPS"))
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "Grpc-Web"})).To(Equal("GRPC"))
+	})
+
+	It("Defaults to TCP or UDP without a recognized prefix", func() {
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "web"})).To(Equal("TCP"))
+		Expect(ServicePortProtocol(corev1.ServicePort{Name: "dns", Protocol: corev1.ProtocolUDP})).To(Equal("UDP"))
+	})
+})
+
+var _ = Describe("Test ResolveWorkload", func() {
+	controller := true
+	controllerRef := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	pod := func(owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:            "reviews-v1-7f9c8-abcde",
+			Namespace:       "default",
+			OwnerReferences: owners,
+		}}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	indexer.Add(&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "reviews-v1-7f9c8",
+		Namespace:       "default",
+		OwnerReferences: controllerRef("Deployment", "reviews-v1"),
+	}})
+	rsLister := appsv1listers.NewReplicaSetLister(indexer)
+
+	It("Resolves pods of a ReplicaSet to the owning Deployment", func() {
+		p := pod(controllerRef("ReplicaSet", "reviews-v1-7f9c8"))
+		Expect(ResolveWorkload(p, rsLister)).To(Equal(
+			Workload{Kind: "Deployment", Name: "reviews-v1", Namespace: "default"}))
+		Expect(ResolveWorkload(p, nil).String()).To(Equal("ReplicaSet/reviews-v1-7f9c8"))
+	})
+
+	It("Resolves pods of other controllers to the controller", func() {
+		p := pod(controllerRef("StatefulSet", "reviews"))
+		Expect(ResolveWorkload(p, rsLister).String()).To(Equal("StatefulSet/reviews"))
+	})
+
+	It("Resolves bare pods to themselves", func() {
+		Expect(ResolveWorkload(pod(nil), rsLister).String()).To(Equal("Pod/reviews-v1-7f9c8-abcde"))
+	})
+})
+
+var _ = Describe("Test InjectionRevision", func() {
+	namespace := func(l map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: l}}
+	}
+
+	It("Returns the revision of injection-enabled namespaces", func() {
+		rev, ok := InjectionRevision(namespace(map[string]string{"istio-injection": "enabled"}))
+		Expect(ok).To(BeTrue())
+		Expect(rev).To(Equal(IstioDefaultRevision))
+		rev, ok = InjectionRevision(namespace(map[string]string{"istio.io/rev": "canary"}))
+		Expect(ok).To(BeTrue())
+		Expect(rev).To(Equal("canary"))
+	})
+
+	It("Prefers the istio-injection label over the revision label", func() {
+		rev, ok := InjectionRevision(namespace(map[string]string{
+			"istio-injection": "enabled", "istio.io/rev": "canary"}))
+		Expect(ok).To(BeTrue())
+		Expect(rev).To(Equal(IstioDefaultRevision))
+		_, ok = InjectionRevision(namespace(map[string]string{
+			"istio-injection": "disabled", "istio.io/rev": "canary"}))
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Returns false without injection labels", func() {
+		_, ok := InjectionRevision(namespace(nil))
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Test SubsetPods", func() {
+	pod := func(name string, l map[string]string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "foo", Labels: l}}
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "foo"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "reviews"}},
+	}
+	v1 := pod("reviews-v1", map[string]string{"app": "reviews", "version": "v1"})
+	v2 := pod("reviews-v2", map[string]string{"app": "reviews", "version": "v2"})
+	noVersion := pod("reviews", map[string]string{"app": "reviews"})
+	ratings := pod("ratings-v1", map[string]string{"app": "ratings", "version": "v1"})
+	pods := []*corev1.Pod{v1, v2, noVersion, ratings}
+
+	It("Selects the pods of the Service matching the subset labels", func() {
+		Expect(SubsetPods(svc, map[string]string{"version": "v1"}, pods)).To(Equal([]*corev1.Pod{v1}))
+		Expect(SubsetPods(svc, nil, pods)).To(Equal([]*corev1.Pod{v1, v2, noVersion}))
+	})
+
+	It("Returns the versions of the pods", func() {
+		Expect(PodVersions([]*corev1.Pod{v2, v1, noVersion, v1})).To(Equal([]string{"v1", "v2"}))
+		Expect(PodVersions([]*corev1.Pod{noVersion})).To(HaveLen(0))
+	})
+})
+
+var _ = Describe("Test HTTPRouteName", func() {
+	It("Returns the name of named routes", func() {
+		Expect(HTTPRouteName(&istiov1alpha3.HTTPRoute{Name: "legacy"}, 2)).To(Equal("legacy"))
+	})
+
+	It("Returns the index of unnamed routes", func() {
+		Expect(HTTPRouteName(&istiov1alpha3.HTTPRoute{}, 2)).To(Equal("2"))
+	})
+})
+
+var _ = Describe("Test IsKubernetesService", func() {
+	svc := func(name, namespace string) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+
+	It("Matches only the kubernetes Service in the default namespace", func() {
+		Expect(IsKubernetesService(svc("kubernetes", "default"))).To(BeTrue())
+		Expect(IsKubernetesService(svc("kubernetes", "foo"))).To(BeFalse())
+		Expect(IsKubernetesService(svc("reviews", "default"))).To(BeFalse())
+	})
+
+	It("Resolves hosts to the kubernetes Service", func() {
+		Expect(IsKubernetesServiceHost("kubernetes", "default")).To(BeTrue())
+		Expect(IsKubernetesServiceHost("kubernetes.default.svc.cluster.local", "foo")).To(BeTrue())
+		Expect(IsKubernetesServiceHost("kubernetes", "foo")).To(BeFalse())
+		Expect(IsKubernetesServiceHost("reviews.default.svc.cluster.local", "default")).To(BeFalse())
+		Expect(IsKubernetesServiceHost("", "default")).To(BeFalse())
+	})
+})
+
+var _ = Describe("Test SidecarInjected", func() {
+	pod := func(annotated, proxy bool) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "reviews"}}},
+		}
+		if annotated {
+			p.Annotations = map[string]string{IstioInitializerPodAnnotation: "{}"}
+		}
+		if proxy {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: IstioProxyContainerName})
+		}
+		return p
+	}
+
+	It("Detects a sidecar from the proxy container alone", func() {
+		Expect(SidecarInjected(pod(true, true))).To(BeTrue())
+		Expect(SidecarInjected(pod(false, true))).To(BeTrue())
+		Expect(SidecarInjected(pod(true, false))).To(BeFalse())
+		Expect(SidecarInjected(pod(false, false))).To(BeFalse())
+	})
+
+	It("Requires the status annotation with SidecarInjectedWithStatus", func() {
+		Expect(SidecarInjectedWithStatus(pod(true, true))).To(BeTrue())
+		Expect(SidecarInjectedWithStatus(pod(false, true))).To(BeFalse())
+		Expect(SidecarInjectedWithStatus(pod(true, false))).To(BeFalse())
+		Expect(SidecarInjectedWithStatus(pod(false, false))).To(BeFalse())
+	})
+})
+
+var _ = Describe("Test ProxyVersion", func() {
+	pod := func(image string) *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{Name: "reviews", Image: "reviews:2.0"},
+					{Name: IstioProxyContainerName, Image: image},
+				},
+			},
+		}
+	}
+
+	It("Returns the tag of the proxy image", func() {
+		v, err := ProxyVersion(pod("docker.io/istio/proxyv2:1.2.3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("1.2.3"))
+		v, err = ProxyVersion(pod("registry.local:5000/istio/proxyv2:1.4.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("1.4.0"))
+	})
+
+	It("Defaults to latest for images without a tag", func() {
+		v, err := ProxyVersion(pod("docker.io/istio/proxyv2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("latest"))
+		v, err = ProxyVersion(pod("registry.local:5000/istio/proxyv2"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal("latest"))
+	})
+
+	It("Fails for images specified by digest or pods without a proxy", func() {
+		_, err := ProxyVersion(pod("docker.io/istio/proxyv2@sha256:0123456789abcdef"))
+		Expect(err).To(HaveOccurred())
+		_, err = ProxyVersion(&corev1.Pod{})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Test GetSidecarInjectorConfig", func() {
+	namespace := func(l map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: l}}
+	}
+	newIndexer := func(objs ...interface{}) cache.Indexer {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, o := range objs {
+			indexer.Add(o)
+		}
+		return indexer
+	}
+	enabled := namespace(map[string]string{"istio-injection": "enabled"})
+	canary := namespace(map[string]string{"istio.io/rev": "canary"})
+
+	It("Defaults to the istio-injection label without configmap and webhook", func() {
+		cfg, err := GetSidecarInjectorConfig(corev1listers.NewConfigMapLister(newIndexer()),
+			admissionv1beta1listers.NewMutatingWebhookConfigurationLister(newIndexer()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Policy).To(Equal(InjectionPolicyEnabled))
+		Expect(cfg.InjectionEnabled(enabled)).To(BeTrue())
+		Expect(cfg.InjectionEnabled(namespace(map[string]string{"istio-injection": "disabled"}))).To(BeFalse())
+		Expect(cfg.InjectionEnabled(canary)).To(BeFalse())
+	})
+
+	It("Uses the policy of the configmap and the selector of the webhook", func() {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: IstioInitializerConfigMap, Namespace: IstioNamespace},
+			Data:       map[string]string{IstioInitializerConfigMapKey: "policy: disabled\n"},
+		}
+		mwc := &admissionv1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: IstioSidecarInjectorWebhook},
+			Webhooks: []admissionv1beta1.MutatingWebhook{
+				{
+					Name: "sidecar-injector.istio.io",
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"istio.io/rev": "canary"},
+					},
+				},
+			},
+		}
+		cfg, err := GetSidecarInjectorConfig(corev1listers.NewConfigMapLister(newIndexer(cm)),
+			admissionv1beta1listers.NewMutatingWebhookConfigurationLister(newIndexer(mwc)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Policy).To(Equal(InjectionPolicyDisabled))
+		Expect(cfg.InjectionEnabled(canary)).To(BeTrue())
+		Expect(cfg.InjectionEnabled(enabled)).To(BeFalse())
+	})
+
+	It("Selects every Namespace if the webhook has no selector", func() {
+		mwc := &admissionv1beta1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: IstioSidecarInjectorWebhook},
+			Webhooks: []admissionv1beta1.MutatingWebhook{
+				{Name: "sidecar-injector.istio.io"},
+			},
+		}
+		cfg, err := GetSidecarInjectorConfig(corev1listers.NewConfigMapLister(newIndexer()),
+			admissionv1beta1listers.NewMutatingWebhookConfigurationLister(newIndexer(mwc)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.InjectionEnabled(canary)).To(BeTrue())
+		Expect(cfg.InjectionEnabled(enabled)).To(BeTrue())
+		Expect(cfg.InjectionEnabled(namespace(nil))).To(BeTrue())
+	})
+})
+
+var _ = Describe("Test ListNamespacesInMesh", func() {
+	namespace := func(name string, l map[string]string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: l}}
+	}
+	labeledIn := namespace("in", map[string]string{"istio-injection": "enabled"})
+	labeledOut := namespace("out", map[string]string{"istio-injection": "disabled"})
+	unlabeled := namespace("unlabeled", nil)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range []*corev1.Namespace{labeledIn, labeledOut, unlabeled} {
+		indexer.Add(ns)
+	}
+
+	It("Returns only the Namespaces labeled for injection", func() {
+		ns, err := ListNamespacesInMesh(corev1listers.NewNamespaceLister(indexer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns).To(Equal([]*corev1.Namespace{labeledIn}))
+	})
+
+	It("Classifies every Namespace with the reason", func() {
+		c, err := ClassifyNamespaces(corev1listers.NewNamespaceLister(indexer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal([]NamespaceClassification{
+			{Name: "in", InMesh: true, Reason: NamespaceLabelEnabled},
+			{Name: "out", InMesh: false, Reason: NamespaceLabelDisabled},
+			{Name: "unlabeled", InMesh: false, Reason: NamespaceDefaultExcluded},
+		}))
+	})
+
+	It("Reports exempted Namespaces", func() {
+		defer SetExemptedNamespaces(DefaultExemptedNamespaces())
+		AddExemptedNamespace("in")
+		AddExemptedNamespace("unlabeled")
+		c, err := ClassifyNamespaces(corev1listers.NewNamespaceLister(indexer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal([]NamespaceClassification{
+			{Name: "in", InMesh: true, Reason: NamespaceLabelEnabled, Exempted: true},
+			{Name: "out", InMesh: false, Reason: NamespaceLabelDisabled},
+			{Name: "unlabeled", InMesh: false, Reason: NamespaceDefaultExcluded, Exempted: true},
+		}))
+	})
+
+	It("Reports the label of exempted Namespaces labeled for injection", func() {
+		exempted := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		exempted.Add(namespace("kube-system", map[string]string{"istio-injection": "enabled"}))
+		c, err := ClassifyNamespaces(corev1listers.NewNamespaceLister(exempted))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal([]NamespaceClassification{
+			{Name: "kube-system", InMesh: true, Reason: NamespaceLabelEnabled, Exempted: true},
+		}))
+	})
+})
+
+var _ = Describe("Test MeshVersion", func() {
+	deployment := func(name, image string) *appsv1.Deployment {
+		d := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: IstioNamespace}}
+		d.Spec.Template.Spec.Containers = []corev1.Container{
+			{Name: IstioDiscoveryContainerName, Image: image},
+		}
+		return d
+	}
+	lister := func(deployments ...*appsv1.Deployment) appsv1listers.DeploymentLister {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, d := range deployments {
+			indexer.Add(d)
+		}
+		return appsv1listers.NewDeploymentLister(indexer)
+	}
+
+	It("Parses the version of the istio-pilot deployment", func() {
+		v, err := MeshVersion(lister(deployment("istio-pilot", "docker.io/istio/pilot:1.4.3")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.String()).To(Equal("1.4.3"))
+	})
+
+	It("Falls back to the istiod deployment", func() {
+		v, err := MeshVersion(lister(deployment("istiod", "docker.io/istio/pilot:1.5.0")))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v.String()).To(Equal("1.5.0"))
+	})
+
+	It("Fails without a control plane deployment or with an unparsable tag", func() {
+		_, err := MeshVersion(lister())
+		Expect(err).To(HaveOccurred())
+		_, err = MeshVersion(lister(deployment("istio-pilot", "docker.io/istio/pilot")))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("Test note suppression", func() {
+	AfterEach(func() {
+		SetSuppressedNotes(nil)
+	})
+
+	suppressing := func(name, ids string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      map[string]string{"istio-injection": "enabled"},
+			Annotations: map[string]string{SuppressAnnotation: ids},
+		}}
+	}
+
+	It("Parses the suppressed note IDs", func() {
+		Expect(SuppressedNoteIDs(suppressing("a", "abc, def,,"))).To(Equal([]string{"abc", "def"}))
+		Expect(SuppressedNoteIDs(suppressing("a", ""))).To(BeEmpty())
+		Expect(SuppressedNoteIDs(&corev1.Namespace{})).To(BeEmpty())
+	})
+
+	It("Records the notes suppressed by Namespaces in the mesh", func() {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		indexer.Add(suppressing("a", "abc,def"))
+		indexer.Add(suppressing("b", "ghi"))
+		out := suppressing("c", "jkl")
+		out.Labels = nil
+		indexer.Add(out)
+		_, err := ListNamespacesInMesh(corev1listers.NewNamespaceLister(indexer))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(SuppressedNotes()).To(Equal(map[string]bool{"abc": true, "def": true, "ghi": true}))
+	})
+
+	It("Filters and downgrades suppressed notes", func() {
+		notes := func() []*apiv1.Note {
+			return []*apiv1.Note{
+				{Id: "abc", Level: apiv1.NoteLevel_ERROR},
+				{Id: "def", Level: apiv1.NoteLevel_WARNING},
+			}
+		}
+		suppressed := map[string]bool{"abc": true}
+		Expect(FilterSuppressed(notes(), suppressed)).To(Equal([]*apiv1.Note{notes()[1]}))
+		Expect(FilterSuppressed(notes(), nil)).To(Equal(notes()))
+		Expect(DowngradeSuppressed(notes(), suppressed)).To(Equal([]*apiv1.Note{
+			{Id: "abc", Level: apiv1.NoteLevel_INFO},
+			{Id: "def", Level: apiv1.NoteLevel_WARNING},
+		}))
+	})
+})
+
+var _ = Describe("Test MaxLevel", func() {
+	notes := func(levels ...apiv1.NoteLevel) []*apiv1.Note {
+		n := []*apiv1.Note{}
+		for _, l := range levels {
+			n = append(n, &apiv1.Note{Level: l})
+		}
+		return n
+	}
+
+	It("Returns UNUSED without notes", func() {
+		Expect(MaxLevel(nil)).To(Equal(apiv1.NoteLevel_UNUSED))
+		Expect(MaxLevel(notes())).To(Equal(apiv1.NoteLevel_UNUSED))
+	})
+
+	It("Returns the highest level regardless of order", func() {
+		Expect(MaxLevel(notes(apiv1.NoteLevel_INFO))).To(Equal(apiv1.NoteLevel_INFO))
+		Expect(MaxLevel(notes(apiv1.NoteLevel_INFO, apiv1.NoteLevel_WARNING,
+			apiv1.NoteLevel_INFO))).To(Equal(apiv1.NoteLevel_WARNING))
+		Expect(MaxLevel(notes(apiv1.NoteLevel_ERROR, apiv1.NoteLevel_WARNING))).To(Equal(apiv1.NoteLevel_ERROR))
+	})
+})
+
+var _ = Describe("Test ComputeIDStable", func() {
+	note := func() *apiv1.Note {
+		return &apiv1.Note{
+			Type:    "sample-type",
+			Summary: "Sample summary - ${name}",
+			Msg:     "Sample message for ${name}",
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr:    map[string]string{"name": "reviews", "namespace": "default"},
+		}
+	}
+
+	It("Returns a SHA256 checksum", func() {
+		Expect(ComputeIDStable(note())).To(HaveLen(64))
+		Expect(ComputeIDStable(note())).To(Equal(ComputeIDStable(note())))
+	})
+
+	It("Ignores fields other than Type, Summary and Attr", func() {
+		id := ComputeIDStable(note())
+		n := note()
+		n.Msg = "Another message for ${name}"
+		n.Level = apiv1.NoteLevel_ERROR
+		n.Id = "previous-id"
+		Expect(ComputeIDStable(n)).To(Equal(id))
+		Expect(ComputeID(n)).NotTo(Equal(ComputeID(note())))
+	})
+
+	It("Changes with the Type, Summary and Attr", func() {
+		id := ComputeIDStable(note())
+		n := note()
+		n.Type = "other-type"
+		Expect(ComputeIDStable(n)).NotTo(Equal(id))
+		n = note()
+		n.Summary = "Other summary"
+		Expect(ComputeIDStable(n)).NotTo(Equal(id))
+		n = note()
+		n.Attr["name"] = "ratings"
+		Expect(ComputeIDStable(n)).NotTo(Equal(id))
+		n = note()
+		n.Attr = map[string]string{"name": "reviews", "namespace": "default", "port": "80"}
+		Expect(ComputeIDStable(n)).NotTo(Equal(id))
+	})
+})
+
+var _ = Describe("Test ParseImage", func() {
+	It("Parses fully qualified images", func() {
+		Expect(ParseImage("docker.io/istio/proxyv2:1.2.3")).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "istio/proxyv2", Tag: "1.2.3"}))
+		Expect(ParseImage("gcr.io/istio-release/pilot:1.4.0@sha256:abcdef")).To(Equal(
+			ImageRef{Registry: "gcr.io", Repository: "istio-release/pilot", Tag: "1.4.0", Digest: "sha256:abcdef"}))
+	})
+
+	It("Defaults the registry and tag", func() {
+		Expect(ParseImage("istio/proxyv2")).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "istio/proxyv2", Tag: "latest"}))
+		Expect(ParseImage("nginx:1.17")).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "library/nginx", Tag: "1.17"}))
+		Expect(ParseImage("istio/proxyv2@sha256:abcdef")).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "istio/proxyv2", Digest: "sha256:abcdef"}))
+	})
+
+	It("Handles ports in the registry host", func() {
+		Expect(ParseImage("localhost:5000/foo")).To(Equal(
+			ImageRef{Registry: "localhost:5000", Repository: "foo", Tag: "latest"}))
+		Expect(ParseImage("localhost/foo:1.0")).To(Equal(
+			ImageRef{Registry: "localhost", Repository: "foo", Tag: "1.0"}))
+		Expect(ParseImage("registry.local:5000/istio/proxyv2:1.4.0")).To(Equal(
+			ImageRef{Registry: "registry.local:5000", Repository: "istio/proxyv2", Tag: "1.4.0"}))
+	})
+
+	It("Formats the fully qualified image", func() {
+		ref, err := ParseImage("nginx")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ref.String()).To(Equal("docker.io/library/nginx:latest"))
+	})
+
+	It("Fails for malformed images", func() {
+		for _, image := range []string{"", "istio/proxyv2:", "istio/proxyv2@abcdef", "localhost:5000/"} {
+			_, err := ParseImage(image)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("Parses the images of containers", func() {
+		spec := corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: IstioInitContainerName, Image: "istio/proxy_init:1.4.0"}},
+			Containers:     []corev1.Container{{Name: IstioProxyContainerName, Image: "istio/proxyv2:1.4.0"}},
+		}
+		Expect(ParsedImage(IstioProxyContainerName, spec)).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "istio/proxyv2", Tag: "1.4.0"}))
+		Expect(ParsedInitImage(IstioInitContainerName, spec)).To(Equal(
+			ImageRef{Registry: "docker.io", Repository: "istio/proxy_init", Tag: "1.4.0"}))
+		_, err := ParsedImage("missing", spec)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// failingPodLister fails to list the pods of one namespace.
+type failingPodLister struct {
+	corev1listers.PodLister
+	namespace string
+}
+
+func (l failingPodLister) Pods(namespace string) corev1listers.PodNamespaceLister {
+	if namespace == l.namespace {
+		return failingPodNamespaceLister{}
+	}
+	return l.PodLister.Pods(namespace)
+}
+
+type failingPodNamespaceLister struct {
+	corev1listers.PodNamespaceLister
+}
+
+func (failingPodNamespaceLister) List(labels.Selector) ([]*corev1.Pod, error) {
+	return nil, errors.New("list failed")
+}
+
+var _ = Describe("Test parallel listing in the mesh", func() {
+	newIndexer := func(objs ...interface{}) cache.Indexer {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, o := range objs {
+			indexer.Add(o)
+		}
+		return indexer
+	}
+	meta := func(namespace, name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: namespace}
+	}
+	namespaces := []interface{}{}
+	pods := []interface{}{}
+	services := []interface{}{}
+	endpoints := []interface{}{}
+	for _, ns := range []string{"ns-c", "ns-a", "ns-b", "default"} {
+		namespaces = append(namespaces, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: ns, Labels: map[string]string{"istio-injection": "enabled"}}})
+		for _, name := range []string{"web", "api"} {
+			pods = append(pods, &corev1.Pod{
+				ObjectMeta: meta(ns, name),
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: IstioProxyContainerName}}},
+			})
+			services = append(services, &corev1.Service{ObjectMeta: meta(ns, name)})
+			endpoints = append(endpoints, &corev1.Endpoints{ObjectMeta: meta(ns, name)})
+		}
+	}
+	pods = append(pods, &corev1.Pod{ObjectMeta: meta("ns-a", "no-sidecar")})
+	services = append(services, &corev1.Service{ObjectMeta: meta("default", "kubernetes")})
+	endpoints = append(endpoints, &corev1.Endpoints{ObjectMeta: meta("default", "kubernetes")})
+	nsLister := corev1listers.NewNamespaceLister(newIndexer(namespaces...))
+	podLister := corev1listers.NewPodLister(newIndexer(pods...))
+	expNames := []string{
+		"default/api", "default/web", "ns-a/api", "ns-a/web",
+		"ns-b/api", "ns-b/web", "ns-c/api", "ns-c/web",
+	}
+	names := func(objs interface{}) []string {
+		n := []string{}
+		switch l := objs.(type) {
+		case []*corev1.Pod:
+			for _, o := range l {
+				n = append(n, o.Namespace+"/"+o.Name)
+			}
+		case []*corev1.Service:
+			for _, o := range l {
+				n = append(n, o.Namespace+"/"+o.Name)
+			}
+		case []*corev1.Endpoints:
+			for _, o := range l {
+				n = append(n, o.Namespace+"/"+o.Name)
+			}
+		}
+		return n
+	}
+
+	It("Lists the same resources as the serial variants sorted by namespace and name", func() {
+		p, err := ListPodsInMeshParallel(nsLister, podLister)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(p)).To(Equal(expNames))
+		serial, err := ListPodsInMesh(nsLister, podLister)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p).To(ConsistOf(serial))
+
+		s, err := ListServicesInMeshParallel(nsLister, corev1listers.NewServiceLister(newIndexer(services...)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(s)).To(Equal(expNames))
+
+		e, err := ListEndpointsInMeshParallel(nsLister, corev1listers.NewEndpointsLister(newIndexer(endpoints...)))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(e)).To(Equal(expNames))
+	})
+
+	It("Returns the error of a failing namespace", func() {
+		_, err := ListPodsInMeshParallel(nsLister, failingPodLister{podLister, "ns-b"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Bounds the workers and stops after the first error", func() {
+		ns := []*corev1.Namespace{}
+		for _, n := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+			ns = append(ns, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: n}})
+		}
+		var mu sync.Mutex
+		running, maxRunning, calls := 0, 0, 0
+		err := listInNamespaces(ns, 3, func(string) error {
+			mu.Lock()
+			running, calls = running+1, calls+1
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(len(ns)))
+		Expect(maxRunning <= 3).To(BeTrue())
+
+		calls = 0
+		err = listInNamespaces(ns, 1, func(n string) error {
+			calls++
+			if n == "b" {
+				return errors.New("list failed")
+			}
+			return nil
+		})
+		Expect(err).To(MatchError("list failed"))
+		Expect(calls).To(Equal(2))
+	})
+})
+
+var _ = Describe("Test ListServicesInMeshFiltered", func() {
+	indexer := func(objs ...interface{}) cache.Indexer {
+		i := cache.NewIndexer(cache.MetaNamespaceKeyFunc,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		for _, o := range objs {
+			i.Add(o)
+		}
+		return i
+	}
+	namespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Labels: map[string]string{"istio-injection": "enabled"}}}
+	}
+	service := func(namespace, name string, spec corev1.ServiceSpec) *corev1.Service {
+		return &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}
+	}
+	nsLister := corev1listers.NewNamespaceLister(indexer(namespace("default"), namespace("legacy")))
+	svcLister := corev1listers.NewServiceLister(indexer(
+		service("default", "kubernetes", corev1.ServiceSpec{ClusterIP: "10.0.0.1"}),
+		service("default", "reviews", corev1.ServiceSpec{ClusterIP: "10.0.0.2"}),
+		service("default", "db", corev1.ServiceSpec{ClusterIP: corev1.ClusterIPNone}),
+		service("default", "payments", corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "pay.example.com"}),
+		service("legacy", "ratings", corev1.ServiceSpec{ClusterIP: "10.0.0.3"}),
+	))
+	names := func(services []*corev1.Service) []string {
+		n := []string{}
+		for _, s := range services {
+			n = append(n, s.Namespace+"/"+s.Name)
+		}
+		return n
+	}
+
+	It("Includes all Services but kubernetes with the default options", func() {
+		services, err := ListServicesInMeshFiltered(nsLister, svcLister, ServiceFilterOpts{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(services)).To(ConsistOf("default/reviews", "default/db", "default/payments", "legacy/ratings"))
+		all, err := ListServicesInMesh(nsLister, svcLister)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(all).To(ConsistOf(services))
+	})
+
+	It("Excludes headless and ExternalName Services", func() {
+		services, err := ListServicesInMeshFiltered(nsLister, svcLister,
+			ServiceFilterOpts{ExcludeHeadless: true, ExcludeExternalName: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(services)).To(ConsistOf("default/reviews", "legacy/ratings"))
+	})
+
+	It("Excludes Services in the listed Namespaces", func() {
+		services, err := ListServicesInMeshFiltered(nsLister, svcLister,
+			ServiceFilterOpts{ExcludeNamespaces: []string{"default"}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(services)).To(ConsistOf("legacy/ratings"))
+	})
+
+	It("Keeps Services named kubernetes outside the default Namespace", func() {
+		nsLister := corev1listers.NewNamespaceLister(indexer(namespace("default"), namespace("my-app")))
+		svcLister := corev1listers.NewServiceLister(indexer(
+			service("default", "kubernetes", corev1.ServiceSpec{ClusterIP: "10.0.0.1"}),
+			service("my-app", "kubernetes", corev1.ServiceSpec{ClusterIP: "10.0.0.4"}),
+		))
+		services, err := ListServicesInMesh(nsLister, svcLister)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(services)).To(Equal([]string{"my-app/kubernetes"}))
+		services, err = ListServicesInMeshParallel(nsLister, svcLister)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names(services)).To(Equal([]string{"my-app/kubernetes"}))
+	})
+})
+
+var _ = Describe("Test ExemptedNamespace", func() {
+	AfterEach(func() {
+		SetExemptedNamespaces([]string{"kube-system", "kube-public", "istio-system"})
+	})
+
+	It("Exempts the default Namespaces", func() {
+		Expect(DefaultExemptedNamespaces()).To(Equal([]string{"istio-system", "kube-public", "kube-system"}))
+		Expect(ExemptedNamespace("istio-system")).To(BeTrue())
+		Expect(ExemptedNamespace("default")).To(BeFalse())
+	})
+
+	It("Adds exempted Namespaces", func() {
+		AddExemptedNamespace("istio-control")
+		Expect(ExemptedNamespace("istio-control")).To(BeTrue())
+		Expect(ExemptedNamespace("kube-system")).To(BeTrue())
+		Expect(DefaultExemptedNamespaces()).To(Equal([]string{"istio-control", "istio-system", "kube-public", "kube-system"}))
+	})
+
+	It("Replaces the exempted Namespaces", func() {
+		SetExemptedNamespaces([]string{"istio-control"})
+		Expect(ExemptedNamespace("istio-control")).To(BeTrue())
+		Expect(ExemptedNamespace("istio-system")).To(BeFalse())
+		Expect(DefaultExemptedNamespaces()).To(Equal([]string{"istio-control"}))
+	})
+
+	It("Is safe for concurrent use", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				AddExemptedNamespace("infra")
+			}()
+			go func() {
+				defer wg.Done()
+				ExemptedNamespace("infra")
+				DefaultExemptedNamespaces()
+			}()
+		}
+		wg.Wait()
+		Expect(ExemptedNamespace("infra")).To(BeTrue())
+	})
+})
+
+var _ = Describe("ExportTo", func() {
+	It("Parses the exportTo annotation of a Service", func() {
+		s := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{IstioExportToAnnotation: " ., bookinfo "},
+		}}
+		Expect(ServiceExportTo(s, &meshv1alpha1.MeshConfig{})).To(Equal([]string{".", "bookinfo"}))
+	})
+
+	It("Falls back to the mesh defaults", func() {
+		mc := &meshv1alpha1.MeshConfig{
+			DefaultServiceExportTo:         []string{"."},
+			DefaultDestinationRuleExportTo: []string{"bookinfo"},
+		}
+		Expect(ServiceExportTo(&corev1.Service{}, mc)).To(Equal([]string{"."}))
+		Expect(DestinationRuleExportTo(&v1alpha3.DestinationRule{}, mc)).To(Equal([]string{"bookinfo"}))
+		Expect(ServiceEntryExportTo(&v1alpha3.ServiceEntry{}, mc)).To(Equal([]string{"."}))
+		mc = &meshv1alpha1.MeshConfig{}
+		Expect(ServiceExportTo(&corev1.Service{}, mc)).To(Equal([]string{ExportToAll}))
+		Expect(DestinationRuleExportTo(&v1alpha3.DestinationRule{}, mc)).To(Equal([]string{ExportToAll}))
+	})
+
+	It("Resolves visibility in a Namespace", func() {
+		Expect(ExportedTo([]string{ExportToAll}, "default", "bookinfo")).To(BeTrue())
+		Expect(ExportedTo([]string{ExportToNamespace}, "default", "default")).To(BeTrue())
+		Expect(ExportedTo([]string{ExportToNamespace}, "default", "bookinfo")).To(BeFalse())
+		Expect(ExportedTo([]string{"bookinfo"}, "default", "bookinfo")).To(BeTrue())
+		Expect(ExportedTo([]string{}, "default", "default")).To(BeFalse())
+	})
+})
+
+var _ = Describe("FormatNote", func() {
+	note := func(msg string) *apiv1.Note {
+		return &apiv1.Note{
+			Summary: "Missing prefix - ${service_name}",
+			Msg:     msg,
+			Attr:    map[string]string{"service_name": "reviews", "namespace": "default"},
+		}
+	}
+
+	It("Replaces the placeholders with the attributes", func() {
+		msg, err := FormatNote(note("Service ${service_name} in {{.namespace}} has no prefix."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg).To(Equal("Service reviews in default has no prefix."))
+		summary, err := FormatNoteSummary(note(""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal("Missing prefix - reviews"))
+	})
+
+	It("Marks missing attributes", func() {
+		msg, err := FormatNote(note("Port ${port} of ${service_name}, {{.protocol}}."))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg).To(Equal("Port <missing:port> of reviews, <missing:protocol>."))
+	})
+
+	It("Returns an error for an invalid template", func() {
+		_, err := FormatNote(note("Service {{.service_name"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/golang/glog"
+	v1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+)
+
+// ControlPlaneDeployment returns the istio-pilot Deployment, or the istiod
+// Deployment in newer releases. It returns nil without an error if neither
+// Deployment exists.
+func ControlPlaneDeployment(deployLister appsv1.DeploymentLister) (*v1.Deployment, error) {
+	for _, n := range []string{IstioPilotDeploymentName, IstiodDeploymentName} {
+		d, err := deployLister.Deployments(IstioNamespace).Get(n)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			glog.Errorf("Failed to retrieve deployment: %s error: %s", n, err)
+			return nil, err
+		}
+		return d, nil
+	}
+	return nil, nil
+}
+
+// MeshVersion returns the version of the Istio control plane, parsed from the
+// image tag of the discovery container of the istio-pilot Deployment, or the
+// istiod Deployment in newer releases.
+func MeshVersion(deployLister appsv1.DeploymentLister) (*version.Version, error) {
+	d, err := ControlPlaneDeployment(deployLister)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, fmt.Errorf("Failed to find deployment %s or %s in namespace %s",
+			IstioPilotDeploymentName, IstiodDeploymentName, IstioNamespace)
+	}
+	ref, err := ParsedImage(IstioDiscoveryContainerName, d.Spec.Template.Spec)
+	if err != nil {
+		return nil, err
+	}
+	v, err := version.ParseSemantic(ref.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse version of control plane image %s: %s", ref, err)
+	}
+	return v, nil
+}
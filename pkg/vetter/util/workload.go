@@ -0,0 +1,56 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	appsv1 "k8s.io/client-go/listers/apps/v1"
+)
+
+// Workload identifies the controller managing a pod, e.g. the Deployment
+// owning the pod's ReplicaSet. A pod without a controller is its own
+// workload of kind "Pod".
+type Workload struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// String returns the workload as "<kind>/<name>".
+func (w Workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+// ResolveWorkload returns the workload of the pod. Pods owned by a ReplicaSet
+// resolve to the Deployment owning the ReplicaSet, if any. The rsLister may be
+// nil, in which case ReplicaSets aren't resolved further.
+func ResolveWorkload(p *corev1.Pod, rsLister appsv1.ReplicaSetLister) Workload {
+	owner := metav1.GetControllerOf(p)
+	if owner == nil {
+		return Workload{Kind: "Pod", Name: p.Name, Namespace: p.Namespace}
+	}
+	if owner.Kind == "ReplicaSet" && rsLister != nil {
+		rs, err := rsLister.ReplicaSets(p.Namespace).Get(owner.Name)
+		if err == nil {
+			if d := metav1.GetControllerOf(rs); d != nil && d.Kind == "Deployment" {
+				return Workload{Kind: d.Kind, Name: d.Name, Namespace: p.Namespace}
+			}
+		}
+	}
+	return Workload{Kind: owner.Kind, Name: owner.Name, Namespace: p.Namespace}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualserviceauthoritymatch vets the http match conditions of
+// VirtualService resources and generates notes if an authority match can't
+// match any of the hosts of the VirtualService.
+package virtualserviceauthoritymatch
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                    = "VirtualServiceAuthorityMatch"
+	unreachableAuthorityType    = "virtualservice-unreachable-authority"
+	unreachableAuthoritySummary = "Authority match outside hosts - ${vs_name}"
+	unreachableAuthorityMsg     = "The http route ${route} of VirtualService ${vs_name}" +
+		" in namespace ${namespace} matches the authority ${authority}, which is" +
+		" not one of its hosts ${host_list}. The VirtualService only receives" +
+		" traffic for its hosts, so the match never applies. Consider adding the" +
+		" authority to the hosts or fixing the match."
+)
+
+// VirtualServiceAuthorityMatch implements Vetter interface
+type VirtualServiceAuthorityMatch struct {
+	nsLister  v1.NamespaceLister
+	svcLister v1.ServiceLister
+	vsLister  netv1alpha3.VirtualServiceLister
+}
+
+// hostCovers returns true if requests for the authority are sent to the
+// VirtualService host. The authority may include a port and, for Kubernetes
+// services, may be of the form "<name>.<namespace>" or
+// "<name>.<namespace>.svc".
+func hostCovers(r *util.HostResolver, host, authority, namespace string) bool {
+	if h, _, err := net.SplitHostPort(authority); err == nil {
+		authority = h
+	}
+	host, authority = r.Normalize(host, namespace), r.Normalize(authority, namespace)
+	switch {
+	case host == "*":
+		return true
+	case strings.HasPrefix(host, "*."):
+		return strings.HasSuffix(authority, host[1:])
+	}
+	return host == authority || host == authority+util.KubernetesDomainSuffix
+}
+
+// authorityReachable returns true if the authority match can match a request
+// for any of the hosts. Prefix and regex matches are reachable if they match
+// the name of a host, or if a host is a wildcard.
+func authorityReachable(r *util.HostResolver, m *istiov1alpha3.StringMatch,
+	hosts []string, namespace string) bool {
+	for _, h := range hosts {
+		if strings.HasPrefix(h, "*") && m.GetExact() == "" {
+			return true
+		}
+		names := []string{strings.ToLower(h), r.Normalize(h, namespace)}
+		switch m.GetMatchType().(type) {
+		case *istiov1alpha3.StringMatch_Exact:
+			if hostCovers(r, h, m.GetExact(), namespace) {
+				return true
+			}
+		case *istiov1alpha3.StringMatch_Prefix:
+			for _, n := range names {
+				if strings.HasPrefix(n, strings.ToLower(m.GetPrefix())) {
+					return true
+				}
+			}
+		case *istiov1alpha3.StringMatch_Regex:
+			re, err := regexp.Compile("^(?:" + m.GetRegex() + ")$")
+			if err != nil {
+				return true
+			}
+			for _, n := range names {
+				if re.MatchString(n) {
+					return true
+				}
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// authorityString returns the value of the authority match for display.
+func authorityString(m *istiov1alpha3.StringMatch) string {
+	switch m.GetMatchType().(type) {
+	case *istiov1alpha3.StringMatch_Prefix:
+		return m.GetPrefix() + "*"
+	case *istiov1alpha3.StringMatch_Regex:
+		return m.GetRegex()
+	}
+	return m.GetExact()
+}
+
+// createUnreachableAuthorityNotes generates a note for every http authority
+// match which can't match any of the hosts of its VirtualService.
+func createUnreachableAuthorityNotes(svcs []*corev1.Service,
+	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	resolver := util.NewHostResolver(svcs)
+	for _, vs := range vsList {
+		hosts := vs.Spec.GetHosts()
+		for i, r := range vs.Spec.GetHttp() {
+			for _, m := range r.GetMatch() {
+				a := m.GetAuthority()
+				if a == nil || authorityReachable(resolver, a, hosts, vs.Namespace) {
+					continue
+				}
+				notes = append(notes, &apiv1.Note{
+					Type:    unreachableAuthorityType,
+					Summary: unreachableAuthoritySummary,
+					Msg:     unreachableAuthorityMsg,
+					Level:   apiv1.NoteLevel_WARNING,
+					Attr: map[string]string{
+						"vs_name":   vs.Name,
+						"namespace": vs.Namespace,
+						"route":     util.HTTPRouteName(r, i),
+						"authority": authorityString(a),
+						"host_list": strings.Join(hosts, ", "),
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (v *VirtualServiceAuthorityMatch) Vet() ([]*apiv1.Note, error) {
+	svcs, err := util.ListServicesInMesh(v.nsLister, v.svcLister)
+	if err != nil {
+		if n := util.IstioInitializerDisabledNote(err.Error(), vetterID,
+			unreachableAuthorityType); n != nil {
+			return []*apiv1.Note{n}, nil
+		}
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(v.nsLister, v.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createUnreachableAuthorityNotes(svcs, vsList), nil
+}
+
+// Info returns information about the vetter
+func (v *VirtualServiceAuthorityMatch) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "VirtualServiceAuthorityMatch" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *VirtualServiceAuthorityMatch {
+	return &VirtualServiceAuthorityMatch{
+		nsLister:  factory.K8s().Core().V1().Namespaces().Lister(),
+		svcLister: factory.K8s().Core().V1().Services().Lister(),
+		vsLister:  factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
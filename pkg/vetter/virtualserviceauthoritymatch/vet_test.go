@@ -0,0 +1,90 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualserviceauthoritymatch
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(hosts []string, authority string) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: hosts,
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Name: "api",
+							Match: []*istiov1alpha3.HTTPMatchRequest{
+								{
+									Authority: &istiov1alpha3.StringMatch{
+										MatchType: &istiov1alpha3.StringMatch_Exact{Exact: authority},
+									},
+								},
+							},
+							Route: []*istiov1alpha3.HTTPRouteDestination{
+								{Destination: &istiov1alpha3.Destination{Host: "web"}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService authority matches", func() {
+	It("creates zero notes for an authority in hosts", func() {
+		vsList := virtualService([]string{"web", "api.example.com"}, "api.example.com:8080")
+		Expect(createUnreachableAuthorityNotes(nil, vsList)).To(HaveLen(0))
+		vsList = virtualService([]string{"web"}, "web.default")
+		Expect(createUnreachableAuthorityNotes(nil, vsList)).To(HaveLen(0))
+		vsList = virtualService([]string{"web"}, "web.default.svc:80")
+		Expect(createUnreachableAuthorityNotes(nil, vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for an authority covered by a wildcard host", func() {
+		vsList := virtualService([]string{"*.example.com"}, "api.example.com")
+		Expect(createUnreachableAuthorityNotes(nil, vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for an authority not in hosts", func() {
+		expNote := &apiv1.Note{
+			Type:    unreachableAuthorityType,
+			Summary: unreachableAuthoritySummary,
+			Msg:     unreachableAuthorityMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":   "web",
+				"namespace": "default",
+				"route":     "api",
+				"authority": "api.example.org",
+				"host_list": "web, *.example.com",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		vsList := virtualService([]string{"web", "*.example.com"}, "api.example.org")
+		Expect(createUnreachableAuthorityNotes(nil, vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
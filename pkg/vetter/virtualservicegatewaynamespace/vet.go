@@ -0,0 +1,150 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualservicegatewaynamespace vets the namespaced gateway
+// references of VirtualService resources and generates notes if the
+// referenced Namespace has no Gateway of that name.
+package virtualservicegatewaynamespace
+
+import (
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "VirtualServiceGatewayNamespace"
+	missingGatewayNoteType = "vs-gateway-not-in-namespace"
+	missingGatewaySummary  = "Gateway not found in namespace - ${gateway}"
+	missingGatewayMsg      = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" binds the gateway ${gateway}, but there is no Gateway ${gateway_name}" +
+		" in namespace ${gateway_namespace}. The routes of the VirtualService are" +
+		" not applied to any gateway."
+	otherNamespaceNoteType = "vs-gateway-in-other-namespace"
+	otherNamespaceSummary  = "Gateway found in another namespace - ${gateway}"
+	otherNamespaceMsg      = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" binds the gateway ${gateway}, but there is no Gateway ${gateway_name}" +
+		" in namespace ${gateway_namespace}. A Gateway of that name exists in" +
+		" namespace(s) ${gateway_namespace_list}. Gateway references are not" +
+		" resolved across namespaces, consider fixing the namespace of the reference."
+)
+
+// GatewayNamespace implements Vetter interface
+type GatewayNamespace struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+	gwLister netv1alpha3.GatewayLister
+}
+
+// createGatewayNamespaceNotes generates a note for every "<namespace>/<name>"
+// gateway reference of a VirtualService which doesn't match a Gateway.
+// References whose name exists in other Namespaces get a distinct note type
+// listing those Namespaces.
+func createGatewayNamespaceNotes(vsList []*v1alpha3.VirtualService,
+	gateways []*v1alpha3.Gateway) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	gwNamespaces := map[string][]string{}
+	for _, gw := range gateways {
+		gwNamespaces[gw.Name] = append(gwNamespaces[gw.Name], gw.Namespace)
+	}
+	for _, vs := range vsList {
+		for _, g := range vs.Spec.GetGateways() {
+			parts := strings.SplitN(g, "/", 2)
+			if len(parts) != 2 {
+				continue
+			}
+			ns, name := parts[0], parts[1]
+			found := false
+			for _, n := range gwNamespaces[name] {
+				if n == ns {
+					found = true
+					break
+				}
+			}
+			if found {
+				continue
+			}
+			attr := map[string]string{
+				"vs_name":           vs.Name,
+				"namespace":         vs.Namespace,
+				"gateway":           g,
+				"gateway_name":      name,
+				"gateway_namespace": ns,
+			}
+			if others := gwNamespaces[name]; len(others) > 0 {
+				others = append([]string{}, others...)
+				sort.Strings(others)
+				attr["gateway_namespace_list"] = strings.Join(others, ", ")
+				notes = append(notes, &apiv1.Note{
+					Type:    otherNamespaceNoteType,
+					Summary: otherNamespaceSummary,
+					Msg:     otherNamespaceMsg,
+					Level:   apiv1.NoteLevel_ERROR,
+					Attr:    attr,
+				})
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    missingGatewayNoteType,
+				Summary: missingGatewaySummary,
+				Msg:     missingGatewayMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr:    attr,
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (g *GatewayNamespace) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(g.nsLister, g.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	gateways, err := g.gwLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve Gateways: %s", err)
+		return nil, err
+	}
+	return createGatewayNamespaceNotes(vsList, gateways), nil
+}
+
+// Info returns information about the vetter
+func (g *GatewayNamespace) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "GatewayNamespace" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *GatewayNamespace {
+	return &GatewayNamespace{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+		gwLister: factory.Istio().Networking().V1alpha3().Gateways().Lister(),
+	}
+}
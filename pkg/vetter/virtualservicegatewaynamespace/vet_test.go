@@ -0,0 +1,101 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualservicegatewaynamespace
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func gateway(namespace, name string) *v1alpha3.Gateway {
+	return &v1alpha3.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	}
+}
+
+func virtualService(gateways ...string) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts:    []string{"api.example.com"},
+					Gateways: gateways,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService gateway namespace", func() {
+	attr := map[string]string{
+		"vs_name":           "api",
+		"namespace":         "default",
+		"gateway":           "istio-system/public-gateway",
+		"gateway_name":      "public-gateway",
+		"gateway_namespace": "istio-system",
+	}
+
+	It("creates zero notes for a correct namespaced reference", func() {
+		gateways := []*v1alpha3.Gateway{gateway("istio-system", "public-gateway")}
+		notes := createGatewayNamespaceNotes(
+			virtualService("mesh", "public-gateway", "istio-system/public-gateway"), gateways)
+		Expect(notes).To(HaveLen(0))
+	})
+
+	It("creates a note if the namespace has no Gateway of that name", func() {
+		gateways := []*v1alpha3.Gateway{gateway("istio-system", "private-gateway")}
+		notes := createGatewayNamespaceNotes(
+			virtualService("istio-system/public-gateway"), gateways)
+		expNote := &apiv1.Note{
+			Type:    missingGatewayNoteType,
+			Summary: missingGatewaySummary,
+			Msg:     missingGatewayMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr:    attr,
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("lists the namespaces of same-named Gateways", func() {
+		gateways := []*v1alpha3.Gateway{
+			gateway("ingress", "public-gateway"),
+			gateway("default", "public-gateway"),
+		}
+		notes := createGatewayNamespaceNotes(
+			virtualService("istio-system/public-gateway"), gateways)
+		expAttr := map[string]string{"gateway_namespace_list": "default, ingress"}
+		for k, v := range attr {
+			expAttr[k] = v
+		}
+		expNote := &apiv1.Note{
+			Type:    otherNamespaceNoteType,
+			Summary: otherNamespaceSummary,
+			Msg:     otherNamespaceMsg,
+			Level:   apiv1.NoteLevel_ERROR,
+			Attr:    expAttr,
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
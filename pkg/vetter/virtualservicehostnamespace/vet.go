@@ -0,0 +1,142 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualservicehostnamespace vets the FQDN hosts of VirtualService
+// resources and generates notes if they refer to namespaces which don't exist
+// in the cluster.
+package virtualservicehostnamespace
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"github.com/golang/glog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "VirtualServiceHostNamespace"
+	missingNamespaceNoteType = "host-namespace-not-found"
+	missingNamespaceSummary  = "Host in missing namespace - ${vs_name}"
+	missingNamespaceMsg      = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" has host(s) ${hostname_list} in namespace(s) ${missing_namespaces} which" +
+		" don't exist. Traffic for these hosts routes to nothing. Consider removing" +
+		" the hosts or correcting their namespace."
+)
+
+// HostNamespace implements Vetter interface
+type HostNamespace struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// createMissingNamespaceNotes generates notes for the VirtualServices whose
+// hosts or route destination hosts are Service FQDNs in namespaces missing
+// from namespaces. Short names are qualified with the namespace of the
+// VirtualService which exists, so they are skipped.
+func createMissingNamespaceNotes(namespaces []*corev1.Namespace,
+	vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	nsMap := map[string]bool{}
+	for _, ns := range namespaces {
+		nsMap[ns.Name] = true
+	}
+	for _, vs := range vsList {
+		hosts := append([]string{}, vs.Spec.GetHosts()...)
+		for _, r := range vs.Spec.GetHttp() {
+			for _, dw := range r.GetRoute() {
+				hosts = append(hosts, dw.GetDestination().GetHost())
+			}
+		}
+		for _, r := range vs.Spec.GetTcp() {
+			for _, dw := range r.GetRoute() {
+				hosts = append(hosts, dw.GetDestination().GetHost())
+			}
+		}
+		for _, r := range vs.Spec.GetTls() {
+			for _, dw := range r.GetRoute() {
+				hosts = append(hosts, dw.GetDestination().GetHost())
+			}
+		}
+		seenHost, seenNs := map[string]bool{}, map[string]bool{}
+		missingHosts, missingNs := []string{}, []string{}
+		for _, h := range hosts {
+			_, ns, ok := util.SplitServiceFQDN(h)
+			if !ok || nsMap[ns] || seenHost[h] {
+				continue
+			}
+			seenHost[h] = true
+			missingHosts = append(missingHosts, h)
+			if !seenNs[ns] {
+				seenNs[ns] = true
+				missingNs = append(missingNs, ns)
+			}
+		}
+		if len(missingHosts) == 0 {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    missingNamespaceNoteType,
+			Summary: missingNamespaceSummary,
+			Msg:     missingNamespaceMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":            vs.Name,
+				"namespace":          vs.Namespace,
+				"hostname_list":      strings.Join(missingHosts, ", "),
+				"missing_namespaces": strings.Join(missingNs, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (h *HostNamespace) Vet() ([]*apiv1.Note, error) {
+	namespaces, err := h.nsLister.List(labels.Everything())
+	if err != nil {
+		glog.Errorf("Failed to retrieve namespaces: %s", err)
+		return nil, err
+	}
+	vsList, err := util.ListVirtualServicesInMesh(h.nsLister, h.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createMissingNamespaceNotes(namespaces, vsList), nil
+}
+
+// Info returns information about the vetter
+func (h *HostNamespace) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "HostNamespace" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *HostNamespace {
+	return &HostNamespace{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
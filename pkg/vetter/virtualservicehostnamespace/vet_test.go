@@ -0,0 +1,85 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualservicehostnamespace
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(hosts ...string) *v1alpha3.VirtualService {
+	return &v1alpha3.VirtualService{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "default"},
+		Spec: v1alpha3.VirtualServiceSpec{
+			VirtualService: istiov1alpha3.VirtualService{
+				Hosts: hosts,
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService hosts in missing namespaces", func() {
+	namespaces := []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "bookinfo"}},
+	}
+
+	It("creates zero notes for an FQDN in a live namespace", func() {
+		vsList := []*v1alpha3.VirtualService{
+			virtualService("reviews.bookinfo.svc.cluster.local"),
+		}
+		Expect(createMissingNamespaceNotes(namespaces, vsList)).To(HaveLen(0))
+	})
+
+	It("skips short name hosts", func() {
+		vsList := []*v1alpha3.VirtualService{virtualService("reviews")}
+		Expect(createMissingNamespaceNotes(namespaces, vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for FQDNs in a deleted namespace", func() {
+		vs := virtualService("reviews.staging.svc.cluster.local", "reviews")
+		vs.Spec.Http = []*istiov1alpha3.HTTPRoute{
+			{
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					{Destination: &istiov1alpha3.Destination{Host: "reviews.staging.svc.cluster.local"}},
+					{Destination: &istiov1alpha3.Destination{Host: "ratings.staging.svc.cluster.local"}},
+				},
+			},
+		}
+		expNote := &apiv1.Note{
+			Type:    missingNamespaceNoteType,
+			Summary: missingNamespaceSummary,
+			Msg:     missingNamespaceMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":            "routes",
+				"namespace":          "default",
+				"hostname_list":      "reviews.staging.svc.cluster.local, ratings.staging.svc.cluster.local",
+				"missing_namespaces": "staging",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		notes := createMissingNamespaceNotes(namespaces, []*v1alpha3.VirtualService{vs})
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualservicemeshgateway vets the gateways of VirtualService
+// resources and generates notes if they bind both the reserved mesh gateway
+// and named gateways.
+package virtualservicemeshgateway
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "VirtualServiceMeshGateway"
+	meshGateway             = "mesh"
+	dualExposureNoteType    = "mesh-and-named-gateways"
+	dualExposureNoteSummary = "Mesh and named gateways - ${vs_name}"
+	dualExposureNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" binds the mesh gateway and the gateway(s) ${gateway_list}. Its routes" +
+		" apply to traffic inside the mesh and to traffic entering through these" +
+		" gateways. Confirm the hosts ${hostname_list} are intended to be exposed" +
+		" by both."
+)
+
+// MeshGateway implements Vetter interface
+type MeshGateway struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// createDualExposureNotes generates a note for every VirtualService which
+// lists the mesh gateway together with named gateways.
+func createDualExposureNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		mesh := false
+		named := []string{}
+		for _, gw := range vs.Spec.GetGateways() {
+			if gw == meshGateway {
+				mesh = true
+			} else {
+				named = append(named, gw)
+			}
+		}
+		if !mesh || len(named) == 0 {
+			continue
+		}
+		notes = append(notes, &apiv1.Note{
+			Type:    dualExposureNoteType,
+			Summary: dualExposureNoteSummary,
+			Msg:     dualExposureNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":       vs.Name,
+				"namespace":     vs.Namespace,
+				"gateway_list":  strings.Join(named, ", "),
+				"hostname_list": strings.Join(vs.Spec.GetHosts(), ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MeshGateway) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(m.nsLister, m.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createDualExposureNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (m *MeshGateway) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MeshGateway" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MeshGateway {
+	return &MeshGateway{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
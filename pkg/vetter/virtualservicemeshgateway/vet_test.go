@@ -0,0 +1,69 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualservicemeshgateway
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(gateways ...string) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts:    []string{"reviews.example.com"},
+					Gateways: gateways,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService mesh and named gateways", func() {
+	It("creates zero notes for the mesh gateway only", func() {
+		Expect(createDualExposureNotes(virtualService("mesh"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for named gateways only", func() {
+		Expect(createDualExposureNotes(virtualService("istio-system/ingressgateway"))).To(HaveLen(0))
+	})
+
+	It("creates a note for mesh and named gateways", func() {
+		expNote := &apiv1.Note{
+			Type:    dualExposureNoteType,
+			Summary: dualExposureNoteSummary,
+			Msg:     dualExposureNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":       "reviews",
+				"namespace":     "default",
+				"gateway_list":  "istio-system/ingressgateway",
+				"hostname_list": "reviews.example.com",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		vsList := virtualService("mesh", "istio-system/ingressgateway")
+		Expect(createDualExposureNotes(vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualservicemultidomain vets the hosts of VirtualService resources
+// bound to gateways and generates notes if the hosts span more than one
+// registrable domain.
+//
+// The vetter is opt-in, it only runs if enabled with the --enable-vetter
+// flag.
+package virtualservicemultidomain
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "VirtualServiceMultiDomain"
+	meshGateway            = "mesh"
+	multiDomainNoteType    = "vs-hosts-multiple-domains"
+	multiDomainNoteSummary = "VirtualService hosts span multiple domains - ${vs_name}"
+	multiDomainNoteMsg     = "The hosts of the VirtualService ${vs_name} in namespace" +
+		" ${namespace}, bound to the gateway(s) ${gateway_list}, span the domains" +
+		" ${domain_list}. Hosts of unrelated domains in one VirtualService often" +
+		" result from merging config by mistake and need certificates for every" +
+		" domain. Consider splitting the VirtualService by domain."
+)
+
+// secondLevelLabels are labels commonly registered below a country code
+// top-level domain, e.g. "co" in "example.co.uk".
+var secondLevelLabels = map[string]bool{
+	"ac":  true,
+	"co":  true,
+	"com": true,
+	"edu": true,
+	"gov": true,
+	"net": true,
+	"or":  true,
+	"org": true,
+}
+
+// MultiDomain implements Vetter interface
+type MultiDomain struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// registrableDomain returns the registrable domain, or eTLD+1, of the host
+// using a heuristic rather than the public suffix list: the last two labels,
+// or the last three if the host ends in a second-level label under a country
+// code, e.g. "example.co.uk". Hosts without a registrable domain, i.e. short
+// names, cluster-local names, IP addresses and "*", return false.
+func registrableDomain(host string) (string, bool) {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(host, "*."), "."))
+	if !strings.Contains(host, ".") || strings.HasSuffix(host, util.KubernetesDomainSuffix) ||
+		net.ParseIP(host) != nil {
+		return "", false
+	}
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 && secondLevelLabels[labels[len(labels)-2]] {
+		n = 3
+	}
+	return strings.Join(labels[len(labels)-n:], "."), true
+}
+
+// createMultiDomainNotes generates a note for every VirtualService bound to a
+// gateway other than the mesh gateway whose hosts span more than one
+// registrable domain.
+func createMultiDomainNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		gateways := []string{}
+		for _, gw := range vs.Spec.GetGateways() {
+			if gw != meshGateway {
+				gateways = append(gateways, gw)
+			}
+		}
+		if len(gateways) == 0 {
+			continue
+		}
+		domains := map[string]bool{}
+		for _, h := range vs.Spec.GetHosts() {
+			if d, ok := registrableDomain(h); ok {
+				domains[d] = true
+			}
+		}
+		if len(domains) < 2 {
+			continue
+		}
+		domainList := make([]string, 0, len(domains))
+		for d := range domains {
+			domainList = append(domainList, d)
+		}
+		sort.Strings(domainList)
+		notes = append(notes, &apiv1.Note{
+			Type:    multiDomainNoteType,
+			Summary: multiDomainNoteSummary,
+			Msg:     multiDomainNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":      vs.Name,
+				"namespace":    vs.Namespace,
+				"gateway_list": strings.Join(gateways, ", "),
+				"domain_list":  strings.Join(domainList, ", "),
+			},
+		})
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (m *MultiDomain) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(m.nsLister, m.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createMultiDomainNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (m *MultiDomain) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "MultiDomain" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *MultiDomain {
+	return &MultiDomain{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualservicemultidomain
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(gateways []string, hosts ...string) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts:    hosts,
+					Gateways: gateways,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService hosts spanning multiple domains", func() {
+	ingress := []string{"istio-system/ingressgateway"}
+
+	It("creates zero notes for single-domain hosts", func() {
+		Expect(createMultiDomainNotes(virtualService(ingress, "a.com"))).To(HaveLen(0))
+		Expect(createMultiDomainNotes(virtualService(ingress, "a.com", "reviews",
+			"reviews.default.svc.cluster.local", "10.0.0.1"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for subdomains of a single domain", func() {
+		Expect(createMultiDomainNotes(virtualService(ingress,
+			"www.a.com", "api.a.com", "*.eu.a.com", "A.com."))).To(HaveLen(0))
+		Expect(createMultiDomainNotes(virtualService(ingress,
+			"www.shop.co.uk", "api.shop.co.uk"))).To(HaveLen(0))
+	})
+
+	It("creates zero notes without a named gateway", func() {
+		Expect(createMultiDomainNotes(virtualService(nil, "a.com", "b.net"))).To(HaveLen(0))
+		Expect(createMultiDomainNotes(virtualService([]string{"mesh"}, "a.com", "b.net"))).To(HaveLen(0))
+	})
+
+	It("creates a note for hosts of multiple domains", func() {
+		notes := createMultiDomainNotes(virtualService(append(ingress, "mesh"),
+			"www.b.net", "a.com", "api.a.com", "shop.co.uk", "other.co.uk"))
+		expNote := &apiv1.Note{
+			Type:    multiDomainNoteType,
+			Summary: multiDomainNoteSummary,
+			Msg:     multiDomainNoteMsg,
+			Level:   apiv1.NoteLevel_INFO,
+			Attr: map[string]string{
+				"vs_name":      "frontend",
+				"namespace":    "default",
+				"gateway_list": "istio-system/ingressgateway",
+				"domain_list":  "a.com, b.net, other.co.uk, shop.co.uk",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		Expect(notes).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
@@ -0,0 +1,121 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualserviceprefixrewrite vets the URI rewrites of VirtualService
+// HTTP routes and generates notes if a rewrite strips the whole matched
+// prefix.
+package virtualserviceprefixrewrite
+
+import (
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID               = "VirtualServicePrefixRewrite"
+	prefixStripNoteType    = "vs-rewrite-strips-prefix"
+	prefixStripNoteSummary = "Rewrite strips matched prefix - ${vs_name}"
+	prefixStripNoteMsg     = "The VirtualService ${vs_name} in namespace ${namespace}" +
+		" rewrites the URI prefix ${prefix} to ${rewrite}, so a request for" +
+		" ${example_path} reaches the destination as ${rewritten_path}. Confirm" +
+		" the destination serves its paths without the prefix, otherwise its" +
+		" requests fail with 404 errors."
+	rootPath = "/"
+)
+
+// PrefixRewrite implements Vetter interface
+type PrefixRewrite struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// examplePaths returns a path matched by the prefix and the path it is
+// rewritten to by replacing the prefix with rewrite, as Envoy does.
+func examplePaths(prefix, rewrite string) (string, string) {
+	path := prefix + "example"
+	if !strings.HasSuffix(prefix, "/") {
+		path = prefix + "/example"
+	}
+	return path, rewrite + strings.TrimPrefix(path, prefix)
+}
+
+// createPrefixStripNotes generates a note for every URI prefix match of a
+// route which rewrites the URI to "/", stripping the matched prefix.
+func createPrefixStripNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for _, route := range vs.Spec.GetHttp() {
+			rewrite := route.GetRewrite().GetUri()
+			if rewrite != rootPath {
+				continue
+			}
+			for _, m := range route.GetMatch() {
+				prefix := m.GetUri().GetPrefix()
+				if prefix == "" || prefix == rootPath {
+					continue
+				}
+				path, rewritten := examplePaths(prefix, rewrite)
+				notes = append(notes, &apiv1.Note{
+					Type:    prefixStripNoteType,
+					Summary: prefixStripNoteSummary,
+					Msg:     prefixStripNoteMsg,
+					Level:   apiv1.NoteLevel_INFO,
+					Attr: map[string]string{
+						"vs_name":        vs.Name,
+						"namespace":      vs.Namespace,
+						"prefix":         prefix,
+						"rewrite":        rewrite,
+						"example_path":   path,
+						"rewritten_path": rewritten,
+					},
+				})
+			}
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (p *PrefixRewrite) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(p.nsLister, p.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createPrefixStripNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (p *PrefixRewrite) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "PrefixRewrite" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *PrefixRewrite {
+	return &PrefixRewrite{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualserviceprefixrewrite
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func virtualService(prefix string, rewrite *istiov1alpha3.HTTPRewrite) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "gateway-routes", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Http: []*istiov1alpha3.HTTPRoute{
+						{
+							Match: []*istiov1alpha3.HTTPMatchRequest{
+								{
+									Uri: &istiov1alpha3.StringMatch{
+										MatchType: &istiov1alpha3.StringMatch_Prefix{Prefix: prefix},
+									},
+								},
+							},
+							Rewrite: rewrite,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func prefixStripNote(prefix, path, rewritten string) *apiv1.Note {
+	n := &apiv1.Note{
+		Type:    prefixStripNoteType,
+		Summary: prefixStripNoteSummary,
+		Msg:     prefixStripNoteMsg,
+		Level:   apiv1.NoteLevel_INFO,
+		Attr: map[string]string{
+			"vs_name":        "gateway-routes",
+			"namespace":      "default",
+			"prefix":         prefix,
+			"rewrite":        "/",
+			"example_path":   path,
+			"rewritten_path": rewritten,
+		},
+	}
+	n.Id = util.ComputeIDStable(n)
+	return n
+}
+
+var _ = Describe("VirtualService rewrites stripping the matched prefix", func() {
+	It("creates zero notes without a rewrite", func() {
+		Expect(createPrefixStripNotes(virtualService("/service/", nil))).To(HaveLen(0))
+		Expect(createPrefixStripNotes(virtualService("/service/",
+			&istiov1alpha3.HTTPRewrite{Authority: "service.example.com"}))).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a partial rewrite", func() {
+		Expect(createPrefixStripNotes(virtualService("/service/",
+			&istiov1alpha3.HTTPRewrite{Uri: "/api/"}))).To(HaveLen(0))
+		Expect(createPrefixStripNotes(virtualService("/",
+			&istiov1alpha3.HTTPRewrite{Uri: "/"}))).To(HaveLen(0))
+	})
+
+	It("creates a note for a rewrite stripping the whole prefix", func() {
+		notes := createPrefixStripNotes(virtualService("/service/", &istiov1alpha3.HTTPRewrite{Uri: "/"}))
+		Expect(notes).To(Equal([]*apiv1.Note{
+			prefixStripNote("/service/", "/service/example", "/example"),
+		}))
+
+		notes = createPrefixStripNotes(virtualService("/service", &istiov1alpha3.HTTPRewrite{Uri: "/"}))
+		Expect(notes).To(Equal([]*apiv1.Note{
+			prefixStripNote("/service", "/service/example", "//example"),
+		}))
+	})
+})
@@ -0,0 +1,274 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualserviceredirectloop vets the http routes of VirtualService
+// resources and generates notes if the target of a redirect is matched by a
+// route of the same VirtualService which redirects again.
+package virtualserviceredirectloop
+
+import (
+	"regexp"
+	"strings"
+
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                = "VirtualServiceRedirectLoop"
+	redirectLoopNoteType    = "virtualservice-redirect-loop"
+	redirectLoopNoteSummary = "Redirect loop - ${vs_name}"
+	redirectLoopNoteMsg     = "The http route ${route} of VirtualService ${vs_name} in" +
+		" namespace ${namespace} redirects to ${target}, which is matched by the" +
+		" route ${target_route} of the same VirtualService that redirects again." +
+		" Clients follow the redirects until they give up. Consider changing the" +
+		" redirect target or the match of the routes."
+)
+
+// VirtualServiceRedirectLoop implements Vetter interface
+type VirtualServiceRedirectLoop struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// target is the request a redirect sends the client to. An empty authority
+// or path is kept from the redirected request.
+type target struct {
+	authority string
+	path      string
+}
+
+type matchResult int
+
+const (
+	noMatch matchResult = iota
+	unknownMatch
+	match
+)
+
+// stringMatches returns true if the value is matched by the string match.
+func stringMatches(s *istiov1alpha3.StringMatch, v string, ignoreCase bool) bool {
+	exact, prefix := s.GetExact(), s.GetPrefix()
+	if ignoreCase {
+		v, exact, prefix = strings.ToLower(v), strings.ToLower(exact), strings.ToLower(prefix)
+	}
+	switch s.GetMatchType().(type) {
+	case *istiov1alpha3.StringMatch_Exact:
+		return v == exact
+	case *istiov1alpha3.StringMatch_Prefix:
+		return strings.HasPrefix(v, prefix)
+	case *istiov1alpha3.StringMatch_Regex:
+		re, err := regexp.Compile("^(?:" + s.GetRegex() + ")$")
+		return err == nil && re.MatchString(v)
+	}
+	return false
+}
+
+// otherConditions returns true if the match has conditions besides the
+// authority and the uri, which can't be evaluated for the redirect target.
+func otherConditions(m *istiov1alpha3.HTTPMatchRequest) bool {
+	return m.GetScheme() != nil || m.GetMethod() != nil || len(m.GetHeaders()) != 0 ||
+		m.GetPort() != 0 || len(m.GetSourceLabels()) != 0 ||
+		len(m.GetGateways()) != 0 || len(m.GetQueryParams()) != 0
+}
+
+// changedConditions evaluates the authority and uri conditions of the match
+// which apply to a part of the request changed by the redirect. The second
+// return value is false if the match has no such condition.
+func changedConditions(m *istiov1alpha3.HTTPMatchRequest, t target) (bool, bool) {
+	changed := false
+	if m.GetAuthority() != nil && t.authority != "" {
+		if !stringMatches(m.GetAuthority(), t.authority, false) {
+			return false, true
+		}
+		changed = true
+	}
+	if m.GetUri() != nil && t.path != "" {
+		if !stringMatches(m.GetUri(), t.path, m.GetIgnoreUriCase()) {
+			return false, true
+		}
+		changed = true
+	}
+	return true, changed
+}
+
+// evalMatch evaluates the match against the redirect target. Conditions on
+// a part of the request kept by the redirect are satisfied if kept is true
+// and unknown otherwise.
+func evalMatch(m *istiov1alpha3.HTTPMatchRequest, t target, kept bool) matchResult {
+	if ok, _ := changedConditions(m, t); !ok {
+		return noMatch
+	}
+	if otherConditions(m) {
+		return unknownMatch
+	}
+	if !kept && ((m.GetAuthority() != nil && t.authority == "") ||
+		(m.GetUri() != nil && t.path == "")) {
+		return unknownMatch
+	}
+	return match
+}
+
+// evalRoute evaluates the route at index k against the target of the
+// redirect of the route at index i, which matched the redirected request.
+// Routes before i missed the redirected request, so only their matches with
+// a condition on a changed part of the request may match the target. Route i
+// matches the target again if every one of its matches does, since any of
+// them may have matched the redirected request.
+func evalRoute(r *istiov1alpha3.HTTPRoute, t target, k, i int) matchResult {
+	if len(r.GetMatch()) == 0 {
+		return match
+	}
+	res := noMatch
+	switch {
+	case k < i:
+		for _, m := range r.GetMatch() {
+			if _, changed := changedConditions(m, t); !changed {
+				continue
+			}
+			switch evalMatch(m, t, false) {
+			case match:
+				return match
+			case unknownMatch:
+				res = unknownMatch
+			}
+		}
+	case k == i:
+		res = match
+		noneMatch := true
+		for _, m := range r.GetMatch() {
+			switch evalMatch(m, t, true) {
+			case match:
+				noneMatch = false
+			case unknownMatch:
+				res, noneMatch = unknownMatch, false
+			case noMatch:
+				res = unknownMatch
+			}
+		}
+		if noneMatch {
+			res = noMatch
+		}
+	default:
+		for _, m := range r.GetMatch() {
+			switch evalMatch(m, t, false) {
+			case match:
+				return match
+			case unknownMatch:
+				res = unknownMatch
+			}
+		}
+	}
+	return res
+}
+
+// firstMatch returns the index of the first route matching the target of
+// the redirect of route i, or -1 if no route matches or the first match
+// can't be determined.
+func firstMatch(routes []*istiov1alpha3.HTTPRoute, t target, i int) int {
+	for k, r := range routes {
+		switch evalRoute(r, t, k, i) {
+		case match:
+			return k
+		case unknownMatch:
+			return -1
+		}
+	}
+	return -1
+}
+
+// sameHost returns true if the redirect authority is served by the
+// VirtualService. An empty authority keeps the host of the request.
+func sameHost(authority string, hosts []string) bool {
+	if authority == "" {
+		return true
+	}
+	for _, h := range hosts {
+		if h == "*" || strings.EqualFold(h, authority) {
+			return true
+		}
+	}
+	return false
+}
+
+// createRedirectLoopNotes generates a note for every redirecting http route
+// whose target is first matched by a route which redirects again. Only
+// single hop loops within one VirtualService are detected.
+func createRedirectLoopNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		routes := vs.Spec.GetHttp()
+		for i, r := range routes {
+			rd := r.GetRedirect()
+			if rd == nil || !sameHost(rd.GetAuthority(), vs.Spec.GetHosts()) {
+				continue
+			}
+			t := target{
+				authority: rd.GetAuthority(),
+				path:      strings.SplitN(rd.GetUri(), "?", 2)[0],
+			}
+			j := firstMatch(routes, t, i)
+			if j < 0 || routes[j].GetRedirect() == nil {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    redirectLoopNoteType,
+				Summary: redirectLoopNoteSummary,
+				Msg:     redirectLoopNoteMsg,
+				Level:   apiv1.NoteLevel_WARNING,
+				Attr: map[string]string{
+					"vs_name":      vs.Name,
+					"namespace":    vs.Namespace,
+					"route":        util.HTTPRouteName(r, i),
+					"target":       rd.GetAuthority() + rd.GetUri(),
+					"target_route": util.HTTPRouteName(routes[j], j),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (v *VirtualServiceRedirectLoop) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(v.nsLister, v.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createRedirectLoopNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (v *VirtualServiceRedirectLoop) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "VirtualServiceRedirectLoop" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *VirtualServiceRedirectLoop {
+	return &VirtualServiceRedirectLoop{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualserviceredirectloop
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func prefixRoute(name, prefix string, redirect *istiov1alpha3.HTTPRedirect) *istiov1alpha3.HTTPRoute {
+	r := &istiov1alpha3.HTTPRoute{
+		Name: name,
+		Match: []*istiov1alpha3.HTTPMatchRequest{
+			{
+				Uri: &istiov1alpha3.StringMatch{
+					MatchType: &istiov1alpha3.StringMatch_Prefix{Prefix: prefix},
+				},
+			},
+		},
+		Redirect: redirect,
+	}
+	if redirect == nil {
+		r.Route = []*istiov1alpha3.HTTPRouteDestination{
+			{Destination: &istiov1alpha3.Destination{Host: "web"}},
+		}
+	}
+	return r
+}
+
+func virtualService(routes ...*istiov1alpha3.HTTPRoute) []*v1alpha3.VirtualService {
+	return virtualServiceWithHosts([]string{"web.example.com"}, routes...)
+}
+
+func virtualServiceWithHosts(hosts []string,
+	routes ...*istiov1alpha3.HTTPRoute) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: hosts,
+					Http:  routes,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService redirect loops", func() {
+	It("creates zero notes for a terminal redirect", func() {
+		vsList := virtualService(
+			prefixRoute("old", "/old", &istiov1alpha3.HTTPRedirect{Uri: "/new"}),
+			prefixRoute("new", "/new", nil),
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a redirect to an unmatched path", func() {
+		vsList := virtualService(
+			prefixRoute("old", "/old", &istiov1alpha3.HTTPRedirect{Uri: "/elsewhere"}),
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a redirect to the canonical host", func() {
+		vsList := virtualServiceWithHosts([]string{"example.com", "www.example.com"},
+			&istiov1alpha3.HTTPRoute{
+				Name: "canonical",
+				Match: []*istiov1alpha3.HTTPMatchRequest{
+					{
+						Authority: &istiov1alpha3.StringMatch{
+							MatchType: &istiov1alpha3.StringMatch_Exact{Exact: "example.com"},
+						},
+					},
+				},
+				Redirect: &istiov1alpha3.HTTPRedirect{Authority: "www.example.com"},
+			},
+			prefixRoute("web", "/", nil),
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes if the target may be matched by a route with headers", func() {
+		vsList := virtualService(
+			prefixRoute("old", "/old", &istiov1alpha3.HTTPRedirect{Uri: "/new"}),
+			&istiov1alpha3.HTTPRoute{
+				Name: "beta",
+				Match: []*istiov1alpha3.HTTPMatchRequest{
+					{
+						Headers: map[string]*istiov1alpha3.StringMatch{
+							"x-beta": {MatchType: &istiov1alpha3.StringMatch_Exact{Exact: "true"}},
+						},
+					},
+				},
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					{Destination: &istiov1alpha3.Destination{Host: "web-beta"}},
+				},
+			},
+			prefixRoute("new", "/new", &istiov1alpha3.HTTPRedirect{Uri: "/latest"}),
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for an authority redirect matched by the redirecting route", func() {
+		expNote := &apiv1.Note{
+			Type:    redirectLoopNoteType,
+			Summary: redirectLoopNoteSummary,
+			Msg:     redirectLoopNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":      "web",
+				"namespace":    "default",
+				"route":        "canonical",
+				"target":       "www.example.com",
+				"target_route": "canonical",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		vsList := virtualServiceWithHosts([]string{"example.com", "www.example.com"},
+			&istiov1alpha3.HTTPRoute{
+				Name:     "canonical",
+				Redirect: &istiov1alpha3.HTTPRedirect{Authority: "www.example.com"},
+			},
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+
+	It("creates a note for a redirect matched by the redirecting route", func() {
+		expNote := &apiv1.Note{
+			Type:    redirectLoopNoteType,
+			Summary: redirectLoopNoteSummary,
+			Msg:     redirectLoopNoteMsg,
+			Level:   apiv1.NoteLevel_WARNING,
+			Attr: map[string]string{
+				"vs_name":      "web",
+				"namespace":    "default",
+				"route":        "old",
+				"target":       "/old/index.html",
+				"target_route": "old",
+			},
+		}
+		expNote.Id = util.ComputeIDStable(expNote)
+		vsList := virtualService(
+			prefixRoute("old", "/old", &istiov1alpha3.HTTPRedirect{Uri: "/old/index.html"}),
+		)
+		Expect(createRedirectLoopNotes(vsList)).To(Equal([]*apiv1.Note{expNote}))
+	})
+})
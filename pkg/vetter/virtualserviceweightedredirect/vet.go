@@ -0,0 +1,116 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package virtualserviceweightedredirect vets the http routes of
+// VirtualService resources and generates notes if weighted route entries are
+// mixed with a redirect or lack a destination.
+package virtualserviceweightedredirect
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	netv1alpha3 "github.com/aspenmesh/istio-client-go/pkg/client/listers/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	"k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	vetterID                 = "VirtualServiceWeightedRedirect"
+	weightedRedirectNoteType = "virtualservice-weighted-redirect"
+	weightedRedirectSummary  = "Weighted route mixed with redirect - ${vs_name}"
+	weightedRedirectNoteMsg  = "The http route ${route} of VirtualService ${vs_name}" +
+		" in namespace ${namespace} has weighted route entries which are mixed" +
+		" with a redirect or don't all carry a destination. Weights only apply" +
+		" to destinations and the route is rejected or behaves unexpectedly." +
+		" Consider moving the redirect into a separate http route and setting a" +
+		" destination on every route entry."
+)
+
+// VirtualServiceWeightedRedirect implements Vetter interface
+type VirtualServiceWeightedRedirect struct {
+	nsLister v1.NamespaceLister
+	vsLister netv1alpha3.VirtualServiceLister
+}
+
+// malformedWeights returns true if the http route has route entries and also
+// redirects, or if any of its route entries has no destination.
+func malformedWeights(r *istiov1alpha3.HTTPRoute) bool {
+	if len(r.GetRoute()) == 0 {
+		return false
+	}
+	if r.GetRedirect() != nil {
+		return true
+	}
+	for _, dw := range r.GetRoute() {
+		if dw.GetDestination().GetHost() == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// createWeightedRedirectNotes generates a note for every http route of the
+// VirtualServices whose weighted route entries are malformed.
+func createWeightedRedirectNotes(vsList []*v1alpha3.VirtualService) []*apiv1.Note {
+	notes := []*apiv1.Note{}
+	for _, vs := range vsList {
+		for i, r := range vs.Spec.GetHttp() {
+			if !malformedWeights(r) {
+				continue
+			}
+			notes = append(notes, &apiv1.Note{
+				Type:    weightedRedirectNoteType,
+				Summary: weightedRedirectSummary,
+				Msg:     weightedRedirectNoteMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr: map[string]string{
+					"vs_name":   vs.Name,
+					"namespace": vs.Namespace,
+					"route":     util.HTTPRouteName(r, i),
+				},
+			})
+		}
+	}
+
+	for i := range notes {
+		notes[i].Id = util.ComputeIDStable(notes[i])
+	}
+	return notes
+}
+
+// Vet returns the list of generated notes
+func (v *VirtualServiceWeightedRedirect) Vet() ([]*apiv1.Note, error) {
+	vsList, err := util.ListVirtualServicesInMesh(v.nsLister, v.vsLister)
+	if err != nil {
+		return nil, err
+	}
+	return createWeightedRedirectNotes(vsList), nil
+}
+
+// Info returns information about the vetter
+func (v *VirtualServiceWeightedRedirect) Info() *apiv1.Info {
+	return &apiv1.Info{Id: vetterID, Version: "0.1.0"}
+}
+
+// NewVetter returns "VirtualServiceWeightedRedirect" which implements Vetter Interface
+func NewVetter(factory vetter.ResourceListGetter) *VirtualServiceWeightedRedirect {
+	return &VirtualServiceWeightedRedirect{
+		nsLister: factory.K8s().Core().V1().Namespaces().Lister(),
+		vsLister: factory.Istio().Networking().V1alpha3().VirtualServices().Lister(),
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2020 Aspen Mesh Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualserviceweightedredirect
+
+import (
+	v1alpha3 "github.com/aspenmesh/istio-client-go/pkg/apis/networking/v1alpha3"
+	apiv1 "github.com/aspenmesh/istio-vet/api/v1"
+	"github.com/aspenmesh/istio-vet/pkg/vetter/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	istiov1alpha3 "istio.io/api/networking/v1alpha3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func weighted(host string, weight int32) *istiov1alpha3.HTTPRouteDestination {
+	dw := &istiov1alpha3.HTTPRouteDestination{Weight: weight}
+	if host != "" {
+		dw.Destination = &istiov1alpha3.Destination{Host: host}
+	}
+	return dw
+}
+
+func virtualService(routes ...*istiov1alpha3.HTTPRoute) []*v1alpha3.VirtualService {
+	return []*v1alpha3.VirtualService{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "reviews", Namespace: "default"},
+			Spec: v1alpha3.VirtualServiceSpec{
+				VirtualService: istiov1alpha3.VirtualService{
+					Hosts: []string{"reviews"},
+					Http:  routes,
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("VirtualService weighted routes mixed with redirects", func() {
+	It("creates zero notes on empty lists", func() {
+		Expect(createWeightedRedirectNotes(nil)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a weighted destination split", func() {
+		vsList := virtualService(&istiov1alpha3.HTTPRoute{
+			Route: []*istiov1alpha3.HTTPRouteDestination{
+				weighted("reviews-v1", 80),
+				weighted("reviews-v2", 20),
+			},
+		})
+		Expect(createWeightedRedirectNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates zero notes for a redirect route", func() {
+		vsList := virtualService(&istiov1alpha3.HTTPRoute{
+			Redirect: &istiov1alpha3.HTTPRedirect{Uri: "/v2/reviews"},
+		})
+		Expect(createWeightedRedirectNotes(vsList)).To(HaveLen(0))
+	})
+
+	It("creates a note for weighted route entries mixed with a redirect", func() {
+		vsList := virtualService(
+			&istiov1alpha3.HTTPRoute{
+				Name:     "legacy",
+				Redirect: &istiov1alpha3.HTTPRedirect{Uri: "/v2/reviews"},
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					weighted("reviews-v1", 50),
+					weighted("", 50),
+				},
+			},
+			&istiov1alpha3.HTTPRoute{
+				Route: []*istiov1alpha3.HTTPRouteDestination{
+					weighted("reviews-v1", 50),
+					weighted("", 50),
+				},
+			},
+		)
+		expNotes := []*apiv1.Note{}
+		for _, route := range []string{"legacy", "1"} {
+			n := &apiv1.Note{
+				Type:    weightedRedirectNoteType,
+				Summary: weightedRedirectSummary,
+				Msg:     weightedRedirectNoteMsg,
+				Level:   apiv1.NoteLevel_ERROR,
+				Attr: map[string]string{
+					"vs_name":   "reviews",
+					"namespace": "default",
+					"route":     route,
+				},
+			}
+			n.Id = util.ComputeIDStable(n)
+			expNotes = append(expNotes, n)
+		}
+		Expect(createWeightedRedirectNotes(vsList)).To(Equal(expNotes))
+	})
+})